@@ -2,6 +2,7 @@ package main
 
 import (
 	"fmt"
+	"os"
 	"strings"
 )
 
@@ -13,25 +14,26 @@ import (
 
 /*
 	In some languages it’s idiomatic to use generic data structures and algorithms.
-	Go does not support generics; in Go it’s common to provide collection functions if and when they are specifically needed for your program and data types.
+	Since Go 1.18 Go supports generics too, so a collection function written once with type parameters works for slices of any element type,
+	instead of one hand-written copy per type.
 	어떤 언어들에서는 제네릭 자료구조와 알고리즘을 사용하는 것이 자연스럽다.
-	Go는 제네릭을 지원하지 않는다; Go에서는 프로그램과 데이터 유형에 특별히 필요한 경우 수집 기능을 제공하는 것이 일반적이다.
+	Go 1.18 부터 Go 도 제네릭을 지원하므로, 타입 파라미터로 한 번 작성한 컬랙션 함수는 타입마다 손으로 작성한 복사본 하나씩 대신에 어떤 원소 타입의 슬라이스에도 동작한다.
 */
 
 /*
-	Here are some example collection functions for slices of strings.
+	Here are some example collection functions; below they are used with slices of strings, and helpers.go has more of them.
 	You can use these examples to build your own functions.
 	Note that in some cases it may be clearest to just inline the collection-manipulating code directly, instead of creating and calling a helper function.
-	여기 문자열의 슬라이스를 위한 몇 가지 컬랙션 함수들에 대한 예제가 있다.
+	여기 몇 가지 컬랙션 함수들에 대한 예제가 있다; 아래에서는 문자열의 슬라이스와 함께 사용되며, helpers.go 에 더 있다.
 	너는 이 예제들을 사용해서 너 자신만의 함수들을 만들 수 있다.
 	참고로 이 몇 가지 경우들은 아마 헬퍼 함수를 생성하고 호출하는 것 대신에 컬랙션을 조작하는 코드를 직접 inline 하는 것이 더 명확할 수 있다.
 */
 
 /*
-	Index returns the first index of the target string t, or -1 if no match is found.
-	Index는 타겟 문자열 t의 첫번째 인덱스를 반환하거나 매치되지 않는다면 -1을 반환한다.
+	Index returns the first index of the target t, or -1 if no match is found.
+	Index는 타겟 t의 첫번째 인덱스를 반환하거나 매치되지 않는다면 -1을 반환한다.
 */
-func Index(vs []string, t string) int {
+func Index[T comparable](vs []T, t T) int {
 	for i, v := range vs {
 		if v == t {
 			return i
@@ -41,18 +43,18 @@ func Index(vs []string, t string) int {
 }
 
 /*
-	Include returns true if the target string t is in the slice.
-	Include는 슬라이스 내에 타겟 문자열 t가 있다면 true를 반환한다.
+	Include returns true if the target t is in the slice.
+	Include는 슬라이스 내에 타겟 t가 있다면 true를 반환한다.
 */
-func Include(vs []string, t string) bool {
+func Include[T comparable](vs []T, t T) bool {
 	return Index(vs, t) >= 0
 }
 
 /*
-	Any returns true if one of the strings in the slice satisfies the predicate f.
-	Any 는 슬라이스 내에 문자열 중 하나가 술부 f를 만족하면 true 를 반환한다.
+	Any returns true if one of the elements in the slice satisfies the predicate f.
+	Any 는 슬라이스 내에 원소 중 하나가 술부 f를 만족하면 true 를 반환한다.
 */
-func Any(vs []string, f func(string) bool) bool {
+func Any[T any](vs []T, f func(T) bool) bool {
 	for _, v := range vs {
 		if f(v) {
 			return true
@@ -62,10 +64,10 @@ func Any(vs []string, f func(string) bool) bool {
 }
 
 /*
-	All returns true if all of the strings in the slice satisfy the predicate f.
-	All 은 슬라이스 내에 모든 문자열들이 술부 f를 만족하면 true를 반환한다.
+	All returns true if all of the elements in the slice satisfy the predicate f.
+	All 은 슬라이스 내에 모든 원소들이 술부 f를 만족하면 true를 반환한다.
 */
-func All(vs []string, f func(string) bool) bool {
+func All[T any](vs []T, f func(T) bool) bool {
 	for _, v := range vs {
 		if !f(v) {
 			return false
@@ -75,11 +77,11 @@ func All(vs []string, f func(string) bool) bool {
 }
 
 /*
-	Filter returns a new slice containing all strings in the slice that satisfy the predicate f.
-	Filter 슬라이스 내에 술부 f를 만족하는 모든 문자열들을 포함하는 새로운 슬라이스를 반환한다.
+	Filter returns a new slice containing all elements in the slice that satisfy the predicate f.
+	Filter 슬라이스 내에 술부 f를 만족하는 모든 원소들을 포함하는 새로운 슬라이스를 반환한다.
 */
-func Filter(vs []string, f func(string) bool) []string {
-	vsf := make([]string, 0)
+func Filter[T any](vs []T, f func(T) bool) []T {
+	vsf := make([]T, 0)
 	for _, v := range vs {
 		if f(v) {
 			vsf = append(vsf, v)
@@ -89,11 +91,13 @@ func Filter(vs []string, f func(string) bool) []string {
 }
 
 /*
-	Map returns a new slice containing the results of applying the function f to each string in the original slice.
-	Map은 원래 슬라이스의 각 문자열에 함수 f를 적용한 결과를 포함하는 새로운 슬라이스를 반환한다.
+	Map returns a new slice containing the results of applying the function f to each element in the original slice.
+	The result's element type U need not be the same as T.
+	Map은 원래 슬라이스의 각 원소에 함수 f를 적용한 결과를 포함하는 새로운 슬라이스를 반환한다.
+	결과의 원소 타입 U 는 T 와 같을 필요가 없다.
 */
-func Map(vs []string, f func(string) string) []string {
-	vsm := make([]string, len(vs))
+func Map[T, U any](vs []T, f func(T) U) []U {
+	vsm := make([]U, len(vs))
 	for i, v := range vs {
 		vsm[i] = f(v)
 	}
@@ -124,4 +128,44 @@ func main() {
 		The above examples all used anonymous functions, but you can also use name functions of the correct type.
 		위의 예제들은 모두 익명함수들로 쓰여졌지만 정확한 타입의 명명된 함수들을 쓸 수도 있다.
 	*/
+
+	/*
+		The same functions work for other element types, and Map can change the element type.
+		같은 함수들이 다른 원소 타입에도 동작하며, Map 은 원소 타입을 바꿀 수 있다.
+	*/
+	var nums = []int{3, 1, 4, 1, 5, 9, 2, 6}
+	fmt.Println(Index(nums, 5))
+	fmt.Println(Map(strs, func(v string) int { return len(v) }))
+	fmt.Println(Reduce(nums, 0, func(sum, v int) int { return sum + v }))
+	fmt.Println(GroupBy(strs, func(v string) string { return v[:1] }))
+	fmt.Println(Chunk(nums, 3))
+	fmt.Println(Window(nums[:4], 2))
+	fmt.Println(Zip(strs, nums))
+	fmt.Println(Uniq(nums))
+	fmt.Println(SortBy(strs, func(v string) string { return v }))
+	fmt.Println(MaxBy(strs, func(v string) int { return len(v) }))
+
+	/*
+		Filter and Map above build a new slice at every step; lazy.go has versions that build nothing until the result is used.
+		위의 Filter 와 Map 은 단계마다 새로운 슬라이스를 만든다; lazy.go 에는 결과가 사용되기 전까지 아무것도 만들지 않는 버전들이 있다.
+	*/
+	lazyExample(os.Stdout)
+
+	/*
+		When f is slow, the elements can be worked on in parallel without losing their order.
+		f 가 느릴 때는, 원소들의 순서를 잃지 않고 병렬로 처리할 수 있다.
+	*/
+	parallelExample(os.Stdout)
+
+	/*
+		The same functions, strung together and addressed by field name, make a small query language for slices of structs.
+		같은 함수들을 엮고 필드 이름으로 지정하면, struct 슬라이스를 위한 작은 query 언어가 된다.
+	*/
+	queryExample(os.Stdout)
+
+	/*
+		When only membership matters, a set answers what Include answers without scanning the slice.
+		포함 여부만 중요할 때, set 은 슬라이스를 훑지 않고 Include 가 답하는 것에 답한다.
+	*/
+	setExample(os.Stdout)
 }
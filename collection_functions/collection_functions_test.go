@@ -0,0 +1,116 @@
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type point struct {
+	X, Y int
+}
+
+/*
+	TestCollections runs a table of cases for every collection function, over ints, strings and a struct type.
+	TestCollections 는 모든 컬랙션 함수에 대해 int, 문자열, 그리고 struct 타입 위의 사례 표를 실행한다.
+*/
+func TestCollections(t *testing.T) {
+	ints := []int{3, 1, 4, 1, 5, 9, 2, 6}
+	strs := []string{"peach", "apple", "pear", "plum"}
+	points := []point{{1, 2}, {3, 0}, {-1, 5}, {3, 0}}
+	even := func(v int) bool { return v%2 == 0 }
+	hasP := func(v string) bool { return strings.HasPrefix(v, "p") }
+
+	for _, tc := range []struct {
+		name      string
+		got, want any
+	}{
+		{"Index ints", Index(ints, 1), 1},
+		{"Index strings missing", Index(strs, "grape"), -1},
+		{"Index points", Index(points, point{3, 0}), 1},
+		{"Index empty", Index([]int(nil), 1), -1},
+
+		{"Include ints", Include(ints, 9), true},
+		{"Include strings", Include(strs, "grape"), false},
+		{"Include points", Include(points, point{-1, 5}), true},
+
+		{"Any ints", Any(ints, even), true},
+		{"Any strings", Any(strs, func(v string) bool { return v == "" }), false},
+		{"Any empty", Any([]point{}, func(point) bool { return true }), false},
+
+		{"All ints", All(ints, even), false},
+		{"All strings", All(strs, hasP), false},
+		{"All empty", All([]int{}, even), true},
+		{"All points", All(points, func(p point) bool { return p.X != 0 }), true},
+
+		{"Filter ints", Filter(ints, even), []int{4, 2, 6}},
+		{"Filter strings", Filter(strs, hasP), []string{"peach", "pear", "plum"}},
+		{"Filter none", Filter(points, func(p point) bool { return p.X > 10 }), []point{}},
+
+		{"Map ints", Map(ints[:3], func(v int) int { return v * v }), []int{9, 1, 16}},
+		{"Map strings", Map(strs, strings.ToUpper), []string{"PEACH", "APPLE", "PEAR", "PLUM"}},
+		{"Map change type", Map(strs, func(v string) int { return len(v) }), []int{5, 5, 4, 4}},
+		{"Map points", Map(points[:2], func(p point) int { return p.X + p.Y }), []int{3, 3}},
+
+		{"Reduce sum", Reduce(ints, 0, func(a, v int) int { return a + v }), 31},
+		{"Reduce join", Reduce(strs, "", func(a, v string) string { return a + v[:1] }), "papp"},
+		{"Reduce points", Reduce(points, point{}, func(a, p point) point { return point{a.X + p.X, a.Y + p.Y} }), point{6, 7}},
+		{"Reduce empty", Reduce([]int{}, 42, func(a, v int) int { return a + v }), 42},
+
+		{"FlatMap ints", FlatMap([]int{1, 2, 3}, func(v int) []int { return []int{v, v * 10} }), []int{1, 10, 2, 20, 3, 30}},
+		{"FlatMap strings", FlatMap([]string{"a b", "c"}, strings.Fields), []string{"a", "b", "c"}},
+		{"FlatMap empty results", FlatMap(points, func(point) []int { return nil }), []int{}},
+
+		{"GroupBy ints", GroupBy(ints, even), map[bool][]int{true: {4, 2, 6}, false: {3, 1, 1, 5, 9}}},
+		{"GroupBy strings", GroupBy(strs, func(v string) int { return len(v) }), map[int][]string{5: {"peach", "apple"}, 4: {"pear", "plum"}}},
+		{"GroupBy points", GroupBy(points, func(p point) int { return p.X }), map[int][]point{1: {{1, 2}}, 3: {{3, 0}, {3, 0}}, -1: {{-1, 5}}}},
+		{"GroupBy empty", GroupBy([]int{}, even), map[bool][]int{}},
+
+		{"Partition ints", pair(Partition(ints, even)), Pair[[]int, []int]{[]int{4, 2, 6}, []int{3, 1, 1, 5, 9}}},
+		{"Partition strings", pair(Partition(strs, hasP)), Pair[[]string, []string]{[]string{"peach", "pear", "plum"}, []string{"apple"}}},
+		{"Partition empty", pair(Partition([]point{}, func(point) bool { return true })), Pair[[]point, []point]{[]point{}, []point{}}},
+
+		{"Chunk ints", Chunk(ints, 3), [][]int{{3, 1, 4}, {1, 5, 9}, {2, 6}}},
+		{"Chunk strings exact", Chunk(strs, 2), [][]string{{"peach", "apple"}, {"pear", "plum"}}},
+		{"Chunk bigger than slice", Chunk(points[:1], 5), [][]point{{{1, 2}}}},
+		{"Chunk empty", Chunk([]int{}, 2), [][]int{}},
+
+		{"Window ints", Window(ints[:4], 2), [][]int{{3, 1}, {1, 4}, {4, 1}}},
+		{"Window strings", Window(strs, 4), [][]string{{"peach", "apple", "pear", "plum"}}},
+		{"Window too short", Window(points[:1], 2), [][]point{}},
+
+		{"Zip ints strings", Zip(ints, strs), []Pair[int, string]{{3, "peach"}, {1, "apple"}, {4, "pear"}, {1, "plum"}}},
+		{"Zip points", Zip(points[:1], []bool{true, false}), []Pair[point, bool]{{point{1, 2}, true}}},
+		{"Zip empty", Zip([]int{}, strs), []Pair[int, string]{}},
+
+		{"Uniq ints", Uniq(ints), []int{3, 1, 4, 5, 9, 2, 6}},
+		{"Uniq strings", Uniq([]string{"a", "b", "a", "a"}), []string{"a", "b"}},
+		{"Uniq points", Uniq(points), []point{{1, 2}, {3, 0}, {-1, 5}}},
+
+		{"MinBy ints", pair(MinBy(ints, func(v int) int { return v })), Pair[int, bool]{1, true}},
+		{"MinBy strings by length", pair(MinBy(strs, func(v string) int { return len(v) })), Pair[string, bool]{"pear", true}},
+		{"MinBy points", pair(MinBy(points, func(p point) int { return p.Y })), Pair[point, bool]{point{3, 0}, true}},
+		{"MinBy empty", pair(MinBy([]int{}, func(v int) int { return v })), Pair[int, bool]{0, false}},
+
+		{"MaxBy ints", pair(MaxBy(ints, func(v int) int { return v })), Pair[int, bool]{9, true}},
+		{"MaxBy strings", pair(MaxBy(strs, func(v string) string { return v })), Pair[string, bool]{"plum", true}},
+		{"MaxBy points by length", pair(MaxBy(points, func(p point) int { return p.X*p.X + p.Y*p.Y })), Pair[point, bool]{point{-1, 5}, true}},
+
+		{"SortBy ints descending", SortBy(ints, func(v int) int { return -v }), []int{9, 6, 5, 4, 3, 2, 1, 1}},
+		{"SortBy strings stable", SortBy(strs, func(v string) int { return len(v) }), []string{"pear", "plum", "peach", "apple"}},
+		{"SortBy points", SortBy(points, func(p point) int { return p.X }), []point{{-1, 5}, {1, 2}, {3, 0}, {3, 0}}},
+		{"SortBy leaves input alone", ints, []int{3, 1, 4, 1, 5, 9, 2, 6}},
+	} {
+		if !reflect.DeepEqual(tc.got, tc.want) {
+			t.Errorf("%s: got %v, want %v", tc.name, tc.got, tc.want)
+		}
+	}
+}
+
+/*
+	pair lets a function with two results sit in one table cell.
+	pair 는 두 결과를 가진 함수가 표의 한 칸에 들어갈 수 있게 한다.
+*/
+func pair[A, B any](a A, b B) Pair[A, B] {
+	return Pair[A, B]{a, b}
+}
@@ -0,0 +1,174 @@
+package main
+
+import (
+	"cmp"
+	"sort"
+)
+
+/*
+	More collection functions, written once for any element type like the ones in collection_functions.go.
+	None of them change the slice they are given; the ones that return slices return new ones.
+	더 많은 컬랙션 함수들로, collection_functions.go 의 것들처럼 어떤 원소 타입에 대해서든 한 번만 작성되었다.
+	그 중 어느 것도 받은 슬라이스를 바꾸지 않는다; 슬라이스를 반환하는 것들은 새로운 슬라이스를 반환한다.
+*/
+
+/*
+	Reduce folds the slice into one value, starting from init and combining it with each element in turn.
+	Reduce 는 init 에서 시작해서 그것을 각 원소와 차례로 결합하여 슬라이스를 하나의 값으로 접는다.
+*/
+func Reduce[T, A any](vs []T, init A, f func(A, T) A) A {
+	acc := init
+	for _, v := range vs {
+		acc = f(acc, v)
+	}
+	return acc
+}
+
+/*
+	FlatMap is Map where f returns a slice for each element, and the results are concatenated.
+	FlatMap 은 f 가 각 원소마다 슬라이스를 반환하고 그 결과들이 이어붙여지는 Map 이다.
+*/
+func FlatMap[T, U any](vs []T, f func(T) []U) []U {
+	vsm := make([]U, 0, len(vs))
+	for _, v := range vs {
+		vsm = append(vsm, f(v)...)
+	}
+	return vsm
+}
+
+/*
+	GroupBy collects the elements by the key f gives them, keeping their order within each group.
+	GroupBy 는 원소들을 f 가 주는 key 별로 모으며, 각 그룹 안에서 그들의 순서를 유지한다.
+*/
+func GroupBy[T any, K comparable](vs []T, f func(T) K) map[K][]T {
+	groups := make(map[K][]T)
+	for _, v := range vs {
+		k := f(v)
+		groups[k] = append(groups[k], v)
+	}
+	return groups
+}
+
+/*
+	Partition splits the slice into the elements that satisfy the predicate f and those that do not.
+	Partition 은 슬라이스를 술부 f 를 만족하는 원소들과 그렇지 않은 원소들로 나눈다.
+*/
+func Partition[T any](vs []T, f func(T) bool) (yes, no []T) {
+	yes, no = make([]T, 0), make([]T, 0)
+	for _, v := range vs {
+		if f(v) {
+			yes = append(yes, v)
+		} else {
+			no = append(no, v)
+		}
+	}
+	return yes, no
+}
+
+/*
+	Chunk splits the slice into consecutive chunks of size elements; the last chunk may be shorter. size must be positive.
+	Chunk 는 슬라이스를 size 개 원소의 연속된 덩어리들로 나눈다; 마지막 덩어리는 더 짧을 수 있다. size 는 양수여야 한다.
+*/
+func Chunk[T any](vs []T, size int) [][]T {
+	if size <= 0 {
+		panic("Chunk: size must be positive")
+	}
+	chunks := make([][]T, 0, (len(vs)+size-1)/size)
+	for i := 0; i < len(vs); i += size {
+		end := min(i+size, len(vs))
+		chunks = append(chunks, append([]T(nil), vs[i:end]...))
+	}
+	return chunks
+}
+
+/*
+	Window returns every run of size consecutive elements, each starting one element after the previous one.
+	A slice shorter than size has no windows. size must be positive.
+	Window 는 size 개의 연속된 원소들의 모든 구간을 반환하며, 각 구간은 이전 구간보다 한 원소 뒤에서 시작한다.
+	size 보다 짧은 슬라이스는 구간이 없다. size 는 양수여야 한다.
+*/
+func Window[T any](vs []T, size int) [][]T {
+	if size <= 0 {
+		panic("Window: size must be positive")
+	}
+	windows := make([][]T, 0, max(len(vs)-size+1, 0))
+	for i := 0; i+size <= len(vs); i++ {
+		windows = append(windows, append([]T(nil), vs[i:i+size]...))
+	}
+	return windows
+}
+
+type Pair[A, B any] struct {
+	First  A
+	Second B
+}
+
+/*
+	Zip pairs up the elements of two slices by index, stopping at the end of the shorter one.
+	Zip 은 두 슬라이스의 원소들을 인덱스별로 짝지으며, 더 짧은 것의 끝에서 멈춘다.
+*/
+func Zip[A, B any](as []A, bs []B) []Pair[A, B] {
+	pairs := make([]Pair[A, B], min(len(as), len(bs)))
+	for i := range pairs {
+		pairs[i] = Pair[A, B]{as[i], bs[i]}
+	}
+	return pairs
+}
+
+/*
+	Uniq returns the slice without duplicates, keeping the first occurrence of each element.
+	Uniq 는 중복이 없는 슬라이스를 반환하며, 각 원소의 첫 번째 등장을 유지한다.
+*/
+func Uniq[T comparable](vs []T) []T {
+	seen := make(map[T]bool, len(vs))
+	vsu := make([]T, 0, len(vs))
+	for _, v := range vs {
+		if !seen[v] {
+			seen[v] = true
+			vsu = append(vsu, v)
+		}
+	}
+	return vsu
+}
+
+/*
+	MinBy returns the element with the smallest key, the first one if several tie, and false for an empty slice.
+	MinBy 는 가장 작은 key 를 가진 원소를 반환하며, 여럿이 같으면 첫 번째 것을, 빈 슬라이스에 대해서는 false 를 반환한다.
+*/
+func MinBy[T any, K cmp.Ordered](vs []T, key func(T) K) (T, bool) {
+	return bestBy(vs, key, func(a, b K) bool { return cmp.Less(a, b) })
+}
+
+/*
+	MaxBy is MinBy for the largest key.
+	MaxBy 는 가장 큰 key 에 대한 MinBy 이다.
+*/
+func MaxBy[T any, K cmp.Ordered](vs []T, key func(T) K) (T, bool) {
+	return bestBy(vs, key, func(a, b K) bool { return cmp.Less(b, a) })
+}
+
+func bestBy[T any, K cmp.Ordered](vs []T, key func(T) K, better func(a, b K) bool) (T, bool) {
+	var best T
+	if len(vs) == 0 {
+		return best, false
+	}
+	best, bestKey := vs[0], key(vs[0])
+	for _, v := range vs[1:] {
+		if k := key(v); better(k, bestKey) {
+			best, bestKey = v, k
+		}
+	}
+	return best, true
+}
+
+/*
+	SortBy returns a sorted copy of the slice, ordered by key. Elements with equal keys keep their order.
+	Each key is computed once, so an expensive key function is not called on every comparison.
+	SortBy 는 key 로 정렬된 슬라이스의 복사본을 반환한다. 같은 key 를 가진 원소들은 그들의 순서를 유지한다.
+	각 key 는 한 번만 계산되므로, 비용이 큰 key 함수가 비교마다 호출되지 않는다.
+*/
+func SortBy[T any, K cmp.Ordered](vs []T, key func(T) K) []T {
+	keyed := Map(vs, func(v T) Pair[K, T] { return Pair[K, T]{key(v), v} })
+	sort.SliceStable(keyed, func(i, j int) bool { return cmp.Less(keyed[i].First, keyed[j].First) })
+	return Map(keyed, func(p Pair[K, T]) T { return p.Second })
+}
@@ -0,0 +1,221 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"iter"
+	"slices"
+	"strings"
+)
+
+/*
+	Filter and Map build a whole new slice every time, so a chain of them allocates one slice per step even if only the first few results are used.
+	The functions below are lazy: they take and return an iter.Seq, nothing runs until someone ranges over the result,
+	and each element flows through the whole chain before the next one is read.
+	When the consumer stops early (break, or Take having enough), yield returns false and every step stops pulling from its source.
+	Filter 와 Map 은 매번 완전히 새로운 슬라이스를 만들기 때문에, 그것들의 연쇄는 처음 몇 개의 결과만 사용되더라도 단계마다 슬라이스 하나를 할당한다.
+	아래의 함수들은 게으르다: iter.Seq 를 받고 반환하며, 누군가 결과 위를 range 하기 전까지 아무것도 실행되지 않고,
+	각 원소는 다음 원소가 읽히기 전에 연쇄 전체를 흘러간다.
+	소비자가 일찍 멈추면(break 하거나 Take 가 충분히 가지면) yield 는 false 를 반환하고 모든 단계는 자신의 소스에서 끌어오는 것을 멈춘다.
+*/
+
+/*
+	FromSlice, FromMap, FromChan and Lines turn the usual sources into sequences.
+	FromChan ranges over the channel the way range_over_channels does; if the consumer stops early the rest of the channel is left unread.
+	Lines stops at the end of the input or the first read error, which the caller gets from the scanner's Err.
+	FromSlice, FromMap, FromChan 그리고 Lines 는 흔한 소스들을 시퀀스로 바꾼다.
+	FromChan 은 range_over_channels 처럼 채널 위를 range 한다; 소비자가 일찍 멈추면 채널의 나머지는 읽히지 않은 채로 남는다.
+	Lines 는 입력의 끝이나 첫 읽기 에러에서 멈추며, 호출자는 그 에러를 scanner 의 Err 에서 얻는다.
+*/
+func FromSlice[T any](vs []T) iter.Seq[T] {
+	return func(yield func(T) bool) {
+		for _, v := range vs {
+			if !yield(v) {
+				return
+			}
+		}
+	}
+}
+
+func FromMap[K comparable, V any](m map[K]V) iter.Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		for k, v := range m {
+			if !yield(k, v) {
+				return
+			}
+		}
+	}
+}
+
+func FromChan[T any](ch <-chan T) iter.Seq[T] {
+	return func(yield func(T) bool) {
+		for v := range ch {
+			if !yield(v) {
+				return
+			}
+		}
+	}
+}
+
+func Lines(sc *bufio.Scanner) iter.Seq[string] {
+	return func(yield func(string) bool) {
+		for sc.Scan() {
+			if !yield(sc.Text()) {
+				return
+			}
+		}
+	}
+}
+
+/*
+	FilterSeq and MapSeq are the lazy Filter and Map.
+	FilterSeq 와 MapSeq 는 게으른 Filter 와 Map 이다.
+*/
+func FilterSeq[T any](seq iter.Seq[T], f func(T) bool) iter.Seq[T] {
+	return func(yield func(T) bool) {
+		for v := range seq {
+			if f(v) && !yield(v) {
+				return
+			}
+		}
+	}
+}
+
+func MapSeq[T, U any](seq iter.Seq[T], f func(T) U) iter.Seq[U] {
+	return func(yield func(U) bool) {
+		for v := range seq {
+			if !yield(f(v)) {
+				return
+			}
+		}
+	}
+}
+
+/*
+	Take yields the first n elements and then stops its source, so it also makes an endless sequence finite.
+	Take 는 처음 n 개의 원소를 내보낸 뒤 자신의 소스를 멈추므로, 끝없는 시퀀스를 유한하게 만들기도 한다.
+*/
+func Take[T any](seq iter.Seq[T], n int) iter.Seq[T] {
+	return func(yield func(T) bool) {
+		if n <= 0 {
+			return
+		}
+		i := 0
+		for v := range seq {
+			if !yield(v) {
+				return
+			}
+			i++
+			if i == n {
+				return
+			}
+		}
+	}
+}
+
+func Skip[T any](seq iter.Seq[T], n int) iter.Seq[T] {
+	return func(yield func(T) bool) {
+		i := 0
+		for v := range seq {
+			if i < n {
+				i++
+				continue
+			}
+			if !yield(v) {
+				return
+			}
+		}
+	}
+}
+
+/*
+	TakeWhile yields elements until the first one that does not satisfy f, and reads no further.
+	TakeWhile 은 f 를 만족하지 않는 첫 원소까지 원소들을 내보내며, 그 이상은 읽지 않는다.
+*/
+func TakeWhile[T any](seq iter.Seq[T], f func(T) bool) iter.Seq[T] {
+	return func(yield func(T) bool) {
+		for v := range seq {
+			if !f(v) || !yield(v) {
+				return
+			}
+		}
+	}
+}
+
+/*
+	Chain yields every element of each sequence in turn.
+	Chain 은 각 시퀀스의 모든 원소를 차례로 내보낸다.
+*/
+func Chain[T any](seqs ...iter.Seq[T]) iter.Seq[T] {
+	return func(yield func(T) bool) {
+		for _, seq := range seqs {
+			for v := range seq {
+				if !yield(v) {
+					return
+				}
+			}
+		}
+	}
+}
+
+/*
+	Enumerate pairs each element with its position, counting from zero.
+	Enumerate 는 각 원소를 0 부터 센 위치와 짝짓는다.
+*/
+func Enumerate[T any](seq iter.Seq[T]) iter.Seq2[int, T] {
+	return func(yield func(int, T) bool) {
+		i := 0
+		for v := range seq {
+			if !yield(i, v) {
+				return
+			}
+			i++
+		}
+	}
+}
+
+/*
+	naturals is an endless sequence, which only a lazy pipeline can use.
+	naturals 는 끝없는 시퀀스로, 게으른 파이프라인만이 그것을 사용할 수 있다.
+*/
+func naturals() iter.Seq[int] {
+	return func(yield func(int) bool) {
+		for i := 0; ; i++ {
+			if !yield(i) {
+				return
+			}
+		}
+	}
+}
+
+/*
+	lazyExample only shows what the sequences do. How they compare with Filter and Map is measured by BenchmarkChain in lazy_test.go,
+	run with `go test -bench Chain -benchmem`.
+	lazyExample 은 시퀀스들이 무엇을 하는지만 보여준다. 그것들이 Filter 와 Map 에 비해 어떤지는 lazy_test.go 의 BenchmarkChain 이 측정하며,
+	`go test -bench Chain -benchmem` 으로 실행한다.
+*/
+func lazyExample(out io.Writer) {
+	squares := MapSeq(naturals(), func(v int) int { return v * v })
+	fmt.Fprintln(out, slices.Collect(Take(Skip(squares, 1), 5)))
+	fmt.Fprintln(out, slices.Collect(TakeWhile(squares, func(v int) bool { return v < 50 })))
+
+	queue := make(chan string, 2)
+	queue <- "one"
+	queue <- "two"
+	close(queue)
+	words := Chain(FromChan(queue), FromSlice([]string{"three", "four"}))
+	for i, w := range Enumerate(words) {
+		fmt.Fprintln(out, i, w)
+	}
+
+	sc := bufio.NewScanner(strings.NewReader("peach\napple\n\npear\nplum\n"))
+	nonEmpty := FilterSeq(Lines(sc), func(v string) bool { return v != "" })
+	fmt.Fprintln(out, slices.Collect(MapSeq(nonEmpty, strings.ToUpper)), sc.Err())
+
+	total := 0
+	for _, v := range FromMap(map[string]int{"a": 1, "b": 2, "c": 3}) {
+		total += v
+	}
+	fmt.Fprintln(out, total)
+}
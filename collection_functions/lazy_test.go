@@ -0,0 +1,210 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"iter"
+	"maps"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func chanOf[T any](vs ...T) <-chan T {
+	ch := make(chan T, len(vs))
+	for _, v := range vs {
+		ch <- v
+	}
+	close(ch)
+	return ch
+}
+
+/*
+	TestSequences collects each sequence in a table, the way TestCollections checks the eager functions.
+	Sequences of pairs are collected into maps.
+	TestSequences 는 TestCollections 가 즉시 실행되는 함수들을 확인하는 방식으로, 표 안의 각 시퀀스를 모은다.
+	쌍의 시퀀스들은 map 으로 모인다.
+*/
+func TestSequences(t *testing.T) {
+	ints := []int{3, 1, 4, 1, 5}
+	strs := []string{"peach", "apple", "pear", "plum"}
+	lines := func(s string) iter.Seq[string] {
+		return Lines(bufio.NewScanner(strings.NewReader(s)))
+	}
+
+	for _, tc := range []struct {
+		name      string
+		got, want any
+	}{
+		{"Skip", slices.Collect(Skip(FromSlice(ints), 2)), []int{4, 1, 5}},
+		{"Skip none", slices.Collect(Skip(FromSlice(ints), 0)), ints},
+		{"Skip more than there are", slices.Collect(Skip(FromSlice(ints), 10)), []int(nil)},
+		{"Skip negative", slices.Collect(Skip(FromSlice(strs), -1)), strs},
+
+		{"TakeWhile", slices.Collect(TakeWhile(FromSlice(ints), func(v int) bool { return v < 4 })), []int{3, 1}},
+		{"TakeWhile all", slices.Collect(TakeWhile(FromSlice(strs), func(v string) bool { return v != "" })), strs},
+		{"TakeWhile none", slices.Collect(TakeWhile(FromSlice(strs), func(v string) bool { return v == "" })), []string(nil)},
+
+		{"Chain", slices.Collect(Chain(FromSlice(ints[:2]), FromSlice(ints[3:]))), []int{3, 1, 1, 5}},
+		{"Chain with empty", slices.Collect(Chain(FromSlice([]string{}), FromSlice(strs[:1]), FromSlice[string](nil))), []string{"peach"}},
+		{"Chain nothing", slices.Collect(Chain[int]()), []int(nil)},
+
+		{"Enumerate", maps.Collect(Enumerate(FromSlice(strs[:3]))), map[int]string{0: "peach", 1: "apple", 2: "pear"}},
+		{"Enumerate after Skip", maps.Collect(Enumerate(Skip(FromSlice(ints), 3))), map[int]int{0: 1, 1: 5}},
+		{"Enumerate empty", maps.Collect(Enumerate(FromSlice([]int{}))), map[int]int{}},
+
+		{"FromMap", maps.Collect(FromMap(map[string]int{"a": 1, "b": 2})), map[string]int{"a": 1, "b": 2}},
+		{"FromMap empty", maps.Collect(FromMap(map[int]bool(nil))), map[int]bool{}},
+
+		{"FromChan", slices.Collect(FromChan(chanOf(strs...))), strs},
+		{"FromChan empty", slices.Collect(FromChan(chanOf[int]())), []int(nil)},
+
+		{"Lines", slices.Collect(lines("peach\napple\n\npear")), []string{"peach", "apple", "", "pear"}},
+		{"Lines CRLF", slices.Collect(lines("peach\r\napple\r\n")), []string{"peach", "apple"}},
+		{"Lines empty", slices.Collect(lines("")), []string(nil)},
+	} {
+		if !reflect.DeepEqual(tc.got, tc.want) {
+			t.Errorf("%s: got %v, want %v", tc.name, tc.got, tc.want)
+		}
+	}
+}
+
+/*
+	TestEarlyTermination runs each pipeline over an endless source that counts how many elements it produced,
+	so a step that kept pulling after it had enough would show up in the count, or never return.
+	TestEarlyTermination 은 각 파이프라인을 자신이 몇 개의 원소를 만들었는지 세는 끝없는 소스 위에서 실행하므로,
+	충분히 가진 뒤에도 계속 끌어오는 단계는 그 수에서 드러나거나, 절대 반환하지 않을 것이다.
+*/
+func TestEarlyTermination(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		pipeline func(src iter.Seq[int]) []int
+		want     []int
+		produced int
+	}{
+		{"Take", func(src iter.Seq[int]) []int {
+			return slices.Collect(Take(src, 3))
+		}, []int{0, 1, 2}, 3},
+		{"Take zero", func(src iter.Seq[int]) []int {
+			return slices.Collect(Take(src, 0))
+		}, nil, 0},
+		{"Skip then Take", func(src iter.Seq[int]) []int {
+			return slices.Collect(Take(Skip(src, 2), 2))
+		}, []int{2, 3}, 4},
+		{"FilterSeq and MapSeq then Take", func(src iter.Seq[int]) []int {
+			odd := FilterSeq(src, func(v int) bool { return v%2 == 1 })
+			return slices.Collect(Take(MapSeq(odd, func(v int) int { return v * 10 }), 2))
+		}, []int{10, 30}, 4},
+		{"TakeWhile reads the first failing element", func(src iter.Seq[int]) []int {
+			return slices.Collect(TakeWhile(src, func(v int) bool { return v < 2 }))
+		}, []int{0, 1}, 3},
+		{"Chain stops in the first sequence", func(src iter.Seq[int]) []int {
+			return slices.Collect(Take(Chain(src, naturals()), 2))
+		}, []int{0, 1}, 2},
+		{"break out of Enumerate", func(src iter.Seq[int]) []int {
+			var got []int
+			for i, v := range Enumerate(src) {
+				if i == 2 {
+					break
+				}
+				got = append(got, v)
+			}
+			return got
+		}, []int{0, 1}, 3},
+	} {
+		produced := 0
+		src := func(yield func(int) bool) {
+			for v := range naturals() {
+				produced++
+				if !yield(v) {
+					return
+				}
+			}
+		}
+		if got := tc.pipeline(src); !slices.Equal(got, tc.want) {
+			t.Errorf("%s: got %v, want %v", tc.name, got, tc.want)
+		}
+		if produced != tc.produced {
+			t.Errorf("%s: the source produced %d elements, want %d", tc.name, produced, tc.produced)
+		}
+	}
+
+	ch := chanOf(1, 2, 3, 4)
+	for v := range FromChan(ch) {
+		if v == 2 {
+			break
+		}
+	}
+	if n := len(ch); n != 2 {
+		t.Errorf("FromChan: %d elements left in the channel after a break, want 2", n)
+	}
+}
+
+/*
+	The chains run the same filters and maps over 10,000 ints, eagerly and lazily, and keep the first n results.
+	체인들은 10,000 개의 int 위에서 같은 filter 와 map 들을 즉시 그리고 게으르게 실행하고, 처음 n 개의 결과를 유지한다.
+*/
+var benchInput = slices.Collect(Take(naturals(), 10000))
+
+func eagerChain(vs []int, n int) []int {
+	vs = Filter(vs, func(v int) bool { return v%2 == 0 })
+	vs = Map(vs, func(v int) int { return v * 3 })
+	vs = Filter(vs, func(v int) bool { return v%4 == 0 })
+	vs = Map(vs, func(v int) int { return v + 1 })
+	return vs[:min(n, len(vs))]
+}
+
+func lazyChain(vs []int, n int) []int {
+	seq := FilterSeq(FromSlice(vs), func(v int) bool { return v%2 == 0 })
+	seq = MapSeq(seq, func(v int) int { return v * 3 })
+	seq = FilterSeq(seq, func(v int) bool { return v%4 == 0 })
+	seq = MapSeq(seq, func(v int) int { return v + 1 })
+	return slices.Collect(Take(seq, n))
+}
+
+/*
+	Keeping 10 results lets the lazy chain stop after a few dozen inputs, which says more about early termination than about the chains,
+	so every chain is also run to the end of its input.
+	10 개의 결과를 유지하면 게으른 체인은 수십 개의 입력 뒤에 멈출 수 있는데, 이것은 체인들보다는 조기 종료에 대해 더 많이 말해준다,
+	그래서 모든 체인은 입력의 끝까지도 실행된다.
+*/
+var chainCases = []struct {
+	name string
+	n    int
+}{
+	{"first10", 10},
+	{"all", len(benchInput)},
+}
+
+/*
+	TestChain checks that both chains agree, so the benchmark compares two ways of computing the same thing.
+	TestChain 은 두 체인이 일치하는지 확인하므로, 벤치마크는 같은 것을 계산하는 두 방법을 비교한다.
+*/
+func TestChain(t *testing.T) {
+	for _, tc := range chainCases {
+		eager, lazy := eagerChain(benchInput, tc.n), lazyChain(benchInput, tc.n)
+		if !slices.Equal(eager, lazy) {
+			t.Errorf("%s: eager %d results, lazy %d results, not equal", tc.name, len(eager), len(lazy))
+		}
+	}
+}
+
+func BenchmarkChain(b *testing.B) {
+	for _, tc := range chainCases {
+		for _, chain := range []struct {
+			name string
+			fn   func([]int, int) []int
+		}{
+			{"eager", eagerChain},
+			{"lazy", lazyChain},
+		} {
+			b.Run(fmt.Sprintf("%s/%s", tc.name, chain.name), func(b *testing.B) {
+				b.ReportAllocs()
+				for i := 0; i < b.N; i++ {
+					chain.fn(benchInput, tc.n)
+				}
+			})
+		}
+	}
+}
@@ -0,0 +1,225 @@
+package main
+
+import (
+	"context"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"io"
+	"runtime/debug"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+)
+
+/*
+	Map calls f on one element at a time, which is fine until f is expensive, like hashing or asking another service.
+	ParallelMap runs f on up to workers elements at once with the worker pool from worker_pools: workers receive jobs on a jobs channel and send results on a results channel.
+	Each job carries its element's index, so results can arrive in any order and still land in the right place in the output.
+	The first error stops the work: no more jobs are handed out and the context passed to f is canceled, so calls in flight can give up too.
+	A panic in f is an error like any other, so one bad element cannot take down the caller.
+	Map 은 한 번에 하나의 원소에 f 를 호출하는데, f 가 해싱이나 다른 서비스에 묻는 것처럼 비싸기 전까지는 괜찮다.
+	ParallelMap 은 worker_pools 의 worker pool 로 최대 workers 개의 원소에 동시에 f 를 실행한다: worker 들은 jobs 채널에서 job 을 받고 results 채널로 결과를 보낸다.
+	각 job 은 자신의 원소의 인덱스를 가지므로, 결과들은 어떤 순서로 도착해도 출력의 올바른 자리에 놓인다.
+	첫 에러가 일을 멈춘다: 더 이상 job 이 나눠지지 않고 f 에 전달된 context 가 취소되므로, 진행 중인 호출들도 포기할 수 있다.
+	f 안의 panic 은 다른 것과 같은 에러이므로, 나쁜 원소 하나가 호출자를 쓰러뜨릴 수 없다.
+*/
+
+/*
+	ItemError is the error f returned for the element at Index.
+	ItemError 는 Index 에 있는 원소에 대해 f 가 반환한 에러이다.
+*/
+type ItemError struct {
+	Index int
+	Err   error
+}
+
+func (e *ItemError) Error() string {
+	return fmt.Sprintf("item %d: %v", e.Index, e.Err)
+}
+
+func (e *ItemError) Unwrap() error {
+	return e.Err
+}
+
+/*
+	PanicError is what a panic in f becomes, with the stack where it happened; it arrives wrapped in the element's ItemError.
+	PanicError 는 f 안의 panic 이 변한 것으로, panic 이 일어난 곳의 스택을 가진다; 그것은 원소의 ItemError 에 감싸져서 도착한다.
+*/
+type PanicError struct {
+	Value any
+	Stack []byte
+}
+
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("panic: %v", e.Value)
+}
+
+type job[T any] struct {
+	index int
+	item  T
+}
+
+type result[U any] struct {
+	index int
+	val   U
+	err   error
+}
+
+func mapWorker[T, U any](ctx context.Context, f func(context.Context, T) (U, error), jobs <-chan job[T], results chan<- result[U]) {
+	for j := range jobs {
+		val, err := callItem(ctx, f, j.item)
+		results <- result[U]{index: j.index, val: val, err: err}
+	}
+}
+
+func callItem[T, U any](ctx context.Context, f func(context.Context, T) (U, error), item T) (val U, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			var zero U
+			val, err = zero, &PanicError{Value: r, Stack: debug.Stack()}
+		}
+	}()
+	return f(ctx, item)
+}
+
+/*
+	ParallelMap returns f applied to every element, in input order, with at most workers calls of f running at once.
+	If any call fails the error joins an ItemError for every element whose f failed, in index order, and the output is partial:
+	elements whose f failed or never ran hold the zero value, and the rest hold their results.
+	If ctx ends first its error is joined in as well. Calls that only failed because the work was stopped are not reported.
+	ParallelMap 은 모든 원소에 f 를 적용한 것을 입력 순서대로 반환하며, 동시에 실행되는 f 의 호출은 최대 workers 개이다.
+	어떤 호출이 실패하면 에러는 f 가 실패한 모든 원소의 ItemError 를 인덱스 순서로 합치며, 출력은 부분적이다:
+	f 가 실패했거나 실행되지 않은 원소들은 zero value 를 가지고, 나머지는 자신의 결과를 가진다.
+	ctx 가 먼저 끝나면 그 에러도 합쳐진다. 일이 멈췄기 때문에만 실패한 호출들은 보고되지 않는다.
+*/
+func ParallelMap[T, U any](ctx context.Context, vs []T, workers int, f func(context.Context, T) (U, error)) ([]U, error) {
+	workers = max(min(workers, len(vs)), 1)
+	parent := ctx
+	ctx, cancel := context.WithCancel(parent)
+	defer cancel()
+
+	jobs := make(chan job[T])
+	results := make(chan result[U], workers)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			mapWorker(ctx, f, jobs, results)
+		}()
+	}
+
+	/*
+		Jobs are handed out one at a time, so stopping leaves the rest of them undispatched.
+		job 들은 한 번에 하나씩 나눠지므로, 멈추면 나머지는 나눠지지 않은 채로 남는다.
+	*/
+	go func() {
+		defer close(jobs)
+		for i, v := range vs {
+			select {
+			case jobs <- job[T]{index: i, item: v}:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
+
+	out := make([]U, len(vs))
+	var errs []*ItemError
+	for r := range results {
+		if r.err == nil {
+			out[r.index] = r.val
+			continue
+		}
+		if ctx.Err() != nil && errors.Is(r.err, ctx.Err()) {
+			continue
+		}
+		errs = append(errs, &ItemError{Index: r.index, Err: r.err})
+		cancel()
+	}
+
+	sort.Slice(errs, func(i, j int) bool { return errs[i].Index < errs[j].Index })
+	joined := make([]error, 0, len(errs)+1)
+	for _, e := range errs {
+		joined = append(joined, e)
+	}
+	if err := parent.Err(); err != nil {
+		joined = append(joined, err)
+	}
+	return out, errors.Join(joined...)
+}
+
+/*
+	ParallelFilter is ParallelMap for a predicate: it keeps the elements for which f returns true, in input order.
+	Its output is partial in the same way: on an error it holds the elements f was run on and returned true for, and leaves out the rest.
+	ParallelFilter 는 술어를 위한 ParallelMap 이다: f 가 true 를 반환하는 원소들을 입력 순서대로 유지한다.
+	그것의 출력도 같은 방식으로 부분적이다: 에러가 나면 f 가 실행되어 true 를 반환한 원소들을 가지고, 나머지는 빠뜨린다.
+*/
+func ParallelFilter[T any](ctx context.Context, vs []T, workers int, f func(context.Context, T) (bool, error)) ([]T, error) {
+	keep, err := ParallelMap(ctx, vs, workers, f)
+	vsf := make([]T, 0)
+	for i, v := range vs {
+		if keep[i] {
+			vsf = append(vsf, v)
+		}
+	}
+	return vsf, err
+}
+
+/*
+	parallelExample hashes strings with a stub lookup that takes 10ms each, first one at a time with Map, then with ParallelMap.
+	Then it shows a lookup that fails, and a deadline that runs out.
+	parallelExample 은 각각 10ms 가 걸리는 가짜 조회로 문자열들을 해싱하는데, 먼저 Map 으로 하나씩 하고, 그 다음 ParallelMap 으로 한다.
+	그런 다음 실패하는 조회와, 다 되어버리는 기한을 보여준다.
+*/
+func parallelExample(out io.Writer) {
+	lookup := func(ctx context.Context, v string) (string, error) {
+		select {
+		case <-time.After(10 * time.Millisecond):
+		case <-ctx.Done():
+			return "", ctx.Err()
+		}
+		if v == "durian" {
+			return "", errors.New("not found")
+		}
+		sum := sha256.Sum256([]byte(v))
+		return fmt.Sprintf("%x", sum[:4]), nil
+	}
+	fruits := []string{"peach", "apple", "pear", "plum", "fig", "kiwi", "lime", "lemon", "mango", "grape", "melon", "date"}
+	ctx := context.Background()
+
+	start := time.Now()
+	sequential := Map(fruits, func(v string) string {
+		h, _ := lookup(ctx, v)
+		return h
+	})
+	fmt.Fprintf(out, "Map:         %v\n", time.Since(start).Round(10*time.Millisecond))
+
+	start = time.Now()
+	parallel, err := ParallelMap(ctx, fruits, 4, lookup)
+	fmt.Fprintf(out, "ParallelMap: %v same order: %v err: %v\n", time.Since(start).Round(10*time.Millisecond), slices.Equal(sequential, parallel), err)
+
+	short, err := ParallelFilter(ctx, fruits, 4, func(ctx context.Context, v string) (bool, error) {
+		h, err := lookup(ctx, v)
+		if err != nil {
+			return false, err
+		}
+		return len(v) <= 4 && h[0] < '8', nil
+	})
+	fmt.Fprintln(out, "ParallelFilter:", short, err)
+
+	_, err = ParallelMap(ctx, append([]string{"durian"}, fruits...), 4, lookup)
+	var itemErr *ItemError
+	fmt.Fprintln(out, "failed lookup:", err, errors.As(err, &itemErr) && itemErr.Index == 0)
+
+	ctx, cancel := context.WithTimeout(ctx, 15*time.Millisecond)
+	defer cancel()
+	_, err = ParallelMap(ctx, fruits, 2, lookup)
+	fmt.Fprintln(out, "deadline:", errors.Is(err, context.DeadlineExceeded))
+}
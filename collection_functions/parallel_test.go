@@ -0,0 +1,252 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+)
+
+/*
+	TestParallel runs ParallelMap and ParallelFilter over 1..8 with one worker, so elements are handed out in order,
+	and checks the partial output and the ItemError when f fails or panics on 5.
+	Work stops at the failure, but the element after it may already be in flight, so those are allowed to be either done or not.
+	TestParallel 은 하나의 worker 로 1..8 위에서 ParallelMap 과 ParallelFilter 를 실행해서 원소들이 순서대로 나눠지게 하고,
+	f 가 5 에서 실패하거나 panic 할 때의 부분 출력과 ItemError 를 확인한다.
+	일은 실패에서 멈추지만 그 뒤의 원소는 이미 진행 중일 수 있으므로, 그것들은 끝났든 아니든 허용된다.
+*/
+func TestParallel(t *testing.T) {
+	vs := []int{1, 2, 3, 4, 5, 6, 7, 8}
+	errBad := errors.New("bad")
+	for _, tc := range []struct {
+		name    string
+		fail    func(v int) error
+		isErr   func(err error) bool
+		wantErr bool
+	}{
+		{"ok", func(int) error { return nil }, nil, false},
+		{"error", func(v int) error {
+			if v == 5 {
+				return errBad
+			}
+			return nil
+		}, func(err error) bool { return errors.Is(err, errBad) }, true},
+		{"panic", func(v int) error {
+			if v == 5 {
+				panic("boom")
+			}
+			return nil
+		}, func(err error) bool {
+			var pe *PanicError
+			return errors.As(err, &pe) && pe.Value == "boom"
+		}, true},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			square := func(ctx context.Context, v int) (int, error) {
+				if err := tc.fail(v); err != nil {
+					return 0, err
+				}
+				return v * v, nil
+			}
+			out, err := ParallelMap(context.Background(), vs, 1, square)
+			checkItemError(t, err, tc.wantErr, tc.isErr)
+			for i, v := range vs {
+				switch {
+				case !tc.wantErr || v < 5:
+					if out[i] != v*v {
+						t.Errorf("map: out[%d] = %d, want %d", i, out[i], v*v)
+					}
+				case v == 5:
+					if out[i] != 0 {
+						t.Errorf("map: out[%d] = %d for the failed element, want 0", i, out[i])
+					}
+				default:
+					if out[i] != 0 && out[i] != v*v {
+						t.Errorf("map: out[%d] = %d, want 0 or %d", i, out[i], v*v)
+					}
+				}
+			}
+
+			odd := func(ctx context.Context, v int) (bool, error) {
+				if err := tc.fail(v); err != nil {
+					return false, err
+				}
+				return v%2 == 1, nil
+			}
+			kept, err := ParallelFilter(context.Background(), vs, 1, odd)
+			checkItemError(t, err, tc.wantErr, tc.isErr)
+			want := "[1 3 5 7]"
+			if tc.wantErr {
+				want = "[1 3]"
+			}
+			if got := fmt.Sprint(kept); got != want && !(tc.wantErr && got == "[1 3 7]") {
+				t.Errorf("filter: kept %s, want %s", got, want)
+			}
+		})
+	}
+}
+
+func checkItemError(t *testing.T, err error, wantErr bool, isErr func(error) bool) {
+	t.Helper()
+	if !wantErr {
+		if err != nil {
+			t.Errorf("got %v", err)
+		}
+		return
+	}
+	var ie *ItemError
+	if !errors.As(err, &ie) || ie.Index != 4 || !isErr(err) {
+		t.Errorf("got %v, want an ItemError for index 4", err)
+	}
+}
+
+/*
+	TestParallelWorkers counts the calls of f running at once. Every call waits until the limit is reached before it returns,
+	so the pool has to get there, and the peak shows that it never goes past it.
+	TestParallelWorkers 는 동시에 실행되는 f 의 호출들을 센다. 모든 호출은 반환하기 전에 한도에 도달할 때까지 기다리므로,
+	pool 은 거기에 도달해야 하고, 최고치는 그것이 절대 한도를 넘지 않는다는 것을 보여준다.
+*/
+func TestParallelWorkers(t *testing.T) {
+	vs := make([]int, 16)
+	for _, tc := range []struct {
+		name    string
+		workers int
+		limit   int
+	}{
+		{"one", 1, 1},
+		{"some", 3, 3},
+		{"as many as items", 16, 16},
+		{"more than items", 100, 16},
+		{"zero means one", 0, 1},
+	} {
+		var mu sync.Mutex
+		running, peak := 0, 0
+		full := make(chan struct{})
+		_, err := ParallelMap(context.Background(), vs, tc.workers, func(ctx context.Context, v int) (int, error) {
+			mu.Lock()
+			running++
+			peak = max(peak, running)
+			if running == tc.limit {
+				select {
+				case <-full:
+				default:
+					close(full)
+				}
+			}
+			mu.Unlock()
+			<-full
+			mu.Lock()
+			running--
+			mu.Unlock()
+			return v, nil
+		})
+		if err != nil || peak != tc.limit {
+			t.Errorf("%s: %d calls at once, %v, want %d", tc.name, peak, err, tc.limit)
+		}
+	}
+}
+
+/*
+	TestParallelCancel ends the caller's ctx before or while f runs. Every call gives up with the ctx's error,
+	which is only reported once, as the ctx's error and not as an ItemError, and no element gets a result.
+	TestParallelCancel 은 f 가 실행되기 전이나 실행되는 동안 호출자의 ctx 를 끝낸다. 모든 호출은 ctx 의 에러와 함께 포기하며,
+	그것은 ItemError 가 아니라 ctx 의 에러로서 한 번만 보고되고, 어떤 원소도 결과를 얻지 못한다.
+*/
+func TestParallelCancel(t *testing.T) {
+	vs := []int{1, 2, 3, 4, 5, 6, 7, 8}
+	for _, tc := range []struct {
+		name string
+		ctx  func(started <-chan struct{}) (context.Context, context.CancelFunc)
+		want error
+	}{
+		{"canceled before", func(<-chan struct{}) (context.Context, context.CancelFunc) {
+			ctx, cancel := context.WithCancel(context.Background())
+			cancel()
+			return ctx, cancel
+		}, context.Canceled},
+		{"canceled while running", func(started <-chan struct{}) (context.Context, context.CancelFunc) {
+			ctx, cancel := context.WithCancel(context.Background())
+			go func() {
+				<-started
+				cancel()
+			}()
+			return ctx, cancel
+		}, context.Canceled},
+		{"deadline", func(<-chan struct{}) (context.Context, context.CancelFunc) {
+			return context.WithTimeout(context.Background(), 10*time.Millisecond)
+		}, context.DeadlineExceeded},
+	} {
+		started := make(chan struct{})
+		var once sync.Once
+		ctx, cancel := tc.ctx(started)
+		out, err := ParallelMap(ctx, vs, 3, func(ctx context.Context, v int) (int, error) {
+			once.Do(func() { close(started) })
+			<-ctx.Done()
+			return v, ctx.Err()
+		})
+		cancel()
+		var ie *ItemError
+		if !errors.Is(err, tc.want) || errors.As(err, &ie) {
+			t.Errorf("%s: got %v, want only %v", tc.name, err, tc.want)
+		}
+		if fmt.Sprint(out) != "[0 0 0 0 0 0 0 0]" {
+			t.Errorf("%s: out %v, want no results", tc.name, out)
+		}
+	}
+}
+
+/*
+	TestParallelErrors fails three elements that are all running before any of them returns, and lets them return in reverse order.
+	Each failure is its own error, not the ctx's, so all three are reported, joined in index order.
+	TestParallelErrors 는 그중 어느 것도 반환하기 전에 모두 실행 중인 세 원소를 실패시키고, 그것들이 역순으로 반환하게 한다.
+	각 실패는 ctx 의 것이 아닌 자신의 에러이므로, 셋 모두 인덱스 순서로 합쳐져서 보고된다.
+*/
+func TestParallelErrors(t *testing.T) {
+	vs := []int{0, 1, 2, 3, 4, 5}
+	failing := map[int]chan struct{}{1: make(chan struct{}), 3: make(chan struct{}), 4: make(chan struct{})}
+	after := map[int]int{1: 3, 3: 4}
+	var mu sync.Mutex
+	waiting := 0
+	all := make(chan struct{})
+	out, err := ParallelMap(context.Background(), vs, len(vs), func(ctx context.Context, v int) (int, error) {
+		returned, ok := failing[v]
+		if !ok {
+			return v * v, nil
+		}
+		defer close(returned)
+		mu.Lock()
+		if waiting++; waiting == len(failing) {
+			close(all)
+		}
+		mu.Unlock()
+		<-all
+		if next, ok := after[v]; ok {
+			<-failing[next]
+		}
+		return 0, fmt.Errorf("bad %d", v)
+	})
+
+	joined, ok := err.(interface{ Unwrap() []error })
+	if !ok {
+		t.Fatalf("got %v, want joined errors", err)
+	}
+	var got []int
+	for _, e := range joined.Unwrap() {
+		var ie *ItemError
+		if !errors.As(e, &ie) || ie.Err.Error() != fmt.Sprintf("bad %d", ie.Index) {
+			t.Errorf("got %v, want an ItemError", e)
+			continue
+		}
+		got = append(got, ie.Index)
+	}
+	if fmt.Sprint(got) != "[1 3 4]" {
+		t.Errorf("errors for %v, want [1 3 4]", got)
+	}
+	for _, i := range []int{1, 3, 4} {
+		if out[i] != 0 {
+			t.Errorf("out[%d] = %d for a failed element, want 0", i, out[i])
+		}
+	}
+}
@@ -0,0 +1,469 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+/*
+	Filtering a slice of structs like person from structs/structs.go is always the same few loops: pick some, sort them, take a page, count per group.
+	Query strings those together. A field is addressed by name: by default the name is looked up on the struct with reflection
+	(case-insensitively, and unexported fields such as person's work too), and Accessor can register a typed func under a name instead,
+	for computed fields or to avoid reflection.
+	Like bufio.Scanner a Query remembers its first error, such as an unknown field, and every later step is skipped; Items, Select and GroupBy report it.
+	structs/structs.go 의 person 같은 struct 의 슬라이스를 거르는 것은 항상 같은 몇 개의 반복문이다: 몇 개를 고르고, 정렬하고, 한 페이지를 가져오고, 그룹별로 센다.
+	Query 는 그것들을 엮는다. 필드는 이름으로 지정된다: 기본적으로 이름은 reflection 으로 struct 에서 찾아지고
+	(대소문자를 구별하지 않으며, person 의 것 같은 unexported 필드도 동작한다), Accessor 는 대신 타입이 있는 함수를 이름으로 등록할 수 있는데,
+	계산된 필드를 위해서나 reflection 을 피하기 위해서이다.
+	bufio.Scanner 처럼 Query 는 알 수 없는 필드 같은 첫 에러를 기억하고, 이후의 모든 단계는 건너뛰어진다; Items, Select 그리고 GroupBy 가 그것을 알려준다.
+*/
+type Query[T any] struct {
+	items     []T
+	accessors map[string]func(T) any
+	err       error
+}
+
+/*
+	UnknownFieldError names the field that was asked for and lists the ones that exist.
+	UnknownFieldError 는 요청된 필드의 이름을 말하고 존재하는 필드들을 나열한다.
+*/
+type UnknownFieldError struct {
+	Type  string
+	Field string
+	Known []string
+}
+
+func (e *UnknownFieldError) Error() string {
+	return fmt.Sprintf("query: %s has no field %q (fields: %s)", e.Type, e.Field, strings.Join(e.Known, ", "))
+}
+
+/*
+	From starts a query over items. The slice itself is never changed.
+	From 은 items 위의 query 를 시작한다. 슬라이스 자체는 절대 바뀌지 않는다.
+*/
+func From[T any](items []T) *Query[T] {
+	return &Query[T]{items: items, accessors: make(map[string]func(T) any)}
+}
+
+/*
+	Accessor makes name refer to get, ahead of any struct field with that name.
+	Accessor 는 name 이 get 을 가리키게 하며, 그 이름을 가진 어떤 struct 필드보다 우선한다.
+*/
+func (q *Query[T]) Accessor(name string, get func(T) any) *Query[T] {
+	q.accessors[strings.ToLower(name)] = get
+	return q
+}
+
+/*
+	field returns a getter for name. The getter fails instead of panicking when T is a pointer and the item is nil.
+	field 는 name 을 위한 getter 를 반환한다. getter 는 T 가 포인터이고 항목이 nil 일 때 panic 하는 대신 실패한다.
+*/
+func (q *Query[T]) field(name string) (func(T) (any, error), error) {
+	if get, ok := q.accessors[strings.ToLower(name)]; ok {
+		return func(item T) (any, error) { return scalar(reflect.ValueOf(get(item))), nil }, nil
+	}
+	typ := reflect.TypeFor[T]()
+	base := typ
+	if base.Kind() == reflect.Pointer {
+		base = base.Elem()
+	}
+	if base.Kind() == reflect.Struct {
+		for i := 0; i < base.NumField(); i++ {
+			if strings.EqualFold(base.Field(i).Name, name) {
+				return func(item T) (any, error) {
+					v := reflect.ValueOf(&item).Elem()
+					if v.Kind() == reflect.Pointer {
+						if v.IsNil() {
+							return nil, fmt.Errorf("field %s of a nil %s", base.Field(i).Name, typ)
+						}
+						v = v.Elem()
+					}
+					return scalar(v.Field(i)), nil
+				}, nil
+			}
+		}
+	}
+	known := make([]string, 0, len(q.accessors))
+	for name := range q.accessors {
+		known = append(known, name)
+	}
+	if base.Kind() == reflect.Struct {
+		for i := 0; i < base.NumField(); i++ {
+			known = append(known, base.Field(i).Name)
+		}
+	}
+	sort.Strings(known)
+	return nil, &UnknownFieldError{Type: typ.String(), Field: name, Known: known}
+}
+
+/*
+	scalar turns a field into a value that can be compared and summed: every number becomes a float64.
+	It uses the kind-specific getters rather than Interface, which would panic on an unexported field.
+	scalar 는 필드를 비교하고 더할 수 있는 값으로 바꾼다: 모든 숫자는 float64 가 된다.
+	unexported 필드에서 panic 할 Interface 대신 kind 별 getter 들을 사용한다.
+*/
+func scalar(v reflect.Value) any {
+	switch v.Kind() {
+	case reflect.Invalid:
+		return nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(v.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return float64(v.Uint())
+	case reflect.Float32, reflect.Float64:
+		return v.Float()
+	case reflect.String:
+		return v.String()
+	case reflect.Bool:
+		return v.Bool()
+	}
+	if v.CanInterface() {
+		return v.Interface()
+	}
+	return v.String()
+}
+
+func compareValues(a, b any) (int, error) {
+	a, b = scalar(reflect.ValueOf(a)), scalar(reflect.ValueOf(b))
+	switch x := a.(type) {
+	case float64:
+		if y, ok := b.(float64); ok {
+			switch {
+			case x < y:
+				return -1, nil
+			case x > y:
+				return 1, nil
+			}
+			return 0, nil
+		}
+	case string:
+		if y, ok := b.(string); ok {
+			return strings.Compare(x, y), nil
+		}
+	case bool:
+		if y, ok := b.(bool); ok {
+			switch {
+			case x == y:
+				return 0, nil
+			case !x:
+				return -1, nil
+			}
+			return 1, nil
+		}
+	}
+	return 0, fmt.Errorf("cannot compare %T with %T", a, b)
+}
+
+/*
+	compareField compares the field get reads from a and b.
+	compareField 는 get 이 a 와 b 에서 읽는 필드를 비교한다.
+*/
+func compareField[T any](get func(T) (any, error), a, b T) (int, error) {
+	x, err := get(a)
+	if err != nil {
+		return 0, err
+	}
+	y, err := get(b)
+	if err != nil {
+		return 0, err
+	}
+	return compareValues(x, y)
+}
+
+/*
+	Filter keeps the items for which pred returns true; it is the typed Where.
+	Filter 는 pred 가 true 를 반환하는 항목들을 유지한다; 타입이 있는 Where 이다.
+*/
+func (q *Query[T]) Filter(pred func(T) bool) *Query[T] {
+	if q.err == nil {
+		q.items = Filter(q.items, pred)
+	}
+	return q
+}
+
+/*
+	Where keeps the items whose field compares to value with op, one of = != < <= > >=.
+	Where 는 field 가 op(= != < <= > >= 중 하나)로 value 와 비교되는 항목들을 유지한다.
+*/
+func (q *Query[T]) Where(field, op string, value any) *Query[T] {
+	if q.err != nil {
+		return q
+	}
+	get, err := q.field(field)
+	if err != nil {
+		q.err = err
+		return q
+	}
+	test := map[string]func(c int) bool{
+		"=":  func(c int) bool { return c == 0 },
+		"!=": func(c int) bool { return c != 0 },
+		"<":  func(c int) bool { return c < 0 },
+		"<=": func(c int) bool { return c <= 0 },
+		">":  func(c int) bool { return c > 0 },
+		">=": func(c int) bool { return c >= 0 },
+	}[op]
+	if test == nil {
+		q.err = fmt.Errorf("query: unknown operator %q in Where(%q)", op, field)
+		return q
+	}
+	q.items = Filter(q.items, func(item T) bool {
+		v, err := get(item)
+		c := 0
+		if err == nil {
+			c, err = compareValues(v, value)
+		}
+		if err != nil && q.err == nil {
+			q.err = fmt.Errorf("query: Where(%q, %q, %v): %w", field, op, value, err)
+		}
+		return err == nil && test(c)
+	})
+	return q
+}
+
+/*
+	OrderKey is one key of an OrderBy: a field name and a direction.
+	OrderKey 는 OrderBy 의 key 하나이다: 필드 이름과 방향.
+*/
+type OrderKey struct {
+	Field string
+	Desc  bool
+}
+
+func Asc(field string) OrderKey  { return OrderKey{Field: field} }
+func Desc(field string) OrderKey { return OrderKey{Field: field, Desc: true} }
+
+/*
+	OrderBy sorts by the first key, then by the second among items equal on the first, and so on. Items equal on every key keep their order.
+	OrderBy 는 첫 번째 key 로 정렬하고, 첫 번째에서 같은 항목들 사이에서는 두 번째로, 계속 그렇게 정렬한다. 모든 key 에서 같은 항목들은 그들의 순서를 유지한다.
+*/
+func (q *Query[T]) OrderBy(keys ...OrderKey) *Query[T] {
+	if q.err != nil {
+		return q
+	}
+	gets := make([]func(T) (any, error), len(keys))
+	for i, k := range keys {
+		get, err := q.field(k.Field)
+		if err != nil {
+			q.err = err
+			return q
+		}
+		gets[i] = get
+	}
+	items := append([]T(nil), q.items...)
+	sort.SliceStable(items, func(i, j int) bool {
+		for n, k := range keys {
+			c, err := compareField(gets[n], items[i], items[j])
+			if err != nil && q.err == nil {
+				q.err = fmt.Errorf("query: OrderBy(%q): %w", k.Field, err)
+			}
+			if c != 0 {
+				return (c < 0) != k.Desc
+			}
+		}
+		return false
+	})
+	q.items = items
+	return q
+}
+
+func (q *Query[T]) Offset(n int) *Query[T] {
+	if q.err == nil {
+		q.items = q.items[min(max(n, 0), len(q.items)):]
+	}
+	return q
+}
+
+func (q *Query[T]) Limit(n int) *Query[T] {
+	if q.err == nil {
+		q.items = q.items[:min(max(n, 0), len(q.items))]
+	}
+	return q
+}
+
+func (q *Query[T]) Items() ([]T, error) {
+	if q.err != nil {
+		return nil, q.err
+	}
+	return q.items, nil
+}
+
+/*
+	Row is one result of Select or GroupBy, keyed by field or aggregate name.
+	Row 는 Select 나 GroupBy 의 결과 하나로, 필드나 집계의 이름을 key 로 가진다.
+*/
+type Row map[string]any
+
+/*
+	Select projects every item onto the named fields. For a typed projection use Map on Items.
+	Select 는 모든 항목을 이름 붙은 필드들로 투영한다. 타입이 있는 투영에는 Items 위에 Map 을 사용한다.
+*/
+func (q *Query[T]) Select(fields ...string) ([]Row, error) {
+	if q.err != nil {
+		return nil, q.err
+	}
+	gets := make([]func(T) (any, error), len(fields))
+	for i, f := range fields {
+		get, err := q.field(f)
+		if err != nil {
+			return nil, err
+		}
+		gets[i] = get
+	}
+	rows := make([]Row, 0, len(q.items))
+	for _, item := range q.items {
+		row := make(Row, len(fields))
+		for i, f := range fields {
+			v, err := gets[i](item)
+			if err != nil {
+				return nil, fmt.Errorf("query: Select(%q): %w", f, err)
+			}
+			row[f] = v
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+/*
+	Aggregate computes one value over a group. Count counts its items, Sum and Avg add up a numeric field.
+	Aggregate 는 그룹 위에서 값 하나를 계산한다. Count 는 항목들을 세고, Sum 과 Avg 는 숫자 필드를 더한다.
+*/
+type Aggregate struct {
+	Name  string
+	Field string
+	fn    func(vals []float64) any
+}
+
+func Count() Aggregate {
+	return Aggregate{Name: "count", fn: func(vals []float64) any { return len(vals) }}
+}
+
+func Sum(field string) Aggregate {
+	return Aggregate{Name: "sum(" + field + ")", Field: field, fn: func(vals []float64) any {
+		return Reduce(vals, 0.0, func(a, v float64) float64 { return a + v })
+	}}
+}
+
+func Avg(field string) Aggregate {
+	return Aggregate{Name: "avg(" + field + ")", Field: field, fn: func(vals []float64) any {
+		return Reduce(vals, 0.0, func(a, v float64) float64 { return a + v }) / float64(len(vals))
+	}}
+}
+
+/*
+	GroupBy returns one Row per distinct value of field, ordered by that value, holding the value and every aggregate.
+	The values are map keys and get sorted, so a field whose values cannot be compared with == or with each other is an error.
+	GroupBy 는 field 의 서로 다른 값마다 Row 하나를 그 값의 순서로 반환하며, 그 Row 는 값과 모든 집계를 가진다.
+	값들은 map 의 key 가 되고 정렬되므로, 값들을 == 로나 서로 비교할 수 없는 필드는 에러이다.
+*/
+func (q *Query[T]) GroupBy(field string, aggs ...Aggregate) ([]Row, error) {
+	if q.err != nil {
+		return nil, q.err
+	}
+	key, err := q.field(field)
+	if err != nil {
+		return nil, err
+	}
+	values := make([]func(T) (any, error), len(aggs))
+	for i, a := range aggs {
+		if a.Field == "" {
+			values[i] = func(T) (any, error) { return 0.0, nil }
+			continue
+		}
+		if values[i], err = q.field(a.Field); err != nil {
+			return nil, err
+		}
+	}
+
+	groups := make(map[any][]T)
+	var keys []any
+	for _, item := range q.items {
+		k, err := key(item)
+		if err != nil {
+			return nil, fmt.Errorf("query: GroupBy(%q): %w", field, err)
+		}
+		if k != nil && !reflect.ValueOf(k).Comparable() {
+			return nil, fmt.Errorf("query: GroupBy(%q): %T values cannot be grouped", field, k)
+		}
+		if _, ok := groups[k]; !ok {
+			keys = append(keys, k)
+		}
+		groups[k] = append(groups[k], item)
+	}
+	var sortErr error
+	sort.Slice(keys, func(i, j int) bool {
+		c, err := compareValues(keys[i], keys[j])
+		if err != nil && sortErr == nil {
+			sortErr = fmt.Errorf("query: GroupBy(%q): %w", field, err)
+		}
+		return c < 0
+	})
+	if sortErr != nil {
+		return nil, sortErr
+	}
+
+	rows := make([]Row, 0, len(keys))
+	for _, k := range keys {
+		row := Row{field: k}
+		for i, a := range aggs {
+			vals := make([]float64, 0, len(groups[k]))
+			for _, item := range groups[k] {
+				val, err := values[i](item)
+				if err != nil {
+					return nil, fmt.Errorf("query: %s: %w", a.Name, err)
+				}
+				v, ok := val.(float64)
+				if !ok {
+					return nil, fmt.Errorf("query: %s: field %q is not a number", a.Name, a.Field)
+				}
+				vals = append(vals, v)
+			}
+			row[a.Name] = a.fn(vals)
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+/*
+	person is the struct from structs/structs.go, with a city so there is something to group by.
+	person 은 structs/structs.go 의 struct 로, 그룹으로 묶을 무언가가 있도록 city 를 가진다.
+*/
+type person struct {
+	name string
+	age  int
+	city string
+}
+
+func queryExample(out io.Writer) {
+	people := []person{
+		{"Bob", 20, "Seoul"},
+		{"Alice", 30, "Busan"},
+		{"Fred", 42, "Seoul"},
+		{"Ann", 30, "Seoul"},
+		{"Jon", 25, "Busan"},
+		{"Kim", 51, "Incheon"},
+	}
+
+	adults, err := From(people).Where("age", ">=", 25).OrderBy(Desc("age"), Asc("name")).Offset(1).Limit(3).Items()
+	fmt.Fprintln(out, adults, err)
+
+	rows, err := From(people).Filter(func(p person) bool { return p.city != "Incheon" }).Select("name", "city")
+	fmt.Fprintln(out, rows, err)
+
+	rows, err = From(people).GroupBy("city", Count(), Sum("age"), Avg("age"))
+	fmt.Fprintln(out, rows, err)
+
+	rows, err = From(people).
+		Accessor("decade", func(p person) any { return p.age / 10 * 10 }).
+		GroupBy("decade", Count())
+	fmt.Fprintln(out, rows, err)
+
+	_, err = From(people).Where("agee", ">", 20).OrderBy(Asc("name")).Items()
+	fmt.Fprintln(out, err)
+	_, err = From(people).Where("name", ">", 20).Items()
+	fmt.Fprintln(out, err)
+}
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+type tagged struct {
+	Name string
+	Tags []string
+}
+
+/*
+	TestQueryErrors runs queries that used to panic: a nil element in a slice of pointers, grouping by a slice field,
+	and grouping by values that cannot be ordered against each other. Each must come back as an error naming the problem.
+	TestQueryErrors 는 예전에 panic 하던 query 들을 실행한다: 포인터 슬라이스 안의 nil 원소, 슬라이스 필드로 묶기,
+	그리고 서로 순서를 매길 수 없는 값들로 묶기. 각각은 문제를 말하는 에러로 돌아와야 한다.
+*/
+func TestQueryErrors(t *testing.T) {
+	people := []*person{{"Bob", 20, "Seoul"}, nil, {"Ann", 30, "Busan"}}
+	for _, tc := range []struct {
+		name    string
+		run     func() (any, error)
+		wantErr string
+	}{
+		{"Where over a nil item", func() (any, error) {
+			return From(people).Where("age", ">", 10).Items()
+		}, "field age of a nil *main.person"},
+		{"OrderBy over a nil item", func() (any, error) {
+			return From(people).OrderBy(Asc("name")).Items()
+		}, "field name of a nil *main.person"},
+		{"Select over a nil item", func() (any, error) {
+			return From(people).Select("city")
+		}, "field city of a nil *main.person"},
+		{"GroupBy over a nil item", func() (any, error) {
+			return From(people).GroupBy("city", Count())
+		}, "field city of a nil *main.person"},
+		{"Sum over a nil item", func() (any, error) {
+			return From(people).Accessor("one", func(*person) any { return 1 }).GroupBy("one", Sum("age"))
+		}, "field age of a nil *main.person"},
+		{"GroupBy a slice field", func() (any, error) {
+			return From([]tagged{{"a", []string{"x"}}}).GroupBy("tags", Count())
+		}, "[]string values cannot be grouped"},
+		{"GroupBy mixed values", func() (any, error) {
+			return From([]int{1, 2}).Accessor("v", func(v int) any {
+				if v == 1 {
+					return "one"
+				}
+				return v
+			}).GroupBy("v", Count())
+		}, "cannot compare"},
+		{"no nil items", func() (any, error) {
+			return From([]*person{people[0], people[2]}).GroupBy("city", Count())
+		}, ""},
+	} {
+		got, err := tc.run()
+		switch {
+		case tc.wantErr == "" && err != nil:
+			t.Errorf("%s: got %v", tc.name, err)
+		case tc.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tc.wantErr)):
+			t.Errorf("%s: got %v, %v, want an error containing %q", tc.name, got, err, tc.wantErr)
+		}
+	}
+}
+
+/*
+	TestQuery checks the queries from queryExample.
+	TestQuery 는 queryExample 의 query 들을 확인한다.
+*/
+func TestQuery(t *testing.T) {
+	people := []person{
+		{"Bob", 20, "Seoul"},
+		{"Alice", 30, "Busan"},
+		{"Fred", 42, "Seoul"},
+		{"Ann", 30, "Seoul"},
+		{"Jon", 25, "Busan"},
+		{"Kim", 51, "Incheon"},
+	}
+	for _, tc := range []struct {
+		name string
+		run  func() (any, error)
+		want string
+	}{
+		{"page", func() (any, error) {
+			return From(people).Where("age", ">=", 25).OrderBy(Desc("age"), Asc("name")).Offset(1).Limit(3).Items()
+		}, "[{Fred 42 Seoul} {Alice 30 Busan} {Ann 30 Seoul}]"},
+		{"select", func() (any, error) {
+			return From(people).Where("city", "=", "Busan").Select("name")
+		}, "[map[name:Alice] map[name:Jon]]"},
+		{"group", func() (any, error) {
+			return From(people).GroupBy("city", Count(), Sum("age"))
+		}, "[map[city:Busan count:2 sum(age):55] map[city:Incheon count:1 sum(age):51] map[city:Seoul count:3 sum(age):92]]"},
+		{"unknown field", func() (any, error) {
+			return From(people).Where("agee", ">", 20).Items()
+		}, `query: main.person has no field "agee" (fields: age, city, name)`},
+	} {
+		got, err := tc.run()
+		s := fmt.Sprint(got)
+		if err != nil {
+			s = err.Error()
+		}
+		if s != tc.want {
+			t.Errorf("%s: got %s, want %s", tc.name, s, tc.want)
+		}
+	}
+}
@@ -0,0 +1,249 @@
+package main
+
+import (
+	"cmp"
+	"fmt"
+	"io"
+	"iter"
+	"maps"
+	"slices"
+	"strings"
+	"sync"
+)
+
+/*
+	Include and Index scan the whole slice on every call. When the question is asked often, or only membership matters, a Set answers it in constant time.
+	Set is a map with empty struct values, which take no space, so a nil Set can be read like a nil map but must be made with NewSet before Add.
+	Go ranges over a map in random order, so Sorted and SortedFunc give the elements in a stable order for printing and comparing.
+	Include 와 Index 는 매 호출마다 슬라이스 전체를 훑는다. 그 질문이 자주 물어지거나 포함 여부만 중요하다면, Set 이 상수 시간에 답한다.
+	Set 은 공간을 차지하지 않는 빈 struct 를 값으로 가진 map 이므로, nil Set 은 nil map 처럼 읽을 수 있지만 Add 전에는 NewSet 으로 만들어야 한다.
+	Go 는 map 위를 무작위 순서로 range 하므로, Sorted 와 SortedFunc 가 출력하고 비교하기 위해 원소들을 안정적인 순서로 준다.
+*/
+type Set[T comparable] map[T]struct{}
+
+func NewSet[T comparable](vs ...T) Set[T] {
+	s := make(Set[T], len(vs))
+	s.Add(vs...)
+	return s
+}
+
+func (s Set[T]) Add(vs ...T) {
+	for _, v := range vs {
+		s[v] = struct{}{}
+	}
+}
+
+func (s Set[T]) Remove(vs ...T) {
+	for _, v := range vs {
+		delete(s, v)
+	}
+}
+
+func (s Set[T]) Has(v T) bool {
+	_, ok := s[v]
+	return ok
+}
+
+func (s Set[T]) Len() int {
+	return len(s)
+}
+
+func (s Set[T]) Clone() Set[T] {
+	return maps.Clone(s)
+}
+
+/*
+	Union, Intersection, Difference and SymmetricDifference return a new set and leave both operands alone.
+	Difference is the elements of s that are not in t; SymmetricDifference is the elements in exactly one of them.
+	Union, Intersection, Difference 그리고 SymmetricDifference 는 새로운 set 을 반환하며 두 피연산자는 그대로 둔다.
+	Difference 는 t 에 없는 s 의 원소들이다; SymmetricDifference 는 둘 중 정확히 하나에만 있는 원소들이다.
+*/
+func (s Set[T]) Union(t Set[T]) Set[T] {
+	u := make(Set[T], max(len(s), len(t)))
+	for v := range s {
+		u.Add(v)
+	}
+	for v := range t {
+		u.Add(v)
+	}
+	return u
+}
+
+func (s Set[T]) Intersection(t Set[T]) Set[T] {
+	if len(t) < len(s) {
+		s, t = t, s
+	}
+	i := make(Set[T])
+	for v := range s {
+		if t.Has(v) {
+			i.Add(v)
+		}
+	}
+	return i
+}
+
+func (s Set[T]) Difference(t Set[T]) Set[T] {
+	d := make(Set[T])
+	for v := range s {
+		if !t.Has(v) {
+			d.Add(v)
+		}
+	}
+	return d
+}
+
+func (s Set[T]) SymmetricDifference(t Set[T]) Set[T] {
+	d := s.Difference(t)
+	for v := range t {
+		if !s.Has(v) {
+			d.Add(v)
+		}
+	}
+	return d
+}
+
+/*
+	IsSubset reports whether every element of s is in t. Every set is a subset of itself, and the empty set is a subset of every set.
+	IsSubset 은 s 의 모든 원소가 t 에 있는지 알려준다. 모든 set 은 자기 자신의 부분집합이고, 공집합은 모든 set 의 부분집합이다.
+*/
+func (s Set[T]) IsSubset(t Set[T]) bool {
+	if len(s) > len(t) {
+		return false
+	}
+	for v := range s {
+		if !t.Has(v) {
+			return false
+		}
+	}
+	return true
+}
+
+func (s Set[T]) IsSuperset(t Set[T]) bool {
+	return t.IsSubset(s)
+}
+
+func (s Set[T]) Equal(t Set[T]) bool {
+	return len(s) == len(t) && s.IsSubset(t)
+}
+
+/*
+	SortedFunc yields the elements ordered by compare, which returns a negative number, zero or a positive number like strings.Compare.
+	Sorted does the same for element types that have a natural order.
+	SortedFunc 는 strings.Compare 처럼 음수, 0, 또는 양수를 반환하는 compare 로 정렬된 원소들을 내보낸다.
+	Sorted 는 자연스러운 순서가 있는 원소 타입에 대해 같은 일을 한다.
+*/
+func (s Set[T]) SortedFunc(compare func(a, b T) int) iter.Seq[T] {
+	return slices.Values(slices.SortedFunc(maps.Keys(s), compare))
+}
+
+func Sorted[T cmp.Ordered](s Set[T]) iter.Seq[T] {
+	return slices.Values(slices.Sorted(maps.Keys(s)))
+}
+
+/*
+	String prints the elements ordered by their printed form, so any Set prints the same way every time.
+	String 은 출력된 형태로 정렬된 원소들을 출력하므로, 어떤 Set 이든 매번 같은 방식으로 출력된다.
+*/
+func (s Set[T]) String() string {
+	vs := make([]string, 0, len(s))
+	for v := range s {
+		vs = append(vs, fmt.Sprint(v))
+	}
+	slices.Sort(vs)
+	return "{" + strings.Join(vs, " ") + "}"
+}
+
+/*
+	SyncSet is a Set that many goroutines can use at once, guarded by a sync.RWMutex the way mutexes guards its map.
+	Add returns whether the element was new, so "have I seen this before?" is one atomic step instead of a Has followed by an Add that can race.
+	The set algebra works on a Snapshot, which is a copy, so a long Union never holds the lock.
+	SyncSet 은 많은 goroutine 들이 동시에 사용할 수 있는 Set 으로, mutexes 가 그것의 map 을 보호하는 방식처럼 sync.RWMutex 로 보호된다.
+	Add 는 원소가 새로운 것이었는지 반환하므로, "이것을 전에 본 적이 있는가?" 는 경쟁할 수 있는 Has 와 그 뒤의 Add 대신 하나의 원자적 단계이다.
+	set 연산은 복사본인 Snapshot 위에서 동작하므로, 긴 Union 이 절대 잠금을 잡고 있지 않는다.
+*/
+type SyncSet[T comparable] struct {
+	mu  sync.RWMutex
+	set Set[T]
+}
+
+func NewSyncSet[T comparable](vs ...T) *SyncSet[T] {
+	return &SyncSet[T]{set: NewSet(vs...)}
+}
+
+func (s *SyncSet[T]) Add(v T) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.set.Has(v) {
+		return false
+	}
+	s.set.Add(v)
+	return true
+}
+
+func (s *SyncSet[T]) Remove(v T) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.set.Has(v) {
+		return false
+	}
+	s.set.Remove(v)
+	return true
+}
+
+func (s *SyncSet[T]) Has(v T) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.set.Has(v)
+}
+
+func (s *SyncSet[T]) Len() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.set.Len()
+}
+
+func (s *SyncSet[T]) Snapshot() Set[T] {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.set.Clone()
+}
+
+/*
+	setExample compares two sets of fruit, then has several goroutines report the words they find and counts each word once.
+	setExample 은 과일의 두 set 을 비교하고, 그 다음 여러 goroutine 이 찾은 단어들을 알리게 하고 각 단어를 한 번씩 센다.
+*/
+func setExample(out io.Writer) {
+	mine := NewSet("peach", "apple", "pear", "plum")
+	yours := NewSet("pear", "fig", "apple")
+	fmt.Fprintln(out, "union:", mine.Union(yours))
+	fmt.Fprintln(out, "intersection:", mine.Intersection(yours))
+	fmt.Fprintln(out, "difference:", mine.Difference(yours))
+	fmt.Fprintln(out, "symmetric difference:", mine.SymmetricDifference(yours))
+	fmt.Fprintln(out, "subset:", NewSet("pear", "apple").IsSubset(mine), mine.IsSuperset(yours))
+	fmt.Fprintln(out, slices.Collect(mine.SortedFunc(func(a, b string) int {
+		return cmp.Or(cmp.Compare(len(a), len(b)), strings.Compare(a, b))
+	})))
+
+	lines := []string{"the quick brown fox", "jumps over the lazy dog", "the dog sleeps", "a quick fox"}
+	seen := NewSyncSet[string]()
+	var (
+		wg       sync.WaitGroup
+		mu       sync.Mutex
+		distinct int
+	)
+	for _, line := range lines {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for _, w := range strings.Fields(line) {
+				if seen.Add(w) {
+					mu.Lock()
+					distinct++
+					mu.Unlock()
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	fmt.Fprintln(out, distinct, "distinct words:", slices.Collect(Sorted(seen.Snapshot())))
+}
@@ -0,0 +1,131 @@
+package main
+
+import (
+	"reflect"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+/*
+	TestSet runs a table of cases for Set over ints, strings and points, like TestCollections does for the slice functions.
+	TestSet 은 TestCollections 가 슬라이스 함수들에 대해 하는 것처럼, int, 문자열, 그리고 point 위에서 Set 의 사례 표를 실행한다.
+*/
+func TestSet(t *testing.T) {
+	ints := []int{3, 1, 4, 1, 5, 9, 2, 6}
+	strs := []string{"peach", "apple", "pear", "plum"}
+	points := []point{{1, 2}, {3, 0}, {-1, 5}, {3, 0}}
+
+	for _, tc := range []struct {
+		name      string
+		got, want any
+	}{
+		{"Set ints", slices.Collect(Sorted(NewSet(ints...))), []int{1, 2, 3, 4, 5, 6, 9}},
+		{"Set Has", pair(NewSet(strs...).Has("pear"), NewSet(strs...).Has("fig")), Pair[bool, bool]{true, false}},
+		{"Set points", NewSet(points...).Len(), 3},
+		{"Set nil", Set[int](nil).Has(1), false},
+
+		{"Remove", func() Set[int] {
+			s := NewSet(ints...)
+			s.Remove(1, 9, 7)
+			return s
+		}(), NewSet(2, 3, 4, 5, 6)},
+		{"Remove everything", func() Set[string] {
+			s := NewSet(strs...)
+			s.Remove(strs...)
+			return s
+		}(), NewSet[string]()},
+		{"Remove from nil", func() Set[int] {
+			var s Set[int]
+			s.Remove(1)
+			return s
+		}(), Set[int](nil)},
+
+		{"Clone", NewSet(points...).Clone(), NewSet(point{1, 2}, point{3, 0}, point{-1, 5})},
+		{"Clone is a copy", func() Set[int] {
+			s := NewSet(1, 2)
+			c := s.Clone()
+			c.Add(3)
+			s.Remove(1)
+			return c
+		}(), NewSet(1, 2, 3)},
+		{"Clone nil", Set[int](nil).Clone(), Set[int](nil)},
+
+		{"Union", NewSet(1, 2).Union(NewSet(2, 3)), NewSet(1, 2, 3)},
+		{"Union empty", NewSet[string]().Union(NewSet("a")), NewSet("a")},
+		{"Intersection", NewSet(1, 2, 3).Intersection(NewSet(2, 3, 4)), NewSet(2, 3)},
+		{"Intersection disjoint", NewSet("a").Intersection(NewSet("b")), NewSet[string]()},
+		{"Difference", NewSet(1, 2, 3).Difference(NewSet(2, 4)), NewSet(1, 3)},
+		{"SymmetricDifference", NewSet(1, 2, 3).SymmetricDifference(NewSet(2, 4)), NewSet(1, 3, 4)},
+		{"IsSubset", pair(NewSet(1, 2).IsSubset(NewSet(1, 2, 3)), NewSet(1, 4).IsSubset(NewSet(1, 2, 3))), Pair[bool, bool]{true, false}},
+		{"IsSubset empty and self", pair(NewSet[int]().IsSubset(NewSet(1)), NewSet(1).IsSubset(NewSet(1))), Pair[bool, bool]{true, true}},
+		{"IsSuperset", NewSet(points...).IsSuperset(NewSet(point{3, 0})), true},
+		{"Equal", pair(NewSet(1, 2).Equal(NewSet(2, 1)), NewSet(1).Equal(NewSet(1, 2))), Pair[bool, bool]{true, false}},
+		{"SortedFunc points", slices.Collect(NewSet(points...).SortedFunc(func(a, b point) int { return a.Y - b.Y })), []point{{3, 0}, {1, 2}, {-1, 5}}},
+		{"Set String", NewSet("b", "a").String(), "{a b}"},
+		{"Set operands unchanged", func() Set[int] {
+			s := NewSet(1, 2)
+			s.Union(NewSet(3))
+			s.Difference(NewSet(1))
+			return s
+		}(), NewSet(1, 2)},
+	} {
+		if !reflect.DeepEqual(tc.got, tc.want) {
+			t.Errorf("%s: got %v, want %v", tc.name, tc.got, tc.want)
+		}
+	}
+}
+
+/*
+	TestSyncSet has many goroutines Add and then Remove the same elements at once; run it with -race.
+	Add and Remove each report true exactly once per element, however the goroutines interleave,
+	and a Snapshot taken in between is a copy that later changes do not reach.
+	TestSyncSet 은 많은 goroutine 이 같은 원소들을 동시에 Add 한 뒤 Remove 하게 한다; -race 와 함께 실행하라.
+	goroutine 들이 어떻게 끼어들든 Add 와 Remove 는 각각 원소마다 정확히 한 번 true 를 알려주며,
+	그 사이에 찍은 Snapshot 은 이후의 변경이 닿지 않는 복사본이다.
+*/
+func TestSyncSet(t *testing.T) {
+	const goroutines, elements = 8, 100
+	s := NewSyncSet[int]()
+	run := func(op func(v int) bool) []int64 {
+		counts := make([]atomic.Int64, elements)
+		var wg sync.WaitGroup
+		for range goroutines {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for v := range elements {
+					if op(v) {
+						counts[v].Add(1)
+					}
+				}
+			}()
+		}
+		wg.Wait()
+		got := make([]int64, elements)
+		for v := range counts {
+			got[v] = counts[v].Load()
+		}
+		return got
+	}
+	once := slices.Repeat([]int64{1}, elements)
+
+	if added := run(s.Add); !slices.Equal(added, once) {
+		t.Errorf("Add returned true %v times, want once per element", added)
+	}
+	if n := s.Len(); n != elements {
+		t.Errorf("Len after Add = %d, want %d", n, elements)
+	}
+	snap := s.Snapshot()
+
+	if removed := run(s.Remove); !slices.Equal(removed, once) {
+		t.Errorf("Remove returned true %v times, want once per element", removed)
+	}
+	if n := s.Len(); n != 0 || s.Has(0) {
+		t.Errorf("Len after Remove = %d, want 0", n)
+	}
+	if n := snap.Len(); n != elements || !snap.Has(elements-1) {
+		t.Errorf("snapshot has %d elements after Remove, want %d", n, elements)
+	}
+}
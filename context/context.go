@@ -1 +1,3 @@
-package main 
+package main
+
+func main() {}
@@ -0,0 +1,3 @@
+module gobyexample
+
+go 1.24
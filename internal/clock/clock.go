@@ -0,0 +1,283 @@
+/*
+	Package clock puts time.NewTimer, time.NewTicker, time.After, time.Tick and time.Sleep behind an interface,
+	so code that waits can be checked without really waiting: Real passes the calls straight to the time package,
+	and Fake only moves when Advance is called, firing its timers and tickers in order.
+	clock 패키지는 time.NewTimer, time.NewTicker, time.After, time.Tick 그리고 time.Sleep 을 인터페이스 뒤에 두어서,
+	기다리는 코드를 실제로 기다리지 않고 확인할 수 있게 한다: Real 은 그 호출들을 time 패키지로 바로 넘기고,
+	Fake 는 Advance 가 호출될 때만 움직이며 자신의 timer 와 ticker 들을 순서대로 발사한다.
+*/
+package clock
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+type Clock interface {
+	Now() time.Time
+	Since(t time.Time) time.Duration
+	Until(t time.Time) time.Duration
+	Sleep(d time.Duration)
+	After(d time.Duration) <-chan time.Time
+	Tick(d time.Duration) <-chan time.Time
+	NewTimer(d time.Duration) Timer
+	NewTicker(d time.Duration) Ticker
+}
+
+type Timer interface {
+	C() <-chan time.Time
+	Stop() bool
+	Reset(d time.Duration) bool
+}
+
+type Ticker interface {
+	C() <-chan time.Time
+	Stop()
+	Reset(d time.Duration)
+}
+
+/*
+	Real is the time package itself.
+	Real 은 time 패키지 그 자체이다.
+*/
+type Real struct{}
+
+func (Real) Now() time.Time                         { return time.Now() }
+func (Real) Since(t time.Time) time.Duration        { return time.Since(t) }
+func (Real) Until(t time.Time) time.Duration        { return time.Until(t) }
+func (Real) Sleep(d time.Duration)                  { time.Sleep(d) }
+func (Real) After(d time.Duration) <-chan time.Time { return time.After(d) }
+func (Real) Tick(d time.Duration) <-chan time.Time  { return time.Tick(d) }
+func (Real) NewTimer(d time.Duration) Timer         { return realTimer{time.NewTimer(d)} }
+func (Real) NewTicker(d time.Duration) Ticker       { return realTicker{time.NewTicker(d)} }
+
+type realTimer struct{ *time.Timer }
+
+func (t realTimer) C() <-chan time.Time { return t.Timer.C }
+
+type realTicker struct{ *time.Ticker }
+
+func (t realTicker) C() <-chan time.Time { return t.Ticker.C }
+
+/*
+	Fake starts at a fixed time and only moves forward when Advance is called.
+	Its timers behave like the runtime's: each has a channel with room for one value, a fire never blocks,
+	and a ticker whose reader is behind drops ticks, so a timer that nobody reads or stops, like the losing branch of a select, costs nothing.
+	Stop and Reset also take back a value that was sent but not received yet, as they do on a real timer since Go 1.23.
+	Because Advance does not wait for anyone to receive, a check synchronises with BlockUntil and with the code's own output, not with Advance returning.
+	Fake 는 고정된 시간에서 시작하고 Advance 가 호출될 때만 앞으로 움직인다.
+	그것의 timer 들은 runtime 의 것처럼 동작한다: 각각은 값 하나를 위한 자리가 있는 채널을 가지고, 발사는 절대 막히지 않으며,
+	읽는 쪽이 뒤쳐진 ticker 는 tick 을 버리므로, select 의 지는 쪽처럼 아무도 읽거나 멈추지 않는 timer 는 아무 비용도 들지 않는다.
+	Stop 과 Reset 은 Go 1.23 부터 실제 timer 에서 그런 것처럼, 보내졌지만 아직 수신되지 않은 값도 되돌린다.
+	Advance 는 누군가 수신하기를 기다리지 않기 때문에, 확인하는 쪽은 Advance 의 반환이 아니라 BlockUntil 과 코드 자신의 출력으로 동기화한다.
+*/
+type Fake struct {
+	mu      sync.Mutex
+	now     time.Time
+	timers  []*fakeTimer
+	changed chan struct{}
+}
+
+func NewFake(start time.Time) *Fake {
+	return &Fake{now: start, changed: make(chan struct{})}
+}
+
+type fakeTimer struct {
+	clock   *Fake
+	c       chan time.Time
+	when    time.Time
+	period  time.Duration
+	sleeper bool
+	active  bool
+}
+
+func (f *Fake) Now() time.Time {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.now
+}
+
+func (f *Fake) Since(t time.Time) time.Duration { return f.Now().Sub(t) }
+func (f *Fake) Until(t time.Time) time.Duration { return t.Sub(f.Now()) }
+
+func (f *Fake) Sleep(d time.Duration) {
+	if d <= 0 {
+		return
+	}
+	t := f.newTimer(d, 0, true)
+	<-t.c
+}
+
+func (f *Fake) After(d time.Duration) <-chan time.Time { return f.NewTimer(d).C() }
+func (f *Fake) Tick(d time.Duration) <-chan time.Time  { return f.NewTicker(d).C() }
+func (f *Fake) NewTimer(d time.Duration) Timer         { return f.newTimer(d, 0, false) }
+
+func (f *Fake) NewTicker(d time.Duration) Ticker {
+	if d <= 0 {
+		panic("non-positive interval for NewTicker")
+	}
+	return fakeTicker{f.newTimer(d, d, false)}
+}
+
+func (f *Fake) newTimer(d, period time.Duration, sleeper bool) *fakeTimer {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	t := &fakeTimer{
+		clock:   f,
+		c:       make(chan time.Time, 1),
+		period:  period,
+		sleeper: sleeper,
+	}
+	f.schedule(t, d)
+	return t
+}
+
+func (f *Fake) schedule(t *fakeTimer, d time.Duration) {
+	t.when = f.now.Add(d)
+	if !t.active {
+		t.active = true
+		f.timers = append(f.timers, t)
+	}
+	f.notify()
+}
+
+func (f *Fake) unschedule(t *fakeTimer) {
+	if !t.active {
+		return
+	}
+	t.active = false
+	for i, other := range f.timers {
+		if other == t {
+			f.timers = append(f.timers[:i], f.timers[i+1:]...)
+			break
+		}
+	}
+	f.notify()
+}
+
+/*
+	notify wakes up everyone in BlockUntil so they can count the timers again.
+	notify 는 BlockUntil 에 있는 모두를 깨워서 timer 들을 다시 셀 수 있게 한다.
+*/
+func (f *Fake) notify() {
+	close(f.changed)
+	f.changed = make(chan struct{})
+}
+
+/*
+	Advance moves the clock forward by d, firing every timer and ticker that falls due on the way in deadline order.
+	A fire whose channel still holds the previous value is dropped, as the runtime drops the ticks of a slow reader.
+	Advance 는 시계를 d 만큼 앞으로 움직이고, 그 사이에 기한이 되는 모든 timer 와 ticker 를 기한 순서대로 발사한다.
+	채널이 아직 이전 값을 가지고 있는 발사는, runtime 이 느린 읽는 쪽의 tick 을 버리는 것처럼, 버려진다.
+*/
+func (f *Fake) Advance(d time.Duration) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	end := f.now.Add(d)
+	for {
+		sort.SliceStable(f.timers, func(i, j int) bool {
+			return f.timers[i].when.Before(f.timers[j].when)
+		})
+		if len(f.timers) == 0 || f.timers[0].when.After(end) {
+			break
+		}
+		t := f.timers[0]
+		f.now = t.when
+		if t.period > 0 {
+			t.when = t.when.Add(t.period)
+		} else {
+			f.unschedule(t)
+		}
+		select {
+		case t.c <- f.now:
+		default:
+		}
+	}
+	f.now = end
+}
+
+/*
+	BlockUntil waits until at least n timers, tickers and sleepers are waiting on the clock.
+	Call it before Advance so the goroutines under test have caught up with the clock.
+	BlockUntil 은 최소 n 개의 timer, ticker 그리고 sleeper 가 시계를 기다릴 때까지 기다린다.
+	테스트 중인 고루틴들이 시계를 따라잡도록 Advance 전에 호출하자.
+*/
+func (f *Fake) BlockUntil(n int) {
+	f.blockUntil(n, func(*fakeTimer) bool { return true })
+}
+
+/*
+	BlockUntilSleeping is BlockUntil that only counts goroutines blocked in Sleep.
+	BlockUntilSleeping 은 Sleep 에서 막힌 고루틴만 세는 BlockUntil 이다.
+*/
+func (f *Fake) BlockUntilSleeping(n int) {
+	f.blockUntil(n, func(t *fakeTimer) bool { return t.sleeper })
+}
+
+func (f *Fake) blockUntil(n int, counts func(*fakeTimer) bool) {
+	for {
+		f.mu.Lock()
+		waiting := 0
+		for _, t := range f.timers {
+			if counts(t) {
+				waiting++
+			}
+		}
+		changed := f.changed
+		f.mu.Unlock()
+		if waiting >= n {
+			return
+		}
+		<-changed
+	}
+}
+
+func (t *fakeTimer) C() <-chan time.Time { return t.c }
+
+/*
+	Stop reports true if the timer had not delivered its value yet. A value that was sent but not received is taken back.
+	Stop 은 timer 가 아직 값을 전달하지 않았다면 true 를 알려준다. 보내졌지만 수신되지 않은 값은 되돌려진다.
+*/
+func (t *fakeTimer) Stop() bool {
+	f := t.clock
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return t.stop()
+}
+
+func (t *fakeTimer) stop() bool {
+	wasWaiting := t.active
+	t.clock.unschedule(t)
+	select {
+	case <-t.c:
+		wasWaiting = true
+	default:
+	}
+	return wasWaiting
+}
+
+func (t *fakeTimer) Reset(d time.Duration) bool {
+	f := t.clock
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	wasWaiting := t.stop()
+	f.schedule(t, d)
+	return wasWaiting
+}
+
+type fakeTicker struct{ *fakeTimer }
+
+func (t fakeTicker) Stop() { t.fakeTimer.Stop() }
+
+func (t fakeTicker) Reset(d time.Duration) {
+	if d <= 0 {
+		panic("non-positive interval for Ticker.Reset")
+	}
+	f := t.clock
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	t.stop()
+	t.period = d
+	f.schedule(t.fakeTimer, d)
+}
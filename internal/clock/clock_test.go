@@ -0,0 +1,114 @@
+package clock
+
+import (
+	"testing"
+	"time"
+)
+
+var start = time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+
+/*
+	received returns what is waiting on c without blocking.
+	received 는 c 에서 기다리고 있는 것을 막히지 않고 반환한다.
+*/
+func received(c <-chan time.Time) []time.Time {
+	var got []time.Time
+	for {
+		select {
+		case v := <-c:
+			got = append(got, v)
+		default:
+			return got
+		}
+	}
+}
+
+func TestFake(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		run  func(f *Fake) []time.Time
+		want []time.Time
+	}{
+		{"timer fires at its deadline", func(f *Fake) []time.Time {
+			timer := f.NewTimer(time.Second)
+			f.Advance(999 * time.Millisecond)
+			early := received(timer.C())
+			f.Advance(time.Millisecond)
+			return append(early, received(timer.C())...)
+		}, []time.Time{start.Add(time.Second)}},
+
+		{"unread timer does not block Advance", func(f *Fake) []time.Time {
+			f.After(time.Second)
+			f.Advance(time.Hour)
+			return []time.Time{f.Now()}
+		}, []time.Time{start.Add(time.Hour)}},
+
+		{"slow ticker reader drops ticks", func(f *Fake) []time.Time {
+			ticker := f.NewTicker(time.Second)
+			f.Advance(3 * time.Second)
+			return received(ticker.C())
+		}, []time.Time{start.Add(time.Second)}},
+
+		{"ticker read every tick", func(f *Fake) []time.Time {
+			ticker := f.NewTicker(time.Second)
+			var got []time.Time
+			for i := 0; i < 3; i++ {
+				f.Advance(time.Second)
+				got = append(got, received(ticker.C())...)
+			}
+			return got
+		}, []time.Time{start.Add(time.Second), start.Add(2 * time.Second), start.Add(3 * time.Second)}},
+
+		{"stop takes back an unread value", func(f *Fake) []time.Time {
+			timer := f.NewTimer(time.Second)
+			f.Advance(time.Second)
+			if !timer.Stop() {
+				return []time.Time{{}}
+			}
+			return received(timer.C())
+		}, nil},
+
+		{"reset moves the deadline", func(f *Fake) []time.Time {
+			timer := f.NewTimer(time.Second)
+			timer.Reset(3 * time.Second)
+			f.Advance(2 * time.Second)
+			early := received(timer.C())
+			f.Advance(time.Second)
+			return append(early, received(timer.C())...)
+		}, []time.Time{start.Add(3 * time.Second)}},
+
+		{"ticker reset changes the period", func(f *Fake) []time.Time {
+			ticker := f.NewTicker(time.Second)
+			ticker.Reset(2 * time.Second)
+			var got []time.Time
+			for i := 0; i < 4; i++ {
+				f.Advance(time.Second)
+				got = append(got, received(ticker.C())...)
+			}
+			return got
+		}, []time.Time{start.Add(2 * time.Second), start.Add(4 * time.Second)}},
+
+		{"sleep wakes when the clock passes it", func(f *Fake) []time.Time {
+			woke := make(chan time.Time)
+			go func() {
+				f.Sleep(time.Second)
+				woke <- f.Now()
+			}()
+			f.BlockUntilSleeping(1)
+			f.Advance(2 * time.Second)
+			return []time.Time{<-woke}
+		}, []time.Time{start.Add(2 * time.Second)}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			got := tc.run(NewFake(start))
+			if len(got) != len(tc.want) {
+				t.Fatalf("got %v, want %v", got, tc.want)
+			}
+			for i := range got {
+				if !got[i].Equal(tc.want[i]) {
+					t.Fatalf("got %v, want %v", got, tc.want)
+				}
+			}
+		})
+	}
+}
@@ -3,6 +3,7 @@ package main
 import (
 	"fmt"
 	"math/rand"
+	"os"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -106,4 +107,31 @@ func main() {
 		Running the program shows that we executed about 90,000 total operations against our mutex-synchronized state.
 		이 프로그램을 실행하면 우리가 mutex와 동기화된 상태에 대해 약 9만 건의 총 작업을 실행했다는 것을 알 수 있다.
 	*/
+
+	/*
+		Now the same harness against a ShardedMap, growing the shard count to see how throughput changes as the single lock is split up.
+		이제 같은 장치를 ShardedMap 에 대해 실행하며, 하나의 lock 이 쪼개질수록 처리량이 어떻게 바뀌는지 보기 위해 shard 의 개수를 늘려간다.
+	*/
+	for _, shards := range []int{1, 4, 16, 64} {
+		reads, writes := shardedOps(shards, 500*time.Millisecond)
+		fmt.Printf("shards: %2d readOps: %9d writeOps: %8d\n", shards, reads, writes)
+	}
+
+	/*
+		Once there is more than one lock, the order they are taken in matters too.
+		lock 이 하나보다 많아지면, 그것들을 가져가는 순서도 중요해진다.
+	*/
+	lockOrderExample()
+
+	/*
+		Finally the harness once more with a ProfiledMutex, whose report shows where the time goes: waiting for the lock, holding it, or neither.
+		마지막으로 ProfiledMutex 로 장치를 한번 더 실행하는데, 그 보고서는 시간이 어디로 가는지 보여준다: lock 을 기다리는 것, 가지고 있는 것, 또는 둘 다 아닌 것.
+	*/
+	profiledOps(500*time.Millisecond, os.Stdout)
+
+	/*
+		A map behind a mutex can also forget keys on its own: a TTLMap used as a session cache.
+		mutex 뒤의 map 은 스스로 key 를 잊을 수도 있다: 세션 캐시로 사용되는 TTLMap.
+	*/
+	sessionCacheExample(os.Stdout)
 }
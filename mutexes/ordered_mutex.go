@@ -0,0 +1,64 @@
+package main
+
+import (
+	"fmt"
+	"sync"
+)
+
+/*
+	With one mutex, as in main, there is no order to get wrong.
+	As soon as code holds one lock while taking another, two goroutines taking them in opposite orders can deadlock each other.
+	OrderedMutex catches that before it happens: build or run with `-tags lockorder` and it reports every lock order cycle with the stacks of both acquisitions.
+	main 에서처럼 mutex 가 하나이면 틀릴 순서가 없다.
+	코드가 하나의 lock 을 가진 채로 다른 lock 을 가져가는 순간, 반대 순서로 그것들을 가져가는 두 고루틴이 서로를 deadlock 시킬 수 있다.
+	OrderedMutex 는 그런 일이 일어나기 전에 잡아낸다: `-tags lockorder` 로 빌드하거나 실행하면 모든 lock 순서 사이클을 두 획득의 스택과 함께 보고한다.
+*/
+
+/*
+	Both builds of OrderedMutex have the method set of a sync.Mutex, so code written against one compiles against the other.
+	OrderedMutex 의 두 빌드 모두 sync.Mutex 의 메서드 집합을 가지므로, 한쪽에 대해 작성된 코드는 다른 쪽에 대해서도 컴파일된다.
+*/
+var _ interface {
+	sync.Locker
+	TryLock() bool
+} = (*OrderedMutex)(nil)
+
+/*
+	lockOrderExample moves money between two accounts, each guarded by its own lock.
+	transfer locks the source account first, so transfers in both directions take the locks in opposite orders.
+	The two transfers here run one after the other and never actually deadlock, but the detector still sees the cycle.
+	lockOrderExample 은 각자 자신의 lock 으로 보호되는 두 계좌 사이에서 돈을 옮긴다.
+	transfer 는 보내는 계좌를 먼저 잠그기 때문에, 양방향의 이체는 반대 순서로 lock 을 가져간다.
+	여기서 두 이체는 차례로 실행되어서 실제로 deadlock 이 나지는 않지만, detector 는 여전히 사이클을 본다.
+*/
+func lockOrderExample() {
+	type account struct {
+		mu      *OrderedMutex
+		balance int
+	}
+	a := &account{mu: NewOrderedMutex("account a"), balance: 100}
+	b := &account{mu: NewOrderedMutex("account b"), balance: 100}
+
+	transfer := func(from, to *account, amount int) {
+		from.mu.Lock()
+		defer from.mu.Unlock()
+		to.mu.Lock()
+		defer to.mu.Unlock()
+		from.balance -= amount
+		to.balance += amount
+	}
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		transfer(a, b, 10)
+	}()
+	wg.Wait()
+	transfer(b, a, 5)
+
+	fmt.Println("balances: ", a.balance, b.balance)
+	if !lockOrderChecking {
+		fmt.Println("run with -tags lockorder to check the lock order")
+	}
+}
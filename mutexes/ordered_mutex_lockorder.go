@@ -0,0 +1,213 @@
+//go:build lockorder
+
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+const lockOrderChecking = true
+
+/*
+	With the lockorder build tag every OrderedMutex reports to one detector.
+	It remembers which locks each goroutine holds, and every time a goroutine takes lock B while holding lock A it records the edge A -> B, with the stack of both acquisitions.
+	If B can already reach A through the recorded edges, some goroutine once took them in the opposite order: the two orders together can deadlock, even if this run got lucky.
+	lockorder 빌드 태그가 있으면 모든 OrderedMutex 는 하나의 detector 에게 보고한다.
+	detector 는 각 고루틴이 어떤 lock 들을 가지고 있는지 기억하고, 고루틴이 lock A 를 가진 채로 lock B 를 가져갈 때마다 두 획득의 스택과 함께 A -> B 간선을 기록한다.
+	만약 기록된 간선들을 통해 B 가 이미 A 에 도달할 수 있다면 어떤 고루틴이 한번은 반대 순서로 가져갔다는 뜻이다: 이번 실행이 운이 좋았더라도 두 순서가 함께 있으면 deadlock 이 날 수 있다.
+*/
+type OrderedMutex struct {
+	mu   sync.Mutex
+	name string
+}
+
+func NewOrderedMutex(name string) *OrderedMutex {
+	return &OrderedMutex{name: name}
+}
+
+/*
+	LockOrderViolation describes a cycle in the lock order graph.
+	Edges lists the cycle from the lock being taken back to itself, each edge with the stacks that first recorded it.
+	LockOrderViolation 은 lock 순서 그래프 안의 사이클을 설명한다.
+	Edges 는 가져가려는 lock 에서 자기 자신으로 돌아오는 사이클을 나열하며, 각 간선은 그것을 처음 기록한 스택들을 가진다.
+*/
+type LockOrderViolation struct {
+	Edges []LockOrderEdge
+}
+
+type LockOrderEdge struct {
+	From, To           string
+	FromStack, ToStack string
+}
+
+func (v *LockOrderViolation) Error() string {
+	var b strings.Builder
+	names := []string{v.Edges[0].From}
+	for _, e := range v.Edges {
+		names = append(names, e.To)
+	}
+	fmt.Fprintf(&b, "lock order cycle: %s\n", strings.Join(names, " -> "))
+	for _, e := range v.Edges {
+		fmt.Fprintf(&b, "\n%s acquired at:\n%s\n%s then acquired at:\n%s\n", e.From, e.FromStack, e.To, e.ToStack)
+	}
+	return b.String()
+}
+
+/*
+	OnLockOrderViolation is called for every new cycle. By default it prints to stderr; tests can replace it to fail instead.
+	OnLockOrderViolation 은 새로운 사이클마다 호출된다. 기본으로 stderr 에 출력한다; 테스트는 대신 실패하도록 그것을 교체할 수 있다.
+*/
+var OnLockOrderViolation = func(v *LockOrderViolation) {
+	fmt.Fprintln(os.Stderr, v.Error())
+}
+
+type heldLock struct {
+	m     *OrderedMutex
+	stack string
+}
+
+var detector = struct {
+	mu    sync.Mutex
+	held  map[int64][]heldLock
+	edges map[*OrderedMutex]map[*OrderedMutex]LockOrderEdge
+}{
+	held:  make(map[int64][]heldLock),
+	edges: make(map[*OrderedMutex]map[*OrderedMutex]LockOrderEdge),
+}
+
+func (m *OrderedMutex) Lock() {
+	gid, stack := goroutineID(), callerStack()
+
+	detector.mu.Lock()
+	var violations []*LockOrderViolation
+	for _, h := range detector.held[gid] {
+		/*
+			Taking a lock the goroutine already holds is the shortest cycle of all, m -> m, and it deadlocks every time.
+			고루틴이 이미 가진 lock 을 가져가는 것은 가장 짧은 사이클 m -> m 이며, 매번 deadlock 이 난다.
+		*/
+		if h.m == m {
+			edge := LockOrderEdge{From: m.name, To: m.name, FromStack: h.stack, ToStack: stack}
+			violations = append(violations, &LockOrderViolation{Edges: []LockOrderEdge{edge}})
+			continue
+		}
+		if _, seen := detector.edges[h.m][m]; seen {
+			continue
+		}
+		if path := lockPath(m, h.m); path != nil {
+			edge := LockOrderEdge{From: h.m.name, To: m.name, FromStack: h.stack, ToStack: stack}
+			violations = append(violations, &LockOrderViolation{Edges: append([]LockOrderEdge{edge}, path...)})
+		}
+		if detector.edges[h.m] == nil {
+			detector.edges[h.m] = make(map[*OrderedMutex]LockOrderEdge)
+		}
+		detector.edges[h.m][m] = LockOrderEdge{From: h.m.name, To: m.name, FromStack: h.stack, ToStack: stack}
+	}
+	detector.mu.Unlock()
+
+	/*
+		Report before blocking on the real lock, so the cycle is seen even when this Lock is the one that deadlocks.
+		실제 lock 에서 막히기 전에 보고해서, 이 Lock 이 deadlock 이 나는 바로 그것이더라도 사이클이 보이게 한다.
+	*/
+	for _, v := range violations {
+		OnLockOrderViolation(v)
+	}
+
+	m.mu.Lock()
+
+	detector.mu.Lock()
+	detector.held[gid] = append(detector.held[gid], heldLock{m: m, stack: stack})
+	detector.mu.Unlock()
+}
+
+/*
+	TryLock never blocks, so taking locks with it cannot deadlock and records no edges.
+	A lock it does take still counts as held, so locks taken after it are ordered against it.
+	TryLock 은 절대 막히지 않으므로 그것으로 lock 을 가져가는 것은 deadlock 이 날 수 없고 간선도 기록하지 않는다.
+	그래도 가져간 lock 은 가진 것으로 세어지므로, 그 뒤에 가져가는 lock 들은 그것에 대해 순서가 매겨진다.
+*/
+func (m *OrderedMutex) TryLock() bool {
+	if !m.mu.TryLock() {
+		return false
+	}
+	gid, stack := goroutineID(), callerStack()
+	detector.mu.Lock()
+	detector.held[gid] = append(detector.held[gid], heldLock{m: m, stack: stack})
+	detector.mu.Unlock()
+	return true
+}
+
+/*
+	Unlock forgets the lock for whichever goroutine holds it; like sync.Mutex it may be unlocked from another goroutine.
+	Unlock 은 그 lock 을 가진 고루틴이 어떤 것이든 그 lock 을 잊는다; sync.Mutex 처럼 다른 고루틴에서 unlock 될 수 있다.
+*/
+func (m *OrderedMutex) Unlock() {
+	detector.mu.Lock()
+	for gid, held := range detector.held {
+		for i := len(held) - 1; i >= 0; i-- {
+			if held[i].m == m {
+				held = append(held[:i], held[i+1:]...)
+				if len(held) == 0 {
+					delete(detector.held, gid)
+				} else {
+					detector.held[gid] = held
+				}
+				break
+			}
+		}
+	}
+	detector.mu.Unlock()
+	m.mu.Unlock()
+}
+
+/*
+	lockPath returns the recorded edges leading from one lock to another, or nil if there is no such path.
+	lockPath 는 한 lock 에서 다른 lock 으로 이어지는 기록된 간선들을 반환하거나, 그런 경로가 없으면 nil 을 반환한다.
+*/
+func lockPath(from, to *OrderedMutex) []LockOrderEdge {
+	visited := map[*OrderedMutex]bool{from: true}
+	var walk func(m *OrderedMutex) []LockOrderEdge
+	walk = func(m *OrderedMutex) []LockOrderEdge {
+		for next, e := range detector.edges[m] {
+			if next == to {
+				return []LockOrderEdge{e}
+			}
+			if visited[next] {
+				continue
+			}
+			visited[next] = true
+			if rest := walk(next); rest != nil {
+				return append([]LockOrderEdge{e}, rest...)
+			}
+		}
+		return nil
+	}
+	return walk(from)
+}
+
+/*
+	The runtime does not hand out goroutine IDs, but the first line of runtime.Stack is "goroutine N [running]:".
+	That is slow, which is fine here since it only runs in lockorder builds.
+	런타임은 고루틴 ID 를 내주지 않지만, runtime.Stack 의 첫 줄은 "goroutine N [running]:" 이다.
+	이것은 느리지만, lockorder 빌드에서만 실행되기 때문에 여기서는 괜찮다.
+*/
+func goroutineID() int64 {
+	var buf [64]byte
+	b := buf[:runtime.Stack(buf[:], false)]
+	b = bytes.TrimPrefix(b, []byte("goroutine "))
+	if i := bytes.IndexByte(b, ' '); i >= 0 {
+		b = b[:i]
+	}
+	id, _ := strconv.ParseInt(string(b), 10, 64)
+	return id
+}
+
+func callerStack() string {
+	buf := make([]byte, 4096)
+	return string(buf[:runtime.Stack(buf, false)])
+}
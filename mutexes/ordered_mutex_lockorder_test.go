@@ -0,0 +1,108 @@
+//go:build lockorder
+
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+/*
+	TestLockOrder replaces OnLockOrderViolation to collect the cycles each scenario reports.
+	Run it with `go test -tags lockorder`; without the tag there is no detector to test.
+	TestLockOrder 는 각 시나리오가 보고하는 사이클들을 모으기 위해 OnLockOrderViolation 을 교체한다.
+	`go test -tags lockorder` 로 실행한다; 태그가 없으면 테스트할 detector 가 없다.
+*/
+func TestLockOrder(t *testing.T) {
+	defer func(f func(*LockOrderViolation)) { OnLockOrderViolation = f }(OnLockOrderViolation)
+
+	for _, tc := range []struct {
+		name string
+		run  func()
+		want []string
+	}{
+		{"same order twice", func() {
+			a, b := NewOrderedMutex("a"), NewOrderedMutex("b")
+			for i := 0; i < 2; i++ {
+				a.Lock()
+				b.Lock()
+				b.Unlock()
+				a.Unlock()
+			}
+		}, nil},
+
+		{"opposite orders", func() {
+			a, b := NewOrderedMutex("a"), NewOrderedMutex("b")
+			a.Lock()
+			b.Lock()
+			b.Unlock()
+			a.Unlock()
+			b.Lock()
+			a.Lock()
+			a.Unlock()
+			b.Unlock()
+		}, []string{"b -> a -> b"}},
+
+		{"try lock records no edge", func() {
+			a, b := NewOrderedMutex("a"), NewOrderedMutex("b")
+			a.Lock()
+			b.TryLock()
+			b.Unlock()
+			a.Unlock()
+			b.Lock()
+			a.Lock()
+			a.Unlock()
+			b.Unlock()
+		}, nil},
+
+		{"try locked lock counts as held", func() {
+			a, b := NewOrderedMutex("a"), NewOrderedMutex("b")
+			b.TryLock()
+			a.Lock()
+			a.Unlock()
+			b.Unlock()
+			a.Lock()
+			b.Lock()
+			b.Unlock()
+			a.Unlock()
+		}, []string{"a -> b -> a"}},
+
+		{"self re-lock", func() {
+			a := NewOrderedMutex("a")
+			a.Lock()
+			/*
+				The second Lock would block forever, so the report unlocks the first one to let it through.
+				두번째 Lock 은 영원히 막힐 것이므로, 보고가 첫번째 것을 unlock 해서 통과시킨다.
+			*/
+			OnLockOrderViolation = func(v *LockOrderViolation) {
+				reported = append(reported, v)
+				a.Unlock()
+			}
+			a.Lock()
+			a.Unlock()
+		}, []string{"a -> a"}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			reported = nil
+			OnLockOrderViolation = func(v *LockOrderViolation) { reported = append(reported, v) }
+			tc.run()
+			var got []string
+			for _, v := range reported {
+				got = append(got, cycle(v))
+			}
+			if strings.Join(got, ", ") != strings.Join(tc.want, ", ") {
+				t.Errorf("reported %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
+
+var reported []*LockOrderViolation
+
+func cycle(v *LockOrderViolation) string {
+	names := []string{v.Edges[0].From}
+	for _, e := range v.Edges {
+		names = append(names, e.To)
+	}
+	return strings.Join(names, " -> ")
+}
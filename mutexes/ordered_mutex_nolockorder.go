@@ -0,0 +1,23 @@
+//go:build !lockorder
+
+package main
+
+import "sync"
+
+const lockOrderChecking = false
+
+/*
+	Without the lockorder build tag an OrderedMutex is just a sync.Mutex with a name, so production builds pay nothing for the checking.
+	lockorder 빌드 태그가 없으면 OrderedMutex 는 그저 이름을 가진 sync.Mutex 라서, 프로덕션 빌드는 검사에 아무 비용도 치르지 않는다.
+*/
+type OrderedMutex struct {
+	mu sync.Mutex
+}
+
+func NewOrderedMutex(name string) *OrderedMutex {
+	return &OrderedMutex{}
+}
+
+func (m *OrderedMutex) Lock()         { m.mu.Lock() }
+func (m *OrderedMutex) Unlock()       { m.mu.Unlock() }
+func (m *OrderedMutex) TryLock() bool { return m.mu.TryLock() }
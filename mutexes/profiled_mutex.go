@@ -0,0 +1,276 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"math/bits"
+	"math/rand"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"text/tabwriter"
+	"time"
+)
+
+/*
+	The readOps and writeOps counters in main say how much work got done, but not where the time went.
+	ProfiledMutex is a sync.Mutex that keeps, for every call site that locks it, how long callers waited for the lock,
+	how long they held it, how many acquisitions had to wait for someone else, and how many held it longer than SlowThreshold.
+	main 의 readOps 와 writeOps 카운터는 얼마나 많은 일이 끝났는지는 말해주지만 시간이 어디로 갔는지는 말해주지 않는다.
+	ProfiledMutex 는 자신을 잠그는 모든 호출 위치마다 호출자가 lock 을 얼마나 기다렸는지, 얼마나 오래 가지고 있었는지,
+	얼마나 많은 획득이 다른 누군가를 기다려야 했는지, 그리고 얼마나 많은 획득이 SlowThreshold 보다 오래 가지고 있었는지를 기록하는 sync.Mutex 이다.
+*/
+type ProfiledMutex struct {
+	mu sync.Mutex
+
+	/*
+		SlowThreshold marks critical sections that run too long; OnSlow, if set, is called for each of them after the lock is released.
+		SlowThreshold 는 너무 오래 실행되는 임계 구역을 표시한다; OnSlow 가 설정되어 있으면 lock 이 해제된 뒤에 각각에 대해 호출된다.
+	*/
+	SlowThreshold time.Duration
+	OnSlow        func(site string, held time.Duration)
+
+	/*
+		These belong to whoever holds mu, so they need no lock of their own.
+		Lock only fills them in; Unlock records them once mu is released, so the critical section pays for two clock reads and nothing else.
+		이것들은 mu 를 가진 쪽의 것이라서 자신만의 lock 이 필요하지 않다.
+		Lock 은 그것들을 채우기만 한다; Unlock 이 mu 가 해제된 뒤에 그것들을 기록하므로, 임계 구역은 시계를 두 번 읽는 것 외에는 비용을 치르지 않는다.
+	*/
+	lockedAt  time.Time
+	lockSite  *siteStats
+	waited    time.Duration
+	contended bool
+
+	/*
+		pcs caches the stats of every return address Lock has been called from, so only the first call from a site resolves its file and line.
+		Two addresses on the same line share one siteStats through sites, which is only touched on that first call.
+		pcs 는 Lock 이 호출된 모든 반환 주소의 통계를 캐시하므로, 한 위치에서의 첫 호출만 그 파일과 줄을 알아낸다.
+		같은 줄의 두 주소는 sites 를 통해 하나의 siteStats 를 공유하며, sites 는 그 첫 호출에서만 건드려진다.
+	*/
+	pcs     sync.Map
+	statsMu sync.Mutex
+	sites   map[string]*siteStats
+}
+
+/*
+	siteStats is only ever updated with atomics, so call sites never wait on each other to record.
+	siteStats 는 atomic 으로만 갱신되므로, 호출 위치들은 기록하기 위해 서로를 기다리지 않는다.
+*/
+type siteStats struct {
+	site      string
+	acquired  atomic.Uint64
+	contended atomic.Uint64
+	slow      atomic.Uint64
+	wait      histogram
+	hold      histogram
+}
+
+/*
+	histogram counts durations in power-of-two buckets of nanoseconds: bucket i holds durations below 2^i ns.
+	That is coarse, but it is cheap to record and good enough to tell microseconds from milliseconds.
+	Reading it while it is being recorded to can see a count that is one ahead of its buckets, which is fine for a report.
+	histogram 은 나노초의 2의 거듭제곱 bucket 으로 시간을 센다: bucket i 는 2^i ns 미만의 시간을 가진다.
+	대략적이지만 기록하는 비용이 싸고 마이크로초와 밀리초를 구별하기에는 충분하다.
+	기록되는 동안 읽으면 count 가 bucket 들보다 하나 앞서 있을 수 있는데, 보고서에는 괜찮다.
+*/
+type histogram struct {
+	buckets [65]atomic.Uint64
+	count   atomic.Uint64
+	total   atomic.Int64
+	max     atomic.Int64
+}
+
+func (h *histogram) record(d time.Duration) {
+	if d < 0 {
+		d = 0
+	}
+	h.buckets[bits.Len64(uint64(d))].Add(1)
+	h.count.Add(1)
+	h.total.Add(int64(d))
+	for {
+		old := h.max.Load()
+		if int64(d) <= old || h.max.CompareAndSwap(old, int64(d)) {
+			return
+		}
+	}
+}
+
+/*
+	percentile returns the upper bound of the bucket holding the p-th percentile.
+	percentile 은 p 번째 백분위수를 가진 bucket 의 상한을 반환한다.
+*/
+func (h *histogram) percentile(p float64) time.Duration {
+	count := h.count.Load()
+	if count == 0 {
+		return 0
+	}
+	rank := uint64(p*float64(count-1)) + 1
+	var seen uint64
+	for i := range h.buckets {
+		seen += h.buckets[i].Load()
+		if seen >= rank {
+			return time.Duration(uint64(1) << i)
+		}
+	}
+	return h.maxDuration()
+}
+
+func (h *histogram) mean() time.Duration {
+	count := h.count.Load()
+	if count == 0 {
+		return 0
+	}
+	return time.Duration(h.total.Load()) / time.Duration(count)
+}
+
+func (h *histogram) maxDuration() time.Duration {
+	return time.Duration(h.max.Load())
+}
+
+/*
+	Lock finds the caller's call site before it takes the lock.
+	A TryLock first tells us whether the acquisition was contended without paying for it on the fast path.
+	Lock 은 lock 을 가져가기 전에 호출자의 호출 위치를 찾는다.
+	먼저 TryLock 을 해서 빠른 경로에서 비용을 치르지 않고 획득이 경쟁 상태였는지 알 수 있다.
+*/
+func (m *ProfiledMutex) Lock() {
+	var pc [1]uintptr
+	runtime.Callers(2, pc[:])
+	s := m.stats(pc[0])
+
+	start := time.Now()
+	contended := !m.mu.TryLock()
+	if contended {
+		m.mu.Lock()
+	}
+	now := time.Now()
+	m.lockedAt = now
+	m.lockSite = s
+	m.waited = now.Sub(start)
+	m.contended = contended
+}
+
+func (m *ProfiledMutex) Unlock() {
+	held := time.Since(m.lockedAt)
+	s, waited, contended := m.lockSite, m.waited, m.contended
+	m.mu.Unlock()
+
+	s.acquired.Add(1)
+	if contended {
+		s.contended.Add(1)
+	}
+	s.wait.record(waited)
+	s.hold.record(held)
+	if m.SlowThreshold > 0 && held > m.SlowThreshold {
+		s.slow.Add(1)
+		if m.OnSlow != nil {
+			m.OnSlow(s.site, held)
+		}
+	}
+}
+
+/*
+	stats returns the stats for the return address pc.
+	Only an address seen for the first time takes statsMu and formats its file and line.
+	stats 는 반환 주소 pc 의 통계를 반환한다.
+	처음 보는 주소만 statsMu 를 잡고 그 파일과 줄을 만든다.
+*/
+func (m *ProfiledMutex) stats(pc uintptr) *siteStats {
+	if s, ok := m.pcs.Load(pc); ok {
+		return s.(*siteStats)
+	}
+	site := callSite(pc)
+	m.statsMu.Lock()
+	defer m.statsMu.Unlock()
+	if m.sites == nil {
+		m.sites = make(map[string]*siteStats)
+	}
+	s, ok := m.sites[site]
+	if !ok {
+		s = &siteStats{site: site}
+		m.sites[site] = s
+	}
+	m.pcs.Store(pc, s)
+	return s
+}
+
+func callSite(pc uintptr) string {
+	if pc == 0 {
+		return "unknown"
+	}
+	frame, _ := runtime.CallersFrames([]uintptr{pc}).Next()
+	return fmt.Sprintf("%s:%d", filepath.Base(frame.File), frame.Line)
+}
+
+/*
+	Report writes one row per call site, sorted by call site, with the wait and hold time distributions.
+	Report 는 호출 위치마다 한 줄씩, 호출 위치 순서로 정렬해서, 기다린 시간과 가지고 있던 시간의 분포와 함께 쓴다.
+*/
+func (m *ProfiledMutex) Report(w io.Writer) {
+	m.statsMu.Lock()
+	sites := make([]*siteStats, 0, len(m.sites))
+	for _, s := range m.sites {
+		sites = append(sites, s)
+	}
+	m.statsMu.Unlock()
+	sort.Slice(sites, func(i, j int) bool { return sites[i].site < sites[j].site })
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "site\tacquired\tcontended\tslow\twait mean\twait p99\twait max\thold mean\thold p99\thold max")
+	for _, s := range sites {
+		fmt.Fprintf(tw, "%s\t%d\t%d\t%d\t%v\t%v\t%v\t%v\t%v\t%v\n",
+			s.site, s.acquired.Load(), s.contended.Load(), s.slow.Load(),
+			s.wait.mean(), s.wait.percentile(0.99), s.wait.maxDuration(),
+			s.hold.mean(), s.hold.percentile(0.99), s.hold.maxDuration())
+	}
+	tw.Flush()
+}
+
+/*
+	profiledOps runs the harness from main for d with a ProfiledMutex in place of the plain one.
+	The readers' sleep stays outside the lock as before, so the report shows whether the lock or the sleep dominates.
+	profiledOps 는 main 의 장치를 평범한 mutex 대신 ProfiledMutex 로 d 동안 실행한다.
+	reader 들의 잠은 이전처럼 lock 밖에 있으므로, 보고서는 lock 과 잠 중 어느 것이 지배적인지 보여준다.
+*/
+func profiledOps(d time.Duration, out io.Writer) {
+	var state = make(map[int]int)
+	mutex := &ProfiledMutex{SlowThreshold: 100 * time.Microsecond}
+	var stop atomic.Bool
+	var wg sync.WaitGroup
+
+	for r := 0; r < 100; r++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			total := 0
+			for !stop.Load() {
+				key := rand.Intn(5)
+				mutex.Lock()
+				total += state[key]
+				mutex.Unlock()
+				time.Sleep(time.Millisecond)
+			}
+		}()
+	}
+	for w := 0; w < 10; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for !stop.Load() {
+				key := rand.Intn(5)
+				val := rand.Intn(100)
+				mutex.Lock()
+				state[key] = val
+				mutex.Unlock()
+				time.Sleep(time.Millisecond)
+			}
+		}()
+	}
+
+	time.Sleep(d)
+	stop.Store(true)
+	wg.Wait()
+	mutex.Report(out)
+}
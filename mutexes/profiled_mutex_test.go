@@ -0,0 +1,76 @@
+package main
+
+import (
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+/*
+	TestProfiledMutex locks from a few call sites and checks the counts each one ends up with.
+	OnSlow locks the mutex again, which only works because it runs after the lock is released.
+	TestProfiledMutex 는 몇 개의 호출 위치에서 잠그고 각각이 갖게 되는 횟수를 확인한다.
+	OnSlow 는 mutex 를 다시 잠그는데, 이것은 lock 이 해제된 뒤에 실행되기 때문에만 동작한다.
+*/
+func TestProfiledMutex(t *testing.T) {
+	m := &ProfiledMutex{}
+	var wg sync.WaitGroup
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 100; i++ {
+				m.Lock()
+				m.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	/*
+		The threshold is only set now, so a loop iteration that happened to be descheduled while holding the lock does not count as slow.
+		임계값은 지금에서야 설정되므로, lock 을 가진 채로 우연히 스케줄에서 밀려난 반복은 느린 것으로 세어지지 않는다.
+	*/
+	m.SlowThreshold = time.Millisecond
+	var slowSites []string
+	m.OnSlow = func(site string, held time.Duration) {
+		slowSites = append(slowSites, site)
+		if len(slowSites) == 1 {
+			m.Lock()
+			m.Unlock()
+		}
+	}
+	m.Lock()
+	time.Sleep(2 * time.Millisecond)
+	m.Unlock()
+
+	rows := map[string]uint64{}
+	m.statsMu.Lock()
+	for site, s := range m.sites {
+		rows[site] = s.acquired.Load()
+	}
+	m.statsMu.Unlock()
+
+	var counts []uint64
+	for site, n := range rows {
+		if !strings.HasPrefix(site, "profiled_mutex_test.go:") {
+			t.Errorf("site %q is not in this file", site)
+		}
+		counts = append(counts, n)
+	}
+	/*
+		The slow section's OnSlow adds its own site, so there are three: the loop, the slow section and OnSlow.
+		느린 구역의 OnSlow 가 자신의 위치를 더하므로 세 개가 있다: 반복문, 느린 구역 그리고 OnSlow.
+	*/
+	if len(rows) != 3 || len(slowSites) == 0 || rows[slowSites[0]] != 1 {
+		t.Errorf("rows %v with slow sites %v, want three sites with the slow one acquired once", rows, slowSites)
+	}
+	total := uint64(0)
+	for _, n := range counts {
+		total += n
+	}
+	if total != 802 {
+		t.Errorf("%d acquisitions, want 802", total)
+	}
+}
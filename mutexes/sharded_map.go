@@ -0,0 +1,201 @@
+package main
+
+import (
+	"hash/maphash"
+	"math/rand"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+/*
+	In main every reader and writer goes through the one mutex, so 110 goroutines contend on a single lock.
+	ShardedMap splits the state over several shards, each a map with its own RWMutex.
+	A key always hashes to the same shard, so goroutines using different shards never wait on each other, and readers of the same shard can share it.
+	main 에서는 모든 reader 와 writer 가 하나의 mutex 를 거치기 때문에 110개의 고루틴이 하나의 lock 을 두고 경쟁한다.
+	ShardedMap 은 상태를 여러 shard 로 나누는데, 각 shard 는 자신만의 RWMutex 를 가진 map 이다.
+	key 는 항상 같은 shard 로 해시되기 때문에 서로 다른 shard 를 사용하는 고루틴들은 서로를 기다리지 않고, 같은 shard 의 reader 들은 그것을 공유할 수 있다.
+*/
+type ShardedMap[K comparable, V any] struct {
+	shards []shard[K, V]
+	mask   uint64
+	hash   func(K) uint64
+}
+
+type shard[K comparable, V any] struct {
+	mu sync.RWMutex
+	m  map[K]V
+}
+
+/*
+	NewShardedMap rounds shards up to a power of two so picking a shard is a mask instead of a division.
+	hash may be nil, in which case keys are hashed with hash/maphash.
+	NewShardedMap 은 shard 를 고르는 것이 나눗셈 대신 mask 가 되도록 shards 를 2의 거듭제곱으로 올림한다.
+	hash 는 nil 일 수 있으며, 그런 경우 key 는 hash/maphash 로 해시된다.
+*/
+func NewShardedMap[K comparable, V any](shards int, hash func(K) uint64) *ShardedMap[K, V] {
+	n := 1
+	for n < shards {
+		n <<= 1
+	}
+	if hash == nil {
+		seed := maphash.MakeSeed()
+		hash = func(k K) uint64 {
+			return maphash.Comparable(seed, k)
+		}
+	}
+	sm := &ShardedMap[K, V]{
+		shards: make([]shard[K, V], n),
+		mask:   uint64(n - 1),
+		hash:   hash,
+	}
+	for i := range sm.shards {
+		sm.shards[i].m = make(map[K]V)
+	}
+	return sm
+}
+
+func (sm *ShardedMap[K, V]) shard(key K) *shard[K, V] {
+	return &sm.shards[sm.hash(key)&sm.mask]
+}
+
+func (sm *ShardedMap[K, V]) Load(key K) (V, bool) {
+	s := sm.shard(key)
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	v, ok := s.m[key]
+	return v, ok
+}
+
+func (sm *ShardedMap[K, V]) Store(key K, val V) {
+	s := sm.shard(key)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.m[key] = val
+}
+
+func (sm *ShardedMap[K, V]) Delete(key K) {
+	s := sm.shard(key)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.m, key)
+}
+
+/*
+	LoadOrStore returns the existing value for key if there is one, otherwise it stores val.
+	loaded reports which of the two happened.
+	LoadOrStore 는 key 에 대한 기존 값이 있으면 그것을 반환하고, 그렇지 않으면 val 을 저장한다.
+	loaded 는 둘 중 어떤 것이 일어났는지 알려준다.
+*/
+func (sm *ShardedMap[K, V]) LoadOrStore(key K, val V) (actual V, loaded bool) {
+	s := sm.shard(key)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if v, ok := s.m[key]; ok {
+		return v, true
+	}
+	s.m[key] = val
+	return val, false
+}
+
+/*
+	Range calls f for every key and value until f returns false.
+	Each shard is copied under its read lock and f runs on the copy after the lock is released,
+	so f may call back into the map, even to write, without deadlocking.
+	Range is not a snapshot of the whole map: a shard visited later can reflect writes made after an earlier one was copied.
+	Range 는 f 가 false 를 반환할 때까지 모든 key 와 값에 대해 f 를 호출한다.
+	각 shard 는 읽기 잠금 아래에서 복사되고 f 는 잠금이 풀린 뒤에 그 복사본 위에서 실행되므로,
+	f 는 deadlock 없이 map 을 다시 호출할 수 있고, 쓰는 것도 가능하다.
+	Range 는 전체 map 의 스냅샷이 아니다: 나중에 방문한 shard 는 이전 shard 가 복사된 뒤에 일어난 쓰기를 반영할 수 있다.
+*/
+func (sm *ShardedMap[K, V]) Range(f func(key K, val V) bool) {
+	type entry struct {
+		k K
+		v V
+	}
+	var entries []entry
+	for i := range sm.shards {
+		s := &sm.shards[i]
+		s.mu.RLock()
+		entries = entries[:0]
+		for k, v := range s.m {
+			entries = append(entries, entry{k, v})
+		}
+		s.mu.RUnlock()
+		for _, e := range entries {
+			if !f(e.k, e.v) {
+				return
+			}
+		}
+	}
+}
+
+func (sm *ShardedMap[K, V]) Len() int {
+	n := 0
+	for i := range sm.shards {
+		s := &sm.shards[i]
+		s.mu.RLock()
+		n += len(s.m)
+		s.mu.RUnlock()
+	}
+	return n
+}
+
+/*
+	shardedOps is the readOps/writeOps harness from main run against a ShardedMap for d.
+	The readers and writers do not sleep between operations here, since the 1ms sleep would hide the lock we are measuring,
+	and there are 1024 keys instead of 5 so that there is something to spread over the shards.
+	shardedOps 는 main 의 readOps/writeOps 장치를 d 동안 ShardedMap 에 대해 실행한 것이다.
+	여기서 reader 와 writer 는 연산 사이에 잠들지 않는데, 1ms 의 잠이 우리가 측정하는 lock 을 가려버리기 때문이다,
+	그리고 shard 들에 퍼뜨릴 무언가가 있도록 key 가 5개 대신 1024개이다.
+*/
+func shardedOps(shards int, d time.Duration) (readOps, writeOps uint64) {
+	state := NewShardedMap[int, int](shards, nil)
+	var stop atomic.Bool
+	var wg sync.WaitGroup
+
+	/*
+		Every goroutine counts into its own slot and the slots are summed at the end.
+		A shared atomic counter would be one more contended cache line, and we would be measuring that instead of the shards.
+		모든 고루틴은 자신만의 칸에 세고 칸들은 마지막에 합쳐진다.
+		공유된 atomic 카운터는 경쟁하는 캐시 라인 하나가 더 되어서, 우리는 shard 대신 그것을 측정하게 될 것이다.
+	*/
+	reads := make([]uint64, 100)
+	writes := make([]uint64, 10)
+	for r := range reads {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			total, n := 0, uint64(0)
+			for !stop.Load() {
+				v, _ := state.Load(rand.Intn(1024))
+				total += v
+				n++
+			}
+			reads[r] = n
+		}()
+	}
+	for w := range writes {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			n := uint64(0)
+			for !stop.Load() {
+				state.Store(rand.Intn(1024), rand.Intn(100))
+				n++
+			}
+			writes[w] = n
+		}()
+	}
+
+	time.Sleep(d)
+	stop.Store(true)
+	wg.Wait()
+	for _, n := range reads {
+		readOps += n
+	}
+	for _, n := range writes {
+		writeOps += n
+	}
+	return readOps, writeOps
+}
@@ -0,0 +1,42 @@
+package main
+
+import "testing"
+
+/*
+	TestShardedMapRange calls back into the map from f, which deadlocked while Range held the shard's read lock around f.
+	TestShardedMapRange 는 f 안에서 map 을 다시 호출하는데, 이것은 Range 가 f 를 감싸서 shard 의 읽기 잠금을 잡고 있던 동안 deadlock 이었다.
+*/
+func TestShardedMapRange(t *testing.T) {
+	for _, tc := range []struct {
+		name              string
+		f                 func(sm *ShardedMap[int, int]) func(k, v int) bool
+		wantSeen, wantLen int
+	}{
+		{"read only", func(sm *ShardedMap[int, int]) func(k, v int) bool {
+			return func(k, v int) bool { return true }
+		}, 100, 100},
+		{"delete while ranging", func(sm *ShardedMap[int, int]) func(k, v int) bool {
+			return func(k, v int) bool { sm.Delete(k); return true }
+		}, 100, 0},
+		{"store while ranging", func(sm *ShardedMap[int, int]) func(k, v int) bool {
+			return func(k, v int) bool { sm.Store(k, v+1); return true }
+		}, 100, 100},
+		{"stop early", func(sm *ShardedMap[int, int]) func(k, v int) bool {
+			return func(k, v int) bool { return false }
+		}, 1, 100},
+	} {
+		sm := NewShardedMap[int, int](8, nil)
+		for i := 0; i < 100; i++ {
+			sm.Store(i, i)
+		}
+		seen := 0
+		f := tc.f(sm)
+		sm.Range(func(k, v int) bool {
+			seen++
+			return f(k, v)
+		})
+		if seen != tc.wantSeen || sm.Len() != tc.wantLen {
+			t.Errorf("%s: saw %d keys and %d are left, want %d and %d", tc.name, seen, sm.Len(), tc.wantSeen, tc.wantLen)
+		}
+	}
+}
@@ -0,0 +1,267 @@
+package main
+
+import (
+	"container/heap"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"gobyexample/internal/clock"
+)
+
+/*
+	TTLMap is the state map from main where every key can have a time to live.
+	An expired key is removed lazily by the first Get that finds it, and actively by one timer armed for the earliest deadline in a min-heap,
+	so keys nobody reads again do not stay around. The timer wakes a goroutine that takes the same mutex as everyone else.
+	Each entry knows its place in the heap, so overwriting or deleting a key moves or removes its deadline and the heap never holds more than one per key.
+	TTLMap 은 모든 key 가 생존 시간을 가질 수 있는 main 의 상태 map 이다.
+	만료된 key 는 그것을 찾은 첫 Get 에 의해 지연해서 제거되고, min-heap 에서 가장 이른 기한에 맞춰진 하나의 타이머에 의해 능동적으로 제거되므로,
+	아무도 다시 읽지 않는 key 들이 남아 있지 않는다. 타이머는 다른 모두와 같은 mutex 를 가져가는 고루틴을 깨운다.
+	각 항목은 heap 안에서의 자기 위치를 알기 때문에, key 를 덮어쓰거나 삭제하면 그 기한을 옮기거나 제거하며 heap 은 key 하나당 하나보다 많이 가지지 않는다.
+*/
+type TTLMap[K comparable, V any] struct {
+	mu        sync.Mutex
+	clock     clock.Clock
+	entries   map[K]*ttlEntry[K, V]
+	deadlines ttlHeap[K, V]
+	timer     clock.Timer
+	timerAt   time.Time
+	stats     TTLStats
+	stopped   bool
+	done      chan struct{}
+}
+
+/*
+	index is the entry's position in the deadline heap, or -1 if the key never expires.
+	index 는 기한 heap 안에서 항목의 위치이며, key 가 절대 만료되지 않으면 -1 이다.
+*/
+type ttlEntry[K comparable, V any] struct {
+	key   K
+	val   V
+	at    time.Time
+	index int
+}
+
+type ttlHeap[K comparable, V any] []*ttlEntry[K, V]
+
+func (h ttlHeap[K, V]) Len() int           { return len(h) }
+func (h ttlHeap[K, V]) Less(i, j int) bool { return h[i].at.Before(h[j].at) }
+func (h ttlHeap[K, V]) Swap(i, j int) {
+	h[i], h[j] = h[j], h[i]
+	h[i].index = i
+	h[j].index = j
+}
+func (h *ttlHeap[K, V]) Push(x any) {
+	e := x.(*ttlEntry[K, V])
+	e.index = len(*h)
+	*h = append(*h, e)
+}
+func (h *ttlHeap[K, V]) Pop() any {
+	old := *h
+	e := old[len(old)-1]
+	old[len(old)-1] = nil
+	*h = old[:len(old)-1]
+	e.index = -1
+	return e
+}
+
+/*
+	TTLStats counts Gets that found a key, Gets that did not, and keys removed because their deadline passed,
+	either by a Get that got there first or by the timer.
+	TTLStats 는 key 를 찾은 Get, 찾지 못한 Get, 그리고 기한이 지나서 제거된 key 들을 센다,
+	먼저 도착한 Get 에 의한 것이든 타이머에 의한 것이든.
+*/
+type TTLStats struct {
+	Hits, Misses                  int
+	ExpiredLazily, ExpiredByTimer int
+}
+
+func NewTTLMap[K comparable, V any]() *TTLMap[K, V] {
+	return NewTTLMapWithClock[K, V](clock.Real{})
+}
+
+/*
+	NewTTLMapWithClock is NewTTLMap on the given clock, so a test can move time instead of sleeping.
+	The timer starts stopped and is only armed once a key has a deadline.
+	NewTTLMapWithClock 은 주어진 시계 위의 NewTTLMap 이므로, 테스트는 잠드는 대신 시간을 움직일 수 있다.
+	타이머는 멈춘 채로 시작하고 key 가 기한을 가질 때에만 맞춰진다.
+*/
+func NewTTLMapWithClock[K comparable, V any](clk clock.Clock) *TTLMap[K, V] {
+	m := &TTLMap[K, V]{
+		clock:   clk,
+		entries: make(map[K]*ttlEntry[K, V]),
+		timer:   clk.NewTimer(time.Hour),
+		done:    make(chan struct{}),
+	}
+	m.timer.Stop()
+	go m.run()
+	return m
+}
+
+/*
+	Set stores val under key for ttl; a ttl of zero or less means the key never expires.
+	Set 은 val 을 key 아래에 ttl 동안 저장한다; ttl 이 0 이하이면 key 는 절대 만료되지 않는다.
+*/
+func (m *TTLMap[K, V]) Set(key K, val V, ttl time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var at time.Time
+	if ttl > 0 {
+		at = m.clock.Now().Add(ttl)
+	}
+	e, ok := m.entries[key]
+	if !ok {
+		e = &ttlEntry[K, V]{key: key, index: -1}
+		m.entries[key] = e
+	}
+	e.val, e.at = val, at
+	switch {
+	case ttl <= 0 && e.index >= 0:
+		heap.Remove(&m.deadlines, e.index)
+	case ttl > 0 && e.index >= 0:
+		heap.Fix(&m.deadlines, e.index)
+	case ttl > 0:
+		heap.Push(&m.deadlines, e)
+	}
+	if ttl > 0 && !m.stopped && (m.timerAt.IsZero() || at.Before(m.timerAt)) {
+		m.arm(at)
+	}
+}
+
+func (m *TTLMap[K, V]) Get(key K) (V, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	e, ok := m.entries[key]
+	if ok && !e.at.IsZero() && !m.clock.Now().Before(e.at) {
+		m.remove(e)
+		m.stats.ExpiredLazily++
+		ok = false
+	}
+	if !ok {
+		m.stats.Misses++
+		var zero V
+		return zero, false
+	}
+	m.stats.Hits++
+	return e.val, true
+}
+
+func (m *TTLMap[K, V]) Delete(key K) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if e, ok := m.entries[key]; ok {
+		m.remove(e)
+	}
+}
+
+func (m *TTLMap[K, V]) remove(e *ttlEntry[K, V]) {
+	if e.index >= 0 {
+		heap.Remove(&m.deadlines, e.index)
+	}
+	delete(m.entries, e.key)
+}
+
+/*
+	Len counts keys that are stored, including expired ones nobody has removed yet.
+	Len 은 아직 아무도 제거하지 않은 만료된 것들을 포함해서 저장된 key 들을 센다.
+*/
+func (m *TTLMap[K, V]) Len() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return len(m.entries)
+}
+
+func (m *TTLMap[K, V]) Stats() TTLStats {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.stats
+}
+
+/*
+	Stop stops the timer and its goroutine; keys still expire lazily on Get afterwards. Stopping twice does nothing.
+	Stop 은 타이머와 그 고루틴을 멈춘다; 그 뒤에도 key 들은 Get 에서 지연해서 만료된다. 두 번 멈추는 것은 아무것도 하지 않는다.
+*/
+func (m *TTLMap[K, V]) Stop() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.stopped {
+		return
+	}
+	m.stopped = true
+	m.timer.Stop()
+	close(m.done)
+}
+
+func (m *TTLMap[K, V]) arm(at time.Time) {
+	m.timerAt = at
+	m.timer.Reset(m.clock.Until(at))
+}
+
+func (m *TTLMap[K, V]) run() {
+	for {
+		select {
+		case <-m.timer.C():
+			m.expireDue()
+		case <-m.done:
+			return
+		}
+	}
+}
+
+/*
+	expireDue removes every key that is due and arms the timer for the earliest deadline left.
+	expireDue 는 기한이 된 모든 key 를 제거하고 남은 것 중 가장 이른 기한을 위해 타이머를 맞춘다.
+*/
+func (m *TTLMap[K, V]) expireDue() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.stopped {
+		return
+	}
+	now := m.clock.Now()
+	for len(m.deadlines) > 0 {
+		e := m.deadlines[0]
+		if now.Before(e.at) {
+			m.arm(e.at)
+			return
+		}
+		m.remove(e)
+		m.stats.ExpiredByTimer++
+	}
+	m.timerAt = time.Time{}
+}
+
+/*
+	sessionCacheExample uses a TTLMap as a session cache: session ids map to user names for a short TTL.
+	Some sessions are read after they expire and the rest are never read again, so only the timer can remove them.
+	Once the timer is stopped, expired sessions stay stored until a Get finds them.
+	sessionCacheExample 은 TTLMap 을 세션 캐시로 사용한다: 세션 id 는 짧은 TTL 동안 사용자 이름에 대응한다.
+	어떤 세션들은 만료된 뒤에 읽히고 나머지는 다시 읽히지 않으므로, 타이머만이 그것들을 제거할 수 있다.
+	타이머가 멈추고 나면, 만료된 세션들은 Get 이 그것들을 찾을 때까지 저장된 채로 남는다.
+*/
+func sessionCacheExample(out io.Writer) {
+	sessions := NewTTLMap[string, string]()
+	defer sessions.Stop()
+
+	for i := 1; i <= 10; i++ {
+		sessions.Set(fmt.Sprintf("session-%d", i), fmt.Sprintf("user-%d", i), time.Duration(i)*10*time.Millisecond)
+	}
+	sessions.Set("admin", "root", 0)
+	time.Sleep(35 * time.Millisecond)
+	for i := 1; i <= 5; i++ {
+		if user, ok := sessions.Get(fmt.Sprintf("session-%d", i)); ok {
+			fmt.Fprintf(out, "session-%d: %s\n", i, user)
+		}
+	}
+	sessions.Stop()
+	time.Sleep(100 * time.Millisecond)
+	fmt.Fprintln(out, "sessions stored: ", sessions.Len())
+	for i := 4; i <= 7; i++ {
+		sessions.Get(fmt.Sprintf("session-%d", i))
+	}
+	fmt.Fprintln(out, "sessions left: ", sessions.Len())
+	user, ok := sessions.Get("admin")
+	fmt.Fprintln(out, "admin: ", user, ok)
+	fmt.Fprintf(out, "ttl stats: %+v\n", sessions.Stats())
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"gobyexample/internal/clock"
+)
+
+/*
+	TestTTLMap runs a TTLMap on a fake clock. The timer only re-arms once it has expired what was due,
+	so waiting for it to be armed again is how the test knows the expiry goroutine is done.
+	TestTTLMap 은 가짜 시계 위에서 TTLMap 을 실행한다. 타이머는 기한이 된 것을 만료시킨 뒤에야 다시 맞춰지므로,
+	그것이 다시 맞춰지기를 기다리는 것이 테스트가 만료 고루틴이 끝났다는 것을 아는 방법이다.
+*/
+func TestTTLMap(t *testing.T) {
+	fake := clock.NewFake(time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC))
+	m := NewTTLMapWithClock[string, int](fake)
+	defer m.Stop()
+
+	m.Set("a", 1, 10*time.Millisecond)
+	m.Set("b", 2, 30*time.Millisecond)
+	m.Set("c", 3, 0)
+	fake.BlockUntil(1)
+	fake.Advance(10 * time.Millisecond)
+	fake.BlockUntil(1)
+	if _, ok := m.Get("a"); ok || m.Len() != 2 || m.Stats().ExpiredByTimer != 1 {
+		t.Errorf("after a's deadline: len %d, stats %+v, want a expired by the timer", m.Len(), m.Stats())
+	}
+
+	/*
+		Refreshing a deadline moves the key's one heap entry; it does not add another.
+		기한을 갱신하는 것은 key 의 하나뿐인 heap 항목을 옮긴다; 또 하나를 더하지 않는다.
+	*/
+	for i := 0; i < 100; i++ {
+		m.Set("b", 2, 50*time.Millisecond)
+	}
+	m.Set("d", 4, time.Minute)
+	m.Delete("d")
+	m.Set("c", 3, time.Minute)
+	m.Set("c", 3, 0)
+	m.mu.Lock()
+	heapLen := len(m.deadlines)
+	m.mu.Unlock()
+	if heapLen != 1 {
+		t.Errorf("heap holds %d deadlines, want 1 for b", heapLen)
+	}
+
+	/*
+		With the timer stopped only Get removes an expired key.
+		타이머가 멈추면 Get 만이 만료된 key 를 제거한다.
+	*/
+	m.Stop()
+	fake.Advance(time.Minute)
+	if m.Len() != 2 {
+		t.Errorf("len %d after stop, want b and c still stored", m.Len())
+	}
+	if _, ok := m.Get("b"); ok {
+		t.Errorf("b found after its deadline")
+	}
+	if val, ok := m.Get("c"); val != 3 || !ok {
+		t.Errorf("c = %d, %v, want 3 without a deadline", val, ok)
+	}
+	m.mu.Lock()
+	heapLen = len(m.deadlines)
+	m.mu.Unlock()
+	if s := m.Stats(); s.ExpiredLazily != 1 || s.ExpiredByTimer != 1 || m.Len() != 1 || heapLen != 0 {
+		t.Errorf("stats %+v, len %d, heap %d, want b expired lazily and only c left", s, m.Len(), heapLen)
+	}
+}
@@ -0,0 +1,281 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"gobyexample/internal/clock"
+)
+
+/*
+	A fixed regulator like the 200ms ticker in main cannot react when the downstream gets slow.
+	AdaptiveLimiter limits how many calls are in flight instead, and resizes that limit from what it observes:
+	with AIMD every success adds a little to the limit and every error or timeout cuts it by a factor,
+	and in Vegas mode the limit follows how far the latency has drifted above the best latency seen so far.
+	main 의 200ms ticker 같은 고정된 regulator 는 downstream 이 느려질 때 반응할 수 없다.
+	AdaptiveLimiter 는 대신 동시에 진행 중인 호출의 개수를 제한하고, 관찰한 것으로부터 그 제한의 크기를 바꾼다:
+	AIMD 에서는 모든 성공이 제한에 조금씩 더하고 모든 에러나 타임아웃은 제한을 비율만큼 깎으며,
+	Vegas 모드에서는 제한이 지금까지 본 가장 좋은 latency 보다 latency 가 얼마나 올라갔는지를 따라간다.
+*/
+
+/*
+	ErrDropped can be passed to the done func to count a call as a failure without a real error, for example when it was shed.
+	ErrDropped 는 실제 에러 없이 호출을 실패로 세기 위해 done 함수에 넘길 수 있다, 예를 들어 요청이 버려졌을 때.
+*/
+var ErrDropped = errors.New("call dropped")
+
+type AdaptiveLimiter struct {
+	clock    clock.Clock
+	mu       sync.Mutex
+	limit    float64
+	min      int
+	max      int
+	inFlight int
+	released chan struct{}
+
+	backoff      float64
+	timeout      time.Duration
+	vegas        bool
+	minLatency   time.Duration
+	lastDecrease time.Time
+}
+
+/*
+	NewAIMDLimiter starts at initial in-flight calls and stays between min and max.
+	A call that fails or takes longer than timeout multiplies the limit by backoff (0.9 is typical).
+	min is at least 1, because a limit of 0 would block every Acquire forever, and initial is clamped into [min, max].
+	NewAIMDLimiter 는 initial 개의 진행 중인 호출로 시작하고 min 과 max 사이에 머문다.
+	실패하거나 timeout 보다 오래 걸린 호출은 제한에 backoff 를 곱한다(보통 0.9).
+	제한 0 은 모든 Acquire 를 영원히 막으므로 min 은 최소 1 이고, initial 은 [min, max] 안으로 맞춰진다.
+*/
+func NewAIMDLimiter(initial, min, max int, backoff float64, timeout time.Duration) *AdaptiveLimiter {
+	return NewAIMDLimiterWithClock(clock.Real{}, initial, min, max, backoff, timeout)
+}
+
+func NewAIMDLimiterWithClock(clk clock.Clock, initial, min, max int, backoff float64, timeout time.Duration) *AdaptiveLimiter {
+	if min < 1 {
+		min = 1
+	}
+	if max < min {
+		max = min
+	}
+	if initial < min {
+		initial = min
+	}
+	if initial > max {
+		initial = max
+	}
+	return &AdaptiveLimiter{
+		clock:    clk,
+		limit:    float64(initial),
+		min:      min,
+		max:      max,
+		released: make(chan struct{}),
+		backoff:  backoff,
+		timeout:  timeout,
+	}
+}
+
+/*
+	NewVegasLimiter is like NewAIMDLimiter, but successful calls move the limit by the latency gradient instead of always adding to it.
+	NewVegasLimiter 는 NewAIMDLimiter 와 같지만, 성공한 호출은 항상 더하는 대신 latency 기울기에 따라 제한을 움직인다.
+*/
+func NewVegasLimiter(initial, min, max int, backoff float64, timeout time.Duration) *AdaptiveLimiter {
+	return NewVegasLimiterWithClock(clock.Real{}, initial, min, max, backoff, timeout)
+}
+
+func NewVegasLimiterWithClock(clk clock.Clock, initial, min, max int, backoff float64, timeout time.Duration) *AdaptiveLimiter {
+	l := NewAIMDLimiterWithClock(clk, initial, min, max, backoff, timeout)
+	l.vegas = true
+	return l
+}
+
+/*
+	Acquire blocks until there is room for another call in flight.
+	The caller must call done with the call's error exactly once when it finishes; that is where the latency is measured.
+	Acquire 는 진행 중인 호출이 하나 더 들어갈 자리가 생길 때까지 막힌다.
+	호출자는 호출이 끝났을 때 호출의 에러와 함께 done 을 정확히 한 번 불러야 한다; 그곳에서 latency 가 측정된다.
+*/
+func (l *AdaptiveLimiter) Acquire(ctx context.Context) (done func(err error), err error) {
+	for {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		l.mu.Lock()
+		if l.inFlight < int(l.limit) {
+			l.inFlight++
+			l.mu.Unlock()
+			start := l.clock.Now()
+			var once sync.Once
+			return func(err error) {
+				once.Do(func() { l.release(start, err) })
+			}, nil
+		}
+		released := l.released
+		l.mu.Unlock()
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-released:
+		}
+	}
+}
+
+func (l *AdaptiveLimiter) release(start time.Time, err error) {
+	now := l.clock.Now()
+	latency := now.Sub(start)
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.inFlight--
+
+	switch {
+	case err != nil || (l.timeout > 0 && latency > l.timeout):
+		/*
+			A burst of failures is one signal, not many: like TCP we cut at most once per round trip,
+			ignoring failures of calls that were already in flight when we last cut.
+			실패의 폭발은 여러 개가 아닌 하나의 신호이다: TCP 처럼 왕복 한 번에 최대 한 번만 깎으며,
+			마지막으로 깎았을 때 이미 진행 중이던 호출의 실패는 무시한다.
+		*/
+		if start.After(l.lastDecrease) {
+			l.limit *= l.backoff
+			l.lastDecrease = now
+		}
+	case l.vegas:
+		if l.minLatency == 0 || latency < l.minLatency {
+			l.minLatency = latency
+		}
+		/*
+			queue estimates how many of our calls are waiting in the downstream rather than being worked on.
+			Vegas keeps it between 3 and 6 steps; each call moves the limit by 1/limit of a step, so a whole round trip moves it by one step.
+			queue 는 우리의 호출 중 얼마나 많은 것이 처리되는 대신 downstream 에서 기다리고 있는지 추정한다.
+			Vegas 는 이것을 3 에서 6 단계 사이로 유지한다; 각 호출은 제한을 한 단계의 1/limit 만큼 움직이므로, 왕복 한 번 전체가 한 단계를 움직인다.
+		*/
+		queue := l.limit * (1 - float64(l.minLatency)/float64(latency))
+		step := math.Log10(l.limit + 1)
+		switch {
+		case queue < 3*step:
+			l.limit += step / l.limit
+		case queue > 6*step:
+			l.limit -= step / l.limit
+		}
+	case l.inFlight+1 >= int(l.limit)/2:
+		/*
+			Only grow when the limit is actually being used, otherwise an idle limiter would creep up to max.
+			실제로 제한이 사용되고 있을 때만 늘린다, 그렇지 않으면 쉬고 있는 limiter 가 max 까지 슬금슬금 올라간다.
+		*/
+		l.limit += 1 / l.limit
+	}
+	l.limit = math.Max(float64(l.min), math.Min(float64(l.max), l.limit))
+
+	close(l.released)
+	l.released = make(chan struct{})
+}
+
+/*
+	Limit returns the current in-flight limit.
+	Limit 은 현재 진행 중인 호출의 제한을 반환한다.
+*/
+func (l *AdaptiveLimiter) Limit() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return int(l.limit)
+}
+
+func (l *AdaptiveLimiter) InFlight() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.inFlight
+}
+
+/*
+	fakeBackend serves capacity calls at once in base time each.
+	Beyond that calls queue up and get slower, and past one and a half times the capacity it starts failing.
+	fakeBackend 는 한번에 capacity 개의 호출을 각각 base 시간에 처리한다.
+	그걸 넘어서면 호출들이 줄을 서서 느려지고, capacity 의 1.5배를 넘으면 실패하기 시작한다.
+*/
+type fakeBackend struct {
+	capacity atomic.Int64
+	active   atomic.Int64
+	base     time.Duration
+}
+
+func (b *fakeBackend) call() error {
+	active := b.active.Add(1)
+	defer b.active.Add(-1)
+	latency, err := b.respond(active, b.capacity.Load())
+	time.Sleep(latency)
+	return err
+}
+
+/*
+	respond is how long a call takes, and whether it fails, with active calls in the backend.
+	respond 는 backend 에 active 개의 호출이 있을 때 호출이 얼마나 걸리는지와 실패하는지이다.
+*/
+func (b *fakeBackend) respond(active, capacity int64) (time.Duration, error) {
+	if 2*active > 3*capacity {
+		return b.base, ErrDropped
+	}
+	if active > capacity {
+		return time.Duration(float64(b.base) * float64(active) / float64(capacity)), nil
+	}
+	return b.base, nil
+}
+
+/*
+	simulateAdaptive drives 40 clients against a backend whose capacity drops from 20 to 5 halfway through the run,
+	printing the limit the limiter has settled on at the end of each phase.
+	It runs on the real clock; TestAdaptiveLimiter replays the same drop on a fake clock and checks where the limit settles.
+	simulateAdaptive 는 실행 도중에 capacity 가 20 에서 5 로 떨어지는 backend 에 대해 40개의 클라이언트를 돌리며,
+	각 단계가 끝날 때 limiter 가 자리잡은 제한을 출력한다.
+	이것은 실제 clock 위에서 실행된다; TestAdaptiveLimiter 는 같은 하락을 가짜 clock 위에서 재생하고 제한이 어디에 자리잡는지 확인한다.
+*/
+func simulateAdaptive() {
+	limiters := []struct {
+		name    string
+		limiter *AdaptiveLimiter
+	}{
+		{"aimd", NewAIMDLimiter(10, 1, 100, 0.9, 10*time.Millisecond)},
+		{"vegas", NewVegasLimiter(10, 1, 100, 0.9, 10*time.Millisecond)},
+	}
+	for _, tt := range limiters {
+		backend := &fakeBackend{base: 5 * time.Millisecond}
+		backend.capacity.Store(20)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		var calls, failures atomic.Int64
+		var wg sync.WaitGroup
+		for c := 0; c < 40; c++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for {
+					done, err := tt.limiter.Acquire(ctx)
+					if err != nil {
+						return
+					}
+					err = backend.call()
+					if err != nil {
+						failures.Add(1)
+					}
+					calls.Add(1)
+					done(err)
+				}
+			}()
+		}
+
+		time.Sleep(time.Second)
+		fmt.Println(tt.name, "capacity 20: limit", tt.limiter.Limit())
+		backend.capacity.Store(5)
+		time.Sleep(time.Second)
+		fmt.Println(tt.name, "capacity 5:  limit", tt.limiter.Limit())
+		cancel()
+		wg.Wait()
+		fmt.Println(tt.name, "calls", calls.Load(), "failures", failures.Load())
+	}
+}
@@ -0,0 +1,114 @@
+package main
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"gobyexample/internal/clock"
+)
+
+/*
+	rounds drives l against the backend in lock step on the fake clock:
+	each round starts as many calls as the limit allows, lets the backend's latency pass and then finishes them all.
+	It returns the smallest and largest limit seen over the last half of the rounds.
+	rounds 는 가짜 clock 위에서 l 을 backend 에 대해 발맞춰 움직인다:
+	각 라운드는 제한이 허용하는 만큼 호출을 시작하고, backend 의 latency 를 흘려보낸 뒤 그것들을 모두 끝낸다.
+	라운드의 마지막 절반 동안 본 가장 작은 제한과 가장 큰 제한을 반환한다.
+*/
+func rounds(f *clock.Fake, l *AdaptiveLimiter, b *fakeBackend, n int) (lo, hi int) {
+	lo, hi = l.Limit(), l.Limit()
+	for i := 0; i < n; i++ {
+		var dones []func(error)
+		for l.InFlight() < l.Limit() {
+			done, err := l.Acquire(context.Background())
+			if err != nil {
+				panic(err)
+			}
+			dones = append(dones, done)
+		}
+		latency, err := b.respond(int64(len(dones)), b.capacity.Load())
+		f.Advance(latency)
+		for _, done := range dones {
+			done(err)
+		}
+		if i == n/2 {
+			lo, hi = l.Limit(), l.Limit()
+		}
+		lo, hi = min(lo, l.Limit()), max(hi, l.Limit())
+	}
+	return lo, hi
+}
+
+/*
+	TestAdaptiveLimiter is simulateAdaptive on a fake clock.
+	Both limiters have to settle between the backend's capacity and the point where it starts failing, before and after the capacity drops from 20 to 5.
+	Probing one call past that point is fine; that is how AIMD finds it.
+	TestAdaptiveLimiter 는 가짜 clock 위의 simulateAdaptive 이다.
+	두 limiter 모두 capacity 가 20 에서 5 로 떨어지기 전과 후에, backend 의 capacity 와 실패하기 시작하는 지점 사이에 자리잡아야 한다.
+	그 지점을 호출 하나만큼 넘어서 탐색하는 것은 괜찮다; AIMD 는 그렇게 그 지점을 찾는다.
+*/
+func TestAdaptiveLimiter(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		new  func(clk clock.Clock) *AdaptiveLimiter
+	}{
+		{"aimd", func(clk clock.Clock) *AdaptiveLimiter {
+			return NewAIMDLimiterWithClock(clk, 10, 1, 100, 0.9, 10*time.Millisecond)
+		}},
+		{"vegas", func(clk clock.Clock) *AdaptiveLimiter {
+			return NewVegasLimiterWithClock(clk, 10, 1, 100, 0.9, 10*time.Millisecond)
+		}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			f := clock.NewFake(time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC))
+			l := tc.new(f)
+			backend := &fakeBackend{base: 5 * time.Millisecond}
+			for _, capacity := range []int{20, 5} {
+				backend.capacity.Store(int64(capacity))
+				lo, hi := rounds(f, l, backend, 200)
+				t.Logf("capacity %d: limit %d..%d", capacity, lo, hi)
+				if lo < capacity || hi > capacity*3/2+1 {
+					t.Errorf("capacity %d: limit moved in %d..%d, want it within %d..%d", capacity, lo, hi, capacity, capacity*3/2+1)
+				}
+			}
+		})
+	}
+}
+
+/*
+	TestAdaptiveLimiterBounds checks that a limiter built with a zero initial and min still lets one call through,
+	and that a slow call is cut like a failed one, using latency measured on the limiter's clock.
+	TestAdaptiveLimiterBounds 는 initial 과 min 을 0 으로 만든 limiter 도 여전히 호출 하나를 통과시키는지,
+	그리고 limiter 의 clock 으로 측정된 latency 를 사용해서 느린 호출이 실패한 호출처럼 깎이는지 확인한다.
+*/
+func TestAdaptiveLimiterBounds(t *testing.T) {
+	f := clock.NewFake(time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC))
+	for _, tc := range []struct {
+		name                string
+		initial, min, max   int
+		latency             time.Duration
+		wantStart, wantDone int
+	}{
+		{"zero initial and min", 0, 0, 0, time.Millisecond, 1, 1},
+		{"initial above max", 50, 1, 10, time.Millisecond, 10, 10},
+		{"slow call is cut", 10, 1, 100, 20 * time.Millisecond, 10, 9},
+	} {
+		l := NewAIMDLimiterWithClock(f, tc.initial, tc.min, tc.max, 0.9, 10*time.Millisecond)
+		if got := l.Limit(); got != tc.wantStart {
+			t.Errorf("%s: starts at %d, want %d", tc.name, got, tc.wantStart)
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		done, err := l.Acquire(ctx)
+		cancel()
+		if err != nil {
+			t.Errorf("%s: acquire: %v", tc.name, err)
+			continue
+		}
+		f.Advance(tc.latency)
+		done(nil)
+		if got := l.Limit(); got != tc.wantDone {
+			t.Errorf("%s: after one call at %v the limit is %d, want %d", tc.name, tc.latency, got, tc.wantDone)
+		}
+	}
+}
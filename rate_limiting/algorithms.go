@@ -0,0 +1,283 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"gobyexample/internal/clock"
+)
+
+/*
+	Limiter is what call sites depend on, so the algorithm behind it can be swapped without touching them.
+	TokenBucket is one Limiter; below are three more that keep no goroutine at all and work everything out from the time of each request.
+	Limiter 는 호출하는 곳이 의존하는 것이라서, 그 뒤의 알고리즘은 호출하는 곳을 건드리지 않고 바꿀 수 있다.
+	TokenBucket 이 하나의 Limiter 이고, 아래에 고루틴을 전혀 갖지 않고 각 요청의 시간으로부터 모든 것을 계산하는 세 개가 더 있다.
+*/
+type Limiter interface {
+	Allow() bool
+	Wait(ctx context.Context) error
+}
+
+var (
+	_ Limiter = (*TokenBucket)(nil)
+	_ Limiter = (*SlidingWindowLog)(nil)
+	_ Limiter = (*SlidingWindowCounter)(nil)
+	_ Limiter = (*GCRA)(nil)
+)
+
+/*
+	algorithm is a limiter that decides at a given instant, and otherwise says how long until it would allow.
+	Passing the instant in lets compareLimiters replay the same trace against every algorithm.
+	algorithm 은 주어진 순간에 결정하는 limiter 이며, 허용하지 않는다면 허용할 때까지 얼마나 걸리는지 말해준다.
+	순간을 넘겨주는 것은 compareLimiters 가 같은 기록을 모든 알고리즘에 대해 재생할 수 있게 한다.
+*/
+type algorithm interface {
+	allowAt(now time.Time) (ok bool, retryAfter time.Duration)
+}
+
+func allow(clk clock.Clock, a algorithm) bool {
+	ok, _ := a.allowAt(clk.Now())
+	return ok
+}
+
+func wait(ctx context.Context, clk clock.Clock, a algorithm) error {
+	for {
+		ok, retryAfter := a.allowAt(clk.Now())
+		if ok {
+			return nil
+		}
+		timer := clk.NewTimer(retryAfter)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C():
+		}
+	}
+}
+
+/*
+	SlidingWindowLog remembers the time of every allowed request in the last window and allows at most limit of them.
+	It is exact, but its memory grows with limit. Like NewTokenBucket, it panics on a non-positive limit or window.
+	SlidingWindowLog 는 마지막 window 안에서 허용된 모든 요청의 시간을 기억하고 최대 limit 개까지 허용한다.
+	정확하지만 메모리가 limit 에 따라 늘어난다. NewTokenBucket 처럼 양수가 아닌 limit 이나 window 에는 panic 한다.
+*/
+type SlidingWindowLog struct {
+	clock  clock.Clock
+	mu     sync.Mutex
+	limit  int
+	window time.Duration
+	log    []time.Time
+}
+
+func NewSlidingWindowLog(limit int, window time.Duration) *SlidingWindowLog {
+	return NewSlidingWindowLogWithClock(clock.Real{}, limit, window)
+}
+
+func NewSlidingWindowLogWithClock(clk clock.Clock, limit int, window time.Duration) *SlidingWindowLog {
+	checkWindow("NewSlidingWindowLog", limit, window)
+	return &SlidingWindowLog{clock: clk, limit: limit, window: window}
+}
+
+func checkWindow(caller string, limit int, window time.Duration) {
+	if limit <= 0 {
+		panic("rate_limiting: non-positive limit for " + caller)
+	}
+	if window <= 0 {
+		panic("rate_limiting: non-positive window for " + caller)
+	}
+}
+
+func (l *SlidingWindowLog) allowAt(now time.Time) (bool, time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	cutoff := now.Add(-l.window)
+	drop := 0
+	for drop < len(l.log) && !l.log[drop].After(cutoff) {
+		drop++
+	}
+	l.log = l.log[drop:]
+	if len(l.log) < l.limit {
+		l.log = append(l.log, now)
+		return true, 0
+	}
+	return false, l.log[0].Sub(cutoff)
+}
+
+func (l *SlidingWindowLog) Allow() bool {
+	return allow(l.clock, l)
+}
+
+func (l *SlidingWindowLog) Wait(ctx context.Context) error {
+	return wait(ctx, l.clock, l)
+}
+
+/*
+	SlidingWindowCounter only keeps counts for the current and the previous fixed window.
+	It estimates the sliding count by weighting the previous window by how much of it still overlaps.
+	It panics on a non-positive limit or window, as NewSlidingWindowLog does.
+	SlidingWindowCounter 는 현재와 이전 고정 window 의 카운트만 유지한다.
+	이전 window 가 아직 얼마나 겹치는지로 가중치를 줘서 sliding 카운트를 추정한다.
+	NewSlidingWindowLog 처럼 양수가 아닌 limit 이나 window 에는 panic 한다.
+*/
+type SlidingWindowCounter struct {
+	clock  clock.Clock
+	mu     sync.Mutex
+	limit  int
+	window time.Duration
+	start  time.Time
+	prev   int
+	cur    int
+}
+
+func NewSlidingWindowCounter(limit int, window time.Duration) *SlidingWindowCounter {
+	return NewSlidingWindowCounterWithClock(clock.Real{}, limit, window)
+}
+
+func NewSlidingWindowCounterWithClock(clk clock.Clock, limit int, window time.Duration) *SlidingWindowCounter {
+	checkWindow("NewSlidingWindowCounter", limit, window)
+	return &SlidingWindowCounter{clock: clk, limit: limit, window: window}
+}
+
+func (c *SlidingWindowCounter) allowAt(now time.Time) (bool, time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.start.IsZero() {
+		c.start = now
+	}
+	if elapsed := now.Sub(c.start); elapsed >= c.window {
+		windows := elapsed / c.window
+		if windows == 1 {
+			c.prev = c.cur
+		} else {
+			c.prev = 0
+		}
+		c.cur = 0
+		c.start = c.start.Add(windows * c.window)
+	}
+
+	elapsed := now.Sub(c.start)
+	weight := 1 - float64(elapsed)/float64(c.window)
+	if float64(c.prev)*weight+float64(c.cur)+1 <= float64(c.limit) {
+		c.cur++
+		return true, 0
+	}
+
+	/*
+		Until the next window starts, only the previous window's share can shrink.
+		다음 window 가 시작되기 전까지는 이전 window 의 몫만 줄어들 수 있다.
+	*/
+	untilNext := c.window - elapsed
+	if c.prev == 0 || c.cur+1 > c.limit {
+		return false, untilNext
+	}
+	need := 1 - float64(c.limit-c.cur-1)/float64(c.prev)
+	retryAfter := time.Duration(need*float64(c.window)) - elapsed
+	if retryAfter <= 0 || retryAfter > untilNext {
+		retryAfter = untilNext
+	}
+	return false, retryAfter
+}
+
+func (c *SlidingWindowCounter) Allow() bool {
+	return allow(c.clock, c)
+}
+
+func (c *SlidingWindowCounter) Wait(ctx context.Context) error {
+	return wait(ctx, c.clock, c)
+}
+
+/*
+	GCRA (generic cell rate algorithm) is a token bucket stored as a single time: the theoretical arrival time tat of the next request.
+	Each allowed request pushes tat forward by one interval, and a request is refused when tat is more than burst-1 intervals ahead of now.
+	Like NewTokenBucket, it panics on a non-positive interval.
+	GCRA(generic cell rate algorithm) 는 하나의 시간으로 저장되는 token bucket 이다: 다음 요청의 이론적인 도착 시간 tat.
+	허용된 각 요청은 tat 를 interval 하나만큼 앞으로 밀고, tat 가 지금보다 burst-1 개의 interval 보다 더 앞서 있으면 요청은 거절된다.
+	NewTokenBucket 처럼 양수가 아닌 interval 에는 panic 한다.
+*/
+type GCRA struct {
+	clock     clock.Clock
+	mu        sync.Mutex
+	every     time.Duration
+	tolerance time.Duration
+	tat       time.Time
+}
+
+func NewGCRA(every time.Duration, burst int) *GCRA {
+	return NewGCRAWithClock(clock.Real{}, every, burst)
+}
+
+func NewGCRAWithClock(clk clock.Clock, every time.Duration, burst int) *GCRA {
+	if every <= 0 {
+		panic("rate_limiting: non-positive interval for NewGCRA")
+	}
+	if burst < 1 {
+		burst = 1
+	}
+	return &GCRA{clock: clk, every: every, tolerance: time.Duration(burst-1) * every}
+}
+
+func (g *GCRA) allowAt(now time.Time) (bool, time.Duration) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	tat := g.tat
+	if tat.Before(now) {
+		tat = now
+	}
+	if ahead := tat.Sub(now); ahead > g.tolerance {
+		return false, ahead - g.tolerance
+	}
+	g.tat = tat.Add(g.every)
+	return true, 0
+}
+
+func (g *GCRA) Allow() bool {
+	return allow(g.clock, g)
+}
+
+func (g *GCRA) Wait(ctx context.Context) error {
+	return wait(ctx, g.clock, g)
+}
+
+/*
+	bursty is the arrival trace compareLimiters replays.
+	It starts like the burstyRequests loop in main - 5 requests at once - and then keeps arriving for a while.
+	bursty 는 compareLimiters 가 재생하는 도착 기록이다.
+	main 의 burstyRequests 반복문처럼 시작하고(한번에 5개 요청) 그 후 한동안 계속 도착한다.
+*/
+var bursty = []time.Duration{0, 0, 0, 0, 0, 150, 200, 400, 450, 600, 650, 1000, 1300, 1310, 1320}
+
+/*
+	compareLimiters replays the bursty trace against each algorithm and prints one row each.
+	Every algorithm is configured for the same budget: 3 requests per 600ms, or one every 200ms with a burst of 3.
+	TestAlgorithms checks the same rows on a fake clock.
+	compareLimiters 는 bursty 기록을 각 알고리즘에 대해 재생하고 각각 한 줄씩 출력한다.
+	모든 알고리즘은 같은 예산으로 설정된다: 600ms 당 3개의 요청, 또는 burst 3과 함께 매 200ms 마다 하나.
+	TestAlgorithms 는 가짜 clock 위에서 같은 줄들을 확인한다.
+*/
+func compareLimiters() {
+	algs := []struct {
+		name string
+		alg  algorithm
+	}{
+		{"sliding window log", NewSlidingWindowLog(3, 600*time.Millisecond)},
+		{"sliding window counter", NewSlidingWindowCounter(3, 600*time.Millisecond)},
+		{"gcra", NewGCRA(200*time.Millisecond, 3)},
+	}
+
+	start := time.Now()
+	for _, a := range algs {
+		var row strings.Builder
+		for _, at := range bursty {
+			if ok, _ := a.alg.allowAt(start.Add(at * time.Millisecond)); ok {
+				row.WriteByte('+')
+			} else {
+				row.WriteByte('-')
+			}
+		}
+		fmt.Printf("%-24s %s\n", a.name, row.String())
+	}
+}
@@ -0,0 +1,125 @@
+package main
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"gobyexample/internal/clock"
+)
+
+/*
+	TestAlgorithms replays the bursty trace through Allow, moving a fake clock from one arrival to the next.
+	TestAlgorithms 는 가짜 clock 을 한 도착에서 다음 도착으로 옮기면서 bursty 기록을 Allow 로 재생한다.
+*/
+func TestAlgorithms(t *testing.T) {
+	start := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	for _, tc := range []struct {
+		name string
+		new  func(clk clock.Clock) Limiter
+		want string
+	}{
+		{"sliding window log", func(clk clock.Clock) Limiter {
+			return NewSlidingWindowLogWithClock(clk, 3, 600*time.Millisecond)
+		}, "+++------+++++-"},
+		{"sliding window counter", func(clk clock.Clock) Limiter {
+			return NewSlidingWindowCounterWithClock(clk, 3, 600*time.Millisecond)
+		}, "+++--------+++-"},
+		{"gcra", func(clk clock.Clock) Limiter {
+			return NewGCRAWithClock(clk, 200*time.Millisecond, 3)
+		}, "+++---++-+-+++-"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			fake := clock.NewFake(start)
+			l := tc.new(fake)
+			var got strings.Builder
+			for _, at := range bursty {
+				fake.Advance(start.Add(at * time.Millisecond).Sub(fake.Now()))
+				if l.Allow() {
+					got.WriteByte('+')
+				} else {
+					got.WriteByte('-')
+				}
+			}
+			if got.String() != tc.want {
+				t.Errorf("got %s, want %s", got.String(), tc.want)
+			}
+		})
+	}
+}
+
+/*
+	TestAlgorithmsWait empties each limiter and checks that Wait sleeps on the limiter's clock until the retry time it worked out.
+	The sliding window counter needs two sleeps: at 600ms the previous window still counts in full.
+	TestAlgorithmsWait 는 각 limiter 를 비우고, Wait 가 limiter 의 clock 위에서 계산한 재시도 시간까지 잠드는지 확인한다.
+	sliding window counter 는 두 번 잠들어야 한다: 600ms 에는 이전 window 가 아직 전부 계산된다.
+*/
+func TestAlgorithmsWait(t *testing.T) {
+	start := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	for _, tc := range []struct {
+		name string
+		new  func(clk clock.Clock) Limiter
+		naps []time.Duration
+	}{
+		{"sliding window log", func(clk clock.Clock) Limiter {
+			return NewSlidingWindowLogWithClock(clk, 3, 600*time.Millisecond)
+		}, []time.Duration{600 * time.Millisecond}},
+		{"sliding window counter", func(clk clock.Clock) Limiter {
+			return NewSlidingWindowCounterWithClock(clk, 3, 600*time.Millisecond)
+		}, []time.Duration{600 * time.Millisecond, 200 * time.Millisecond}},
+		{"gcra", func(clk clock.Clock) Limiter {
+			return NewGCRAWithClock(clk, 200*time.Millisecond, 3)
+		}, []time.Duration{200 * time.Millisecond}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			fake := clock.NewFake(start)
+			l := tc.new(fake)
+			for l.Allow() {
+			}
+			served := make(chan time.Duration)
+			go func() {
+				l.Wait(context.Background())
+				served <- fake.Since(start)
+			}()
+			var want time.Duration
+			for _, nap := range tc.naps {
+				fake.BlockUntil(1)
+				fake.Advance(nap)
+				want += nap
+			}
+			if got := <-served; got != want {
+				t.Errorf("served at %v, want %v", got, want)
+			}
+		})
+	}
+}
+
+/*
+	TestAlgorithmsBadArguments checks that the constructors refuse a limit, window or interval that would break the limiter later,
+	with a message that names them, instead of an index out of range or a division by zero on the first request.
+	TestAlgorithmsBadArguments 는 생성자들이 나중에 limiter 를 망가뜨릴 limit, window 또는 interval 을, 첫 요청에서의
+	index out of range 나 0 으로 나누기 대신, 그것들의 이름을 대는 메시지와 함께 거부하는지 확인한다.
+*/
+func TestAlgorithmsBadArguments(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		new  func()
+		want string
+	}{
+		{"log limit", func() { NewSlidingWindowLog(0, time.Second) }, "rate_limiting: non-positive limit for NewSlidingWindowLog"},
+		{"log window", func() { NewSlidingWindowLog(3, 0) }, "rate_limiting: non-positive window for NewSlidingWindowLog"},
+		{"counter limit", func() { NewSlidingWindowCounter(-1, time.Second) }, "rate_limiting: non-positive limit for NewSlidingWindowCounter"},
+		{"counter window", func() { NewSlidingWindowCounter(3, -time.Second) }, "rate_limiting: non-positive window for NewSlidingWindowCounter"},
+		{"gcra interval", func() { NewGCRA(0, 3) }, "rate_limiting: non-positive interval for NewGCRA"},
+	} {
+		func() {
+			defer func() {
+				if got := recover(); got != tc.want {
+					t.Errorf("%s: panicked with %v, want %q", tc.name, got, tc.want)
+				}
+			}()
+			tc.new()
+		}()
+	}
+}
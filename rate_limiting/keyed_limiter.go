@@ -0,0 +1,390 @@
+package main
+
+import (
+	"container/list"
+	"context"
+	"sync"
+	"time"
+
+	"gobyexample/internal/clock"
+)
+
+/*
+	The requests channel in main treats all traffic as one stream, so one noisy client can use up everyone's budget.
+	KeyedLimiter keeps an independent token bucket per key, such as a client ID or an IP address.
+	A key is only a few fields, not a TokenBucket: like GCRA it works its tokens out from the time elapsed since it was last used, so there is no goroutine or ticker per key.
+	To bound memory it forgets keys that have been idle for longer than idleTTL, and the least recently used key once there are more than maxKeys.
+	main 의 requests 채널은 모든 트래픽을 하나의 흐름으로 다루기 때문에 시끄러운 클라이언트 하나가 모두의 예산을 다 써버릴 수 있다.
+	KeyedLimiter 는 클라이언트 ID 나 IP 주소 같은 key 마다 독립적인 token bucket 을 유지한다.
+	key 는 TokenBucket 이 아니라 몇 개의 필드일 뿐이다: GCRA 처럼 마지막으로 사용된 뒤 지난 시간으로부터 토큰을 계산하므로 key 마다 고루틴이나 ticker 가 없다.
+	메모리를 제한하기 위해서 idleTTL 보다 오래 쉬고 있는 key 와, key 가 maxKeys 개보다 많아지면 가장 오래 전에 사용된 key 를 잊어버린다.
+*/
+
+type keyLimit struct {
+	every time.Duration
+	burst int
+}
+
+/*
+	keyedEntry is one key's bucket.
+	last is the instant tokens was last brought up to date; the next token is due one interval after it.
+	keyedEntry 는 key 하나의 bucket 이다.
+	last 는 tokens 가 마지막으로 최신으로 맞춰진 순간이다; 다음 토큰은 그로부터 interval 하나 뒤에 온다.
+*/
+type keyedEntry struct {
+	key      string
+	limit    keyLimit
+	tokens   int
+	last     time.Time
+	lastSeen time.Time
+}
+
+/*
+	refill adds the tokens earned since last.
+	A full bucket earns nothing, so its interval restarts when the first token is taken.
+	refill 은 last 이후에 얻은 토큰들을 더한다.
+	가득 찬 bucket 은 아무것도 얻지 않으므로, 첫 토큰을 가져갈 때 interval 이 다시 시작한다.
+*/
+func (e *keyedEntry) refill(now time.Time) {
+	if e.tokens >= e.limit.burst {
+		e.tokens = e.limit.burst
+		e.last = now
+		return
+	}
+	n := now.Sub(e.last) / e.limit.every
+	if n <= 0 {
+		return
+	}
+	if n >= time.Duration(e.limit.burst-e.tokens) {
+		e.tokens = e.limit.burst
+		e.last = now
+		return
+	}
+	e.tokens += int(n)
+	e.last = e.last.Add(n * e.limit.every)
+}
+
+/*
+	untilNext is how long until the next token, or 0 when the bucket is full.
+	untilNext 는 다음 토큰까지 걸리는 시간이고, bucket 이 가득 차 있으면 0 이다.
+*/
+func (e *keyedEntry) untilNext(now time.Time) time.Duration {
+	if e.tokens >= e.limit.burst {
+		return 0
+	}
+	return max(e.last.Add(e.limit.every).Sub(now), 0)
+}
+
+type KeyedLimiter struct {
+	clock     clock.Clock
+	mu        sync.Mutex
+	limit     keyLimit
+	maxKeys   int
+	idleTTL   time.Duration
+	overrides map[string]keyLimit
+	entries   map[string]*list.Element
+	lru       *list.List
+
+	done     chan struct{}
+	stopOnce sync.Once
+}
+
+/*
+	NewKeyedLimiter gives every key its own bucket of one token every interval and up to burst saved up.
+	maxKeys <= 0 means no LRU bound and idleTTL <= 0 means keys never expire.
+	Like NewTokenBucket it panics on a non-positive interval.
+	NewKeyedLimiter 는 모든 key 에게 매 interval 마다 토큰 하나, 최대 burst 개까지 저장하는 자신만의 bucket 을 준다.
+	maxKeys <= 0 은 LRU 제한이 없다는 뜻이고 idleTTL <= 0 은 key 가 만료되지 않는다는 뜻이다.
+	NewTokenBucket 처럼 양수가 아닌 interval 에는 panic 한다.
+*/
+func NewKeyedLimiter(every time.Duration, burst, maxKeys int, idleTTL time.Duration) *KeyedLimiter {
+	return NewKeyedLimiterWithClock(clock.Real{}, every, burst, maxKeys, idleTTL)
+}
+
+/*
+	NewKeyedLimiterWithClock is NewKeyedLimiter on the given clock; every key's bucket shares it.
+	NewKeyedLimiterWithClock 은 주어진 clock 위의 NewKeyedLimiter 이다; 모든 key 의 bucket 이 그것을 공유한다.
+*/
+func NewKeyedLimiterWithClock(clk clock.Clock, every time.Duration, burst, maxKeys int, idleTTL time.Duration) *KeyedLimiter {
+	if every <= 0 {
+		panic("rate_limiting: non-positive interval for NewKeyedLimiter")
+	}
+	kl := &KeyedLimiter{
+		clock:     clk,
+		limit:     keyLimit{every: every, burst: max(burst, 1)},
+		maxKeys:   maxKeys,
+		idleTTL:   idleTTL,
+		overrides: make(map[string]keyLimit),
+		entries:   make(map[string]*list.Element),
+		lru:       list.New(),
+		done:      make(chan struct{}),
+	}
+	if idleTTL > 0 {
+		go kl.sweep()
+	}
+	return kl
+}
+
+/*
+	sweep evicts idle keys in the background, so keys nobody asks about again are still released.
+	It checks twice per idleTTL; a 1ns idleTTL still gets a positive interval.
+	sweep 은 백그라운드에서 쉬고 있는 key 들을 내보내서, 아무도 다시 묻지 않는 key 들도 해제되게 한다.
+	idleTTL 당 두 번 확인한다; 1ns 의 idleTTL 도 양수의 interval 을 갖는다.
+*/
+func (kl *KeyedLimiter) sweep() {
+	interval := kl.idleTTL / 2
+	if interval <= 0 {
+		interval = kl.idleTTL
+	}
+	ticker := kl.clock.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-kl.done:
+			return
+		case now := <-ticker.C():
+			kl.mu.Lock()
+			kl.evictIdle(now)
+			kl.mu.Unlock()
+		}
+	}
+}
+
+/*
+	evictIdle walks the LRU list from its oldest end; the first key that is still fresh ends the walk.
+	An idle key is only forgotten once its bucket has refilled, so coming back later with a full bucket gives it nothing it would not have had anyway.
+	evictIdle 은 LRU 리스트를 가장 오래된 끝에서부터 순회한다; 아직 신선한 첫번째 key 에서 순회가 끝난다.
+	쉬고 있는 key 는 bucket 이 다시 가득 찬 뒤에만 잊혀지므로, 나중에 가득 찬 bucket 으로 돌아와도 어차피 가졌을 것 이상을 얻지 않는다.
+*/
+func (kl *KeyedLimiter) evictIdle(now time.Time) {
+	for e := kl.lru.Back(); e != nil; {
+		entry := e.Value.(*keyedEntry)
+		if now.Sub(entry.lastSeen) < kl.idleTTL {
+			return
+		}
+		prev := e.Prev()
+		if entry.refill(now); entry.tokens >= entry.limit.burst {
+			kl.remove(e)
+		}
+		e = prev
+	}
+}
+
+func (kl *KeyedLimiter) remove(e *list.Element) {
+	entry := kl.lru.Remove(e).(*keyedEntry)
+	delete(kl.entries, entry.key)
+}
+
+/*
+	entry returns key's bucket brought up to now, creating it full on first use and marking it most recently used.
+	The caller holds kl.mu.
+	When maxKeys forces out a key whose bucket is not full, that key starts over with a full bucket; this is the price of the LRU bound.
+	entry 는 now 까지 최신으로 맞춘 key 의 bucket 을 반환하는데, 처음 사용할 때 가득 찬 상태로 만들고 가장 최근에 사용된 것으로 표시한다.
+	호출자가 kl.mu 를 잡고 있다.
+	maxKeys 때문에 bucket 이 가득 차지 않은 key 가 밀려나면, 그 key 는 가득 찬 bucket 으로 다시 시작한다; 이것이 LRU 제한의 대가이다.
+*/
+func (kl *KeyedLimiter) entry(key string, now time.Time) (*keyedEntry, error) {
+	select {
+	case <-kl.done:
+		return nil, ErrLimiterStopped
+	default:
+	}
+
+	if kl.idleTTL > 0 {
+		kl.evictIdle(now)
+	}
+	if e, ok := kl.entries[key]; ok {
+		entry := e.Value.(*keyedEntry)
+		entry.lastSeen = now
+		entry.refill(now)
+		kl.lru.MoveToFront(e)
+		return entry, nil
+	}
+
+	limit := kl.limit
+	if o, ok := kl.overrides[key]; ok {
+		limit = o
+	}
+	entry := &keyedEntry{key: key, limit: limit, tokens: limit.burst, last: now, lastSeen: now}
+	kl.entries[key] = kl.lru.PushFront(entry)
+	for kl.maxKeys > 0 && kl.lru.Len() > kl.maxKeys {
+		kl.remove(kl.lru.Back())
+	}
+	return entry, nil
+}
+
+/*
+	Allow reports whether a request for key may go through right now.
+	Allow 는 key 에 대한 요청이 지금 통과해도 되는지 알려준다.
+*/
+func (kl *KeyedLimiter) Allow(key string) bool {
+	kl.mu.Lock()
+	defer kl.mu.Unlock()
+	entry, err := kl.entry(key, kl.clock.Now())
+	if err != nil || entry.tokens <= 0 {
+		return false
+	}
+	entry.tokens--
+	return true
+}
+
+/*
+	Wait blocks until key has a token.
+	It reserves the token first and then sleeps out the delay, so the token is ours even if the key is evicted in the meantime;
+	giving up early hands the token back.
+	Wait 는 key 가 토큰을 가질 때까지 막힌다.
+	먼저 토큰을 예약하고 그 다음 지연 시간 동안 잠들기 때문에, 그 사이에 key 가 내보내지더라도 토큰은 우리 것이다;
+	일찍 포기하면 토큰을 돌려준다.
+*/
+func (kl *KeyedLimiter) Wait(ctx context.Context, key string) error {
+	r := kl.Reserve(key)
+	if !r.OK() {
+		return ErrLimiterStopped
+	}
+	if r.Delay() <= 0 {
+		return nil
+	}
+	timer := kl.clock.NewTimer(r.Delay())
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		r.Cancel()
+		return ctx.Err()
+	case <-kl.done:
+		return ErrLimiterStopped
+	case <-timer.C():
+		return nil
+	}
+}
+
+/*
+	keyStatus is what the HTTP middleware puts in its headers:
+	whether the request got a token, the tokens left, the burst, how long until the bucket is full again and how long until the next token.
+	keyStatus 는 HTTP 미들웨어가 헤더에 넣는 것이다:
+	요청이 토큰을 얻었는지, 남은 토큰, burst, bucket 이 다시 가득 찰 때까지 걸리는 시간 그리고 다음 토큰까지 걸리는 시간.
+*/
+type keyStatus struct {
+	ok                bool
+	remaining, burst  int
+	reset, retryAfter time.Duration
+}
+
+/*
+	allowStatus is Allow that also reports the key's status. Once the limiter is stopped it returns ErrLimiterStopped instead,
+	since a stopped limiter has no buckets left to report on.
+	allowStatus 는 key 의 상태도 알려주는 Allow 이다. limiter 가 멈춘 뒤에는 대신 ErrLimiterStopped 를 반환하는데,
+	멈춘 limiter 에는 알려줄 bucket 이 남아 있지 않기 때문이다.
+*/
+func (kl *KeyedLimiter) allowStatus(key string) (keyStatus, error) {
+	kl.mu.Lock()
+	defer kl.mu.Unlock()
+	now := kl.clock.Now()
+	entry, err := kl.entry(key, now)
+	if err != nil {
+		return keyStatus{}, err
+	}
+	s := keyStatus{burst: entry.limit.burst}
+	if entry.tokens > 0 {
+		entry.tokens--
+		s.ok = true
+	}
+	s.remaining = max(entry.tokens, 0)
+	s.retryAfter = entry.untilNext(now)
+	if entry.tokens < entry.limit.burst {
+		s.reset = s.retryAfter + time.Duration(entry.limit.burst-entry.tokens-1)*entry.limit.every
+	}
+	return s, nil
+}
+
+/*
+	Reserve takes a token for key even when there is none, like TokenBucket.Reserve.
+	Reserve 는 TokenBucket.Reserve 처럼 토큰이 없을 때에도 key 의 토큰을 가져간다.
+*/
+func (kl *KeyedLimiter) Reserve(key string) *Reservation {
+	kl.mu.Lock()
+	defer kl.mu.Unlock()
+	now := kl.clock.Now()
+	entry, err := kl.entry(key, now)
+	if err != nil {
+		return &Reservation{}
+	}
+	entry.tokens--
+	r := &Reservation{ok: true, cancel: func() {
+		kl.mu.Lock()
+		defer kl.mu.Unlock()
+		entry.refill(kl.clock.Now())
+		if entry.tokens < entry.limit.burst {
+			entry.tokens++
+		}
+	}}
+	if entry.tokens < 0 {
+		r.delay = entry.untilNext(now) + time.Duration(-entry.tokens-1)*entry.limit.every
+	}
+	return r
+}
+
+/*
+	SetOverride gives key its own rate and burst instead of the defaults.
+	A key that is already live is updated in place, keeping the tokens it has earned so far.
+	It panics on a non-positive interval, before any lock is taken.
+	SetOverride 는 key 에게 기본값 대신 자신만의 rate 와 burst 를 준다.
+	이미 살아있는 key 는 지금까지 얻은 토큰을 유지한 채 그 자리에서 갱신된다.
+	양수가 아닌 interval 에는 어떤 lock 도 잡기 전에 panic 한다.
+*/
+func (kl *KeyedLimiter) SetOverride(key string, every time.Duration, burst int) {
+	if every <= 0 {
+		panic("rate_limiting: non-positive interval for SetOverride")
+	}
+	limit := keyLimit{every: every, burst: max(burst, 1)}
+	kl.mu.Lock()
+	defer kl.mu.Unlock()
+	kl.overrides[key] = limit
+	kl.setLimit(key, limit)
+}
+
+/*
+	RemoveOverride puts key back on the default rate and burst.
+	RemoveOverride 는 key 를 기본 rate 와 burst 로 되돌린다.
+*/
+func (kl *KeyedLimiter) RemoveOverride(key string) {
+	kl.mu.Lock()
+	defer kl.mu.Unlock()
+	delete(kl.overrides, key)
+	kl.setLimit(key, kl.limit)
+}
+
+func (kl *KeyedLimiter) setLimit(key string, limit keyLimit) {
+	e, ok := kl.entries[key]
+	if !ok {
+		return
+	}
+	entry := e.Value.(*keyedEntry)
+	entry.refill(kl.clock.Now())
+	entry.limit = limit
+	entry.tokens = min(entry.tokens, limit.burst)
+}
+
+/*
+	Len returns the number of live keys.
+	Len 은 살아있는 key 의 개수를 반환한다.
+*/
+func (kl *KeyedLimiter) Len() int {
+	kl.mu.Lock()
+	defer kl.mu.Unlock()
+	return kl.lru.Len()
+}
+
+/*
+	Stop stops the sweeper, forgets every key and wakes up all waiters.
+	Stop 은 sweeper 를 멈추고, 모든 key 를 잊고, 기다리는 모두를 깨운다.
+*/
+func (kl *KeyedLimiter) Stop() {
+	kl.stopOnce.Do(func() {
+		kl.mu.Lock()
+		defer kl.mu.Unlock()
+		close(kl.done)
+		for e := kl.lru.Back(); e != nil; e = kl.lru.Back() {
+			kl.remove(e)
+		}
+	})
+}
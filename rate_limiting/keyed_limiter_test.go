@@ -0,0 +1,117 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"gobyexample/internal/clock"
+)
+
+/*
+	allows runs Allow for key n times and writes '+' for allowed and '-' for refused, like compareLimiters.
+	allows 는 key 에 대해 Allow 를 n 번 실행하고 허용은 '+', 거절은 '-' 로 쓴다. compareLimiters 처럼.
+*/
+func allows(kl *KeyedLimiter, key string, n int) string {
+	var b strings.Builder
+	for i := 0; i < n; i++ {
+		if kl.Allow(key) {
+			b.WriteByte('+')
+		} else {
+			b.WriteByte('-')
+		}
+	}
+	return b.String()
+}
+
+func TestKeyedLimiter(t *testing.T) {
+	start := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	for _, tc := range []struct {
+		name    string
+		every   time.Duration
+		burst   int
+		maxKeys int
+		idleTTL time.Duration
+		run     func(f *clock.Fake, kl *KeyedLimiter) string
+		want    string
+	}{
+		{"keys refill lazily and independently", 200 * time.Millisecond, 3, 0, 0, func(f *clock.Fake, kl *KeyedLimiter) string {
+			trace := allows(kl, "a", 4) + " " + allows(kl, "b", 1)
+			f.Advance(200 * time.Millisecond)
+			trace += " " + allows(kl, "a", 2)
+			f.Advance(time.Second)
+			return trace + " " + allows(kl, "a", 4)
+		}, "+++- + +- +++-"},
+
+		{"idle key is kept until its bucket refills", time.Second, 3, 0, 100 * time.Millisecond, func(f *clock.Fake, kl *KeyedLimiter) string {
+			trace := allows(kl, "a", 3)
+			f.Advance(500 * time.Millisecond)
+			trace += fmt.Sprint(" ", allows(kl, "b", 1), kl.Len(), allows(kl, "a", 1))
+			f.Advance(5 * time.Second)
+			return trace + fmt.Sprint(" ", allows(kl, "c", 1), kl.Len())
+		}, "+++ +2- +1"},
+
+		{"wait keeps its token while the key is idle", 200 * time.Millisecond, 1, 0, 50 * time.Millisecond, func(f *clock.Fake, kl *KeyedLimiter) string {
+			trace := allows(kl, "a", 1)
+			served := make(chan time.Duration)
+			go func() {
+				kl.Wait(context.Background(), "a")
+				served <- f.Since(start)
+			}()
+			f.BlockUntil(2)
+			f.Advance(100 * time.Millisecond)
+			trace += " " + allows(kl, "a", 1)
+			f.Advance(100 * time.Millisecond)
+			return trace + fmt.Sprint(" ", <-served, " ", allows(kl, "a", 1))
+		}, "+ - 200ms -"},
+
+		{"canceled wait hands its token back", 200 * time.Millisecond, 1, 0, 0, func(f *clock.Fake, kl *KeyedLimiter) string {
+			trace := allows(kl, "a", 1)
+			ctx, cancel := context.WithCancel(context.Background())
+			errc := make(chan error)
+			go func() { errc <- kl.Wait(ctx, "a") }()
+			f.BlockUntil(1)
+			cancel()
+			trace += fmt.Sprint(" ", <-errc)
+			f.Advance(200 * time.Millisecond)
+			return trace + " " + allows(kl, "a", 2)
+		}, "+ context canceled +-"},
+
+		{"override keeps earned tokens", time.Second, 3, 0, 0, func(f *clock.Fake, kl *KeyedLimiter) string {
+			trace := allows(kl, "a", 2)
+			kl.SetOverride("a", 100*time.Millisecond, 10)
+			trace += " " + allows(kl, "a", 2)
+			f.Advance(300 * time.Millisecond)
+			kl.RemoveOverride("a")
+			return trace + " " + allows(kl, "a", 4)
+		}, "++ +- +++-"},
+
+		{"bad override panics without holding the lock", time.Second, 1, 0, 0, func(f *clock.Fake, kl *KeyedLimiter) string {
+			func() {
+				defer func() { recover() }()
+				kl.SetOverride("a", 0, 1)
+			}()
+			return allows(kl, "a", 2)
+		}, "+-"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			f := clock.NewFake(start)
+			kl := NewKeyedLimiterWithClock(f, tc.every, tc.burst, tc.maxKeys, tc.idleTTL)
+			defer kl.Stop()
+			if got := tc.run(f, kl); got != tc.want {
+				t.Errorf("got %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
+
+/*
+	A tiny idleTTL used to halve to a zero sweep interval and panic in NewTicker.
+	아주 작은 idleTTL 은 sweep 간격을 0 으로 반으로 나눠 NewTicker 에서 panic 하곤 했다.
+*/
+func TestKeyedLimiterTinyIdleTTL(t *testing.T) {
+	kl := NewKeyedLimiter(time.Second, 1, 0, time.Nanosecond)
+	kl.Stop()
+}
@@ -0,0 +1,133 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"time"
+)
+
+/*
+	So far the limited requests were just ints on a channel and the only output was fmt.Println.
+	RateLimit wraps a KeyedLimiter as net/http middleware: requests over the limit get 429 Too Many Requests with Retry-After,
+	and every response carries RateLimit-Limit, RateLimit-Remaining and RateLimit-Reset so clients can pace themselves.
+	지금까지 제한된 요청들은 채널 위의 int 일 뿐이었고 유일한 출력은 fmt.Println 이었다.
+	RateLimit 은 KeyedLimiter 를 net/http 미들웨어로 감싼다: 제한을 넘은 요청은 Retry-After 와 함께 429 Too Many Requests 를 받고,
+	모든 응답은 클라이언트가 스스로 속도를 조절할 수 있도록 RateLimit-Limit, RateLimit-Remaining 그리고 RateLimit-Reset 을 갖는다.
+*/
+
+/*
+	KeyFunc picks the key a request is limited under.
+	KeyFunc 는 요청이 제한될 key 를 고른다.
+*/
+type KeyFunc func(r *http.Request) string
+
+/*
+	KeyByRemoteAddr limits each client IP separately.
+	KeyByRemoteAddr 는 각 클라이언트 IP 를 따로 제한한다.
+*/
+func KeyByRemoteAddr(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+/*
+	KeyByHeader limits by the value of a header such as X-API-Key, falling back to the remote address when it is missing.
+	KeyByHeader 는 X-API-Key 같은 헤더의 값으로 제한하고, 헤더가 없으면 원격 주소로 대신한다.
+*/
+func KeyByHeader(name string) KeyFunc {
+	return func(r *http.Request) string {
+		if v := r.Header.Get(name); v != "" {
+			return v
+		}
+		return KeyByRemoteAddr(r)
+	}
+}
+
+/*
+	RateLimit returns middleware that lets a request through to next only if its key has a token.
+	Once the limiter is stopped every request gets 503 Service Unavailable and no RateLimit headers, since there is no limit left to report.
+	RateLimit 은 요청의 key 가 토큰을 가진 경우에만 그 요청을 next 로 통과시키는 미들웨어를 반환한다.
+	limiter 가 멈춘 뒤에는 알려줄 제한이 남아 있지 않으므로 모든 요청은 RateLimit 헤더 없이 503 Service Unavailable 을 받는다.
+*/
+func RateLimit(limiter *KeyedLimiter, key KeyFunc) func(http.Handler) http.Handler {
+	if key == nil {
+		key = KeyByRemoteAddr
+	}
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			s, err := limiter.allowStatus(key(r))
+			if err != nil {
+				http.Error(w, http.StatusText(http.StatusServiceUnavailable), http.StatusServiceUnavailable)
+				return
+			}
+			h := w.Header()
+			h.Set("RateLimit-Limit", strconv.Itoa(s.burst))
+			h.Set("RateLimit-Remaining", strconv.Itoa(s.remaining))
+			h.Set("RateLimit-Reset", strconv.Itoa(seconds(s.reset)))
+			if !s.ok {
+				h.Set("Retry-After", strconv.Itoa(max(seconds(s.retryAfter), 1)))
+				http.Error(w, http.StatusText(http.StatusTooManyRequests), http.StatusTooManyRequests)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+/*
+	The headers carry whole seconds, rounded up so a client never retries too early.
+	헤더는 정수 초를 담으며, 클라이언트가 절대 너무 일찍 재시도하지 않도록 올림한다.
+*/
+func seconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
+
+/*
+	serveRateLimited runs the middleware end to end against a real listener with httptest.Server.
+	Client "a" sends 5 requests against a burst of 3, so the last 2 get 429, while client "b" is untouched.
+	TestRateLimit sends the same requests to an httptest.Server too, with the limiter on a fake clock, and checks the responses.
+	serveRateLimited 는 httptest.Server 로 실제 리스너에 대해 미들웨어를 처음부터 끝까지 실행한다.
+	클라이언트 "a" 는 burst 3 에 대해 5개의 요청을 보내므로 마지막 2개는 429 를 받고, 클라이언트 "b" 는 영향을 받지 않는다.
+	TestRateLimit 도 limiter 를 가짜 clock 위에 두고 같은 요청들을 httptest.Server 로 보내서, 응답들을 확인한다.
+*/
+func serveRateLimited() {
+	limiter := NewKeyedLimiter(time.Second, 3, 1000, time.Minute)
+	defer limiter.Stop()
+
+	hello := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintln(w, "hello")
+	})
+	srv := httptest.NewServer(RateLimit(limiter, KeyByHeader("X-Client-ID"))(hello))
+	defer srv.Close()
+
+	get := func(client string) {
+		req, err := http.NewRequest(http.MethodGet, srv.URL, nil)
+		if err != nil {
+			panic(err)
+		}
+		req.Header.Set("X-Client-ID", client)
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			panic(err)
+		}
+		io.Copy(io.Discard, resp.Body)
+		resp.Body.Close()
+		fmt.Println("client", client, resp.StatusCode,
+			"limit", resp.Header.Get("RateLimit-Limit"),
+			"remaining", resp.Header.Get("RateLimit-Remaining"),
+			"reset", resp.Header.Get("RateLimit-Reset"),
+			"retry-after", resp.Header.Get("Retry-After"))
+	}
+	for i := 0; i < 5; i++ {
+		get("a")
+	}
+	get("b")
+}
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"gobyexample/internal/clock"
+)
+
+/*
+	TestRateLimit sends the requests from serveRateLimited, and a few more, to an httptest.Server with http.Client, with the limiter on a fake clock.
+	Each step may advance the clock or stop the limiter first, and then checks the status and the rate limit headers of the response.
+	Every request comes from the loopback address, so the one without X-Client-ID is limited under that.
+	TestRateLimit 은 limiter 를 가짜 clock 위에 두고, serveRateLimited 의 요청들과 몇 개 더를 http.Client 로 httptest.Server 에 보낸다.
+	각 단계는 먼저 시계를 advance 하거나 limiter 를 멈출 수 있고, 그 다음 응답의 상태와 rate limit 헤더들을 확인한다.
+	모든 요청은 loopback 주소에서 오므로, X-Client-ID 가 없는 요청은 그 주소로 제한된다.
+*/
+func TestRateLimit(t *testing.T) {
+	fake := clock.NewFake(time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC))
+	limiter := NewKeyedLimiterWithClock(fake, time.Second, 3, 1000, time.Minute)
+	defer limiter.Stop()
+
+	hello := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, "hello")
+	})
+	srv := httptest.NewServer(RateLimit(limiter, KeyByHeader("X-Client-ID"))(hello))
+	defer srv.Close()
+	client := srv.Client()
+
+	for _, tc := range []struct {
+		name       string
+		advance    time.Duration
+		stop       bool
+		client     string
+		status     int
+		limit      string
+		remaining  string
+		reset      string
+		retryAfter string
+		keys       int
+	}{
+		{"a 1", 0, false, "a", http.StatusOK, "3", "2", "1", "", 1},
+		{"a 2", 0, false, "a", http.StatusOK, "3", "1", "2", "", 1},
+		{"a 3", 0, false, "a", http.StatusOK, "3", "0", "3", "", 1},
+		{"a 4 over the burst", 0, false, "a", http.StatusTooManyRequests, "3", "0", "3", "1", 1},
+		{"a 5 over the burst", 0, false, "a", http.StatusTooManyRequests, "3", "0", "3", "1", 1},
+		{"b is untouched", 0, false, "b", http.StatusOK, "3", "2", "1", "", 2},
+		{"no header falls back to the remote address", 0, false, "", http.StatusOK, "3", "2", "1", "", 3},
+		{"a 6 after one refill", time.Second, false, "a", http.StatusOK, "3", "0", "3", "", 3},
+		{"a 7 half way to the next token", 500 * time.Millisecond, false, "a", http.StatusTooManyRequests, "3", "0", "3", "1", 3},
+		{"stopped limiter", 0, true, "c", http.StatusServiceUnavailable, "", "", "", "", 0},
+	} {
+		fake.Advance(tc.advance)
+		if tc.stop {
+			limiter.Stop()
+		}
+		req, err := http.NewRequest(http.MethodGet, srv.URL, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if tc.client != "" {
+			req.Header.Set("X-Client-ID", tc.client)
+		}
+		resp, err := client.Do(req)
+		if err != nil {
+			t.Fatalf("%s: %v", tc.name, err)
+		}
+		body, err := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			t.Fatalf("%s: reading the body: %v", tc.name, err)
+		}
+
+		h := resp.Header
+		if resp.StatusCode != tc.status || h.Get("RateLimit-Limit") != tc.limit || h.Get("RateLimit-Remaining") != tc.remaining ||
+			h.Get("RateLimit-Reset") != tc.reset || h.Get("Retry-After") != tc.retryAfter {
+			t.Errorf("%s: got %d limit %q remaining %q reset %q retry-after %q, want %d limit %q remaining %q reset %q retry-after %q",
+				tc.name, resp.StatusCode, h.Get("RateLimit-Limit"), h.Get("RateLimit-Remaining"), h.Get("RateLimit-Reset"), h.Get("Retry-After"),
+				tc.status, tc.limit, tc.remaining, tc.reset, tc.retryAfter)
+		}
+		if tc.status == http.StatusOK && string(body) != "hello" {
+			t.Errorf("%s: body %q, want the handler's", tc.name, body)
+		}
+		if tc.status != http.StatusOK && string(body) == "hello" {
+			t.Errorf("%s: the handler ran", tc.name)
+		}
+		if got := limiter.Len(); got != tc.keys {
+			t.Errorf("%s: %d live keys, want %d", tc.name, got, tc.keys)
+		}
+	}
+}
@@ -1,8 +1,11 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"time"
+
+	"gobyexample/internal/clock"
 )
 
 /*
@@ -13,6 +16,12 @@ import (
 */
 
 func main() {
+	/*
+		All the timing below goes through a Clock, so the tests can replay it on a fake clock.
+		아래의 모든 타이밍은 Clock 을 거치기 때문에 테스트가 가짜 clock 위에서 그것을 재생할 수 있다.
+	*/
+	var clk clock.Clock = clock.Real{}
+
 	/*
 		First we'll look at basic rate limiting.
 		Suppose we want to limit our handling of incoming requests.
@@ -33,7 +42,7 @@ func main() {
 		이 limter 채널은 매 200ms 마다 값을 받을 것이다.
 		이 것은 우리의 rate limiting 스키마 내에 regulator이다.
 	*/
-	limiter := time.Tick(200 * time.Millisecond)
+	limiter := clk.Tick(200 * time.Millisecond)
 
 	/*
 		By blocking on a receive from the limiter channel before serving each request, we limit ourselves to 1 request every 200 milliseconds.
@@ -41,7 +50,7 @@ func main() {
 	*/
 	for req := range requests {
 		<-limiter
-		fmt.Println("request ", req, time.Now())
+		fmt.Println("request ", req, clk.Now())
 	}
 
 	/*
@@ -59,7 +68,7 @@ func main() {
 		허용된 burst를 표현하기 위해서 채널을 채우자.
 	*/
 	for i := 0; i < 3; i++ {
-		burstyLimiter <- time.Now()
+		burstyLimiter <- clk.Now()
 	}
 
 	/*
@@ -67,7 +76,7 @@ func main() {
 		매 200ms마다 우리는 burstyLimiter 에 최대 3개의 새로운 값을 추가하기를 시도할 것이다.
 	*/
 	go func() {
-		for t := range time.Tick(200 * time.Millisecond) {
+		for t := range clk.Tick(200 * time.Millisecond) {
 			burstyLimiter <- t
 		}
 	}()
@@ -85,7 +94,7 @@ func main() {
 	close(burstyRequests)
 	for req := range burstyRequests {
 		<-burstyLimiter
-		fmt.Println("request ", req, time.Now())
+		fmt.Println("request ", req, clk.Now())
 	}
 
 	/*
@@ -97,4 +106,73 @@ func main() {
 		For the second batch of requests we serve the first 3 immediately because of the burstable rate limiting, then serve the remaining 2 with ~200ms delays each.
 		두 번째 요청의 경우 버스트 가능 속도 제한 때문에 첫 번째 3개 요청을 즉시 처리한 후 나머지 2개 요청을 각각 200ms씩 지연시킨다.
 	*/
+
+	/*
+		The goroutine refilling burstyLimiter keeps running until the program exits.
+		TokenBucket gives the same behaviour with a rate and burst we can change, and Stop releases its refill goroutine.
+		burstyLimiter 를 채우는 고루틴은 프로그램이 끝날 때까지 계속 실행된다.
+		TokenBucket 은 바꿀 수 있는 rate 와 burst 로 같은 동작을 제공하고, Stop 은 refill 고루틴을 해제한다.
+	*/
+	bucket := NewTokenBucketWithClock(clk, 200*time.Millisecond, 3)
+	ctx := context.Background()
+	for i := 1; i <= 5; i++ {
+		if err := bucket.Wait(ctx); err != nil {
+			fmt.Println("wait: ", err)
+			break
+		}
+		fmt.Println("bucket request ", i, clk.Now())
+	}
+
+	/*
+		Reserve takes a token without blocking and says how long to wait for it, and Allow never waits at all.
+		Reserve 는 막히지 않고 토큰을 가져가며 얼마나 기다려야 하는지 알려주고, Allow 는 전혀 기다리지 않는다.
+	*/
+	r := bucket.Reserve()
+	fmt.Println("reserved, delay ", r.Delay().Round(time.Millisecond))
+	r.Cancel()
+	fmt.Println("allow: ", bucket.Allow())
+
+	/*
+		After Stop, Wait returns ErrLimiterStopped instead of blocking forever.
+		Stop 뒤에는 Wait 가 영원히 막히는 대신 ErrLimiterStopped 를 반환한다.
+	*/
+	bucket.Stop()
+	fmt.Println("after stop: ", bucket.Wait(ctx))
+
+	/*
+		KeyedLimiter gives every client its own bucket, so a noisy client only uses up its own budget.
+		Here "noisy" sends 5 requests at once and only its burst of 3 goes through, while "quiet" is still allowed.
+		KeyedLimiter 는 모든 클라이언트에게 자신만의 bucket 을 주기 때문에 시끄러운 클라이언트는 자신의 예산만 다 써버린다.
+		여기서 "noisy" 는 한번에 5개의 요청을 보내고 burst 인 3개만 통과하지만, "quiet" 는 여전히 허용된다.
+	*/
+	clients := NewKeyedLimiterWithClock(clk, 200*time.Millisecond, 3, 100, time.Minute)
+	defer clients.Stop()
+	for i := 1; i <= 5; i++ {
+		fmt.Println("noisy request ", i, clients.Allow("noisy"))
+	}
+	fmt.Println("quiet request ", 1, clients.Allow("quiet"))
+	clients.SetOverride("vip", 50*time.Millisecond, 10)
+	fmt.Println("live keys: ", clients.Len())
+
+	/*
+		TokenBucket is only one algorithm behind the Limiter interface.
+		Here the same bursty trace is replayed against a sliding window log, a sliding window counter and GCRA, one row each ('+' allowed, '-' refused).
+		TokenBucket 은 Limiter 인터페이스 뒤의 하나의 알고리즘일 뿐이다.
+		여기서 같은 bursty 기록을 sliding window log, sliding window counter 그리고 GCRA 에 대해 재생하며, 각각 한 줄씩 보여준다('+' 허용, '-' 거절).
+	*/
+	compareLimiters()
+
+	/*
+		Finally the same per-client limiting as HTTP middleware, with 429 and the standard RateLimit headers.
+		마지막으로 같은 클라이언트별 제한을 429 와 표준 RateLimit 헤더와 함께 HTTP 미들웨어로 해보자.
+	*/
+	serveRateLimited()
+
+	/*
+		Every limiter so far uses a fixed rate.
+		AdaptiveLimiter instead limits calls in flight and shrinks that limit when the downstream gets slow or starts failing.
+		지금까지의 모든 limiter 는 고정된 rate 를 사용한다.
+		AdaptiveLimiter 는 대신 진행 중인 호출을 제한하고, downstream 이 느려지거나 실패하기 시작하면 그 제한을 줄인다.
+	*/
+	simulateAdaptive()
 }
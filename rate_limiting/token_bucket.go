@@ -0,0 +1,261 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"gobyexample/internal/clock"
+)
+
+/*
+	The burstyLimiter in main is a buffered channel refilled by a time.Tick goroutine.
+	That goroutine can never be stopped and the capacity of 3 is fixed when the channel is made.
+	TokenBucket keeps the same idea - one token every interval, up to burst tokens saved up - but the rate and burst can be changed at runtime and Stop releases the refill goroutine.
+	main 의 burstyLimiter 는 time.Tick 고루틴이 채우는 buffered 채널이다.
+	그 고루틴은 절대 멈출 수 없고 용량 3은 채널을 만들 때 고정된다.
+	TokenBucket 은 같은 아이디어(매 interval 마다 토큰 하나, 최대 burst 개까지 저장)를 유지하지만 rate 와 burst 를 실행 중에 바꿀 수 있고 Stop 이 refill 고루틴을 해제한다.
+*/
+
+/*
+	ErrLimiterStopped is returned by Wait once the limiter has been stopped.
+	ErrLimiterStopped 는 limiter 가 멈춘 뒤에 Wait 가 반환한다.
+*/
+var ErrLimiterStopped = errors.New("rate limiter stopped")
+
+type TokenBucket struct {
+	clock    clock.Clock
+	mu       sync.Mutex
+	every    time.Duration
+	burst    int
+	tokens   int
+	next     time.Time
+	refilled chan struct{}
+
+	ticker   clock.Ticker
+	done     chan struct{}
+	stopOnce sync.Once
+}
+
+/*
+	NewTokenBucket returns a limiter that hands out one token every interval and saves up to burst of them.
+	Like the burstyLimiter, the bucket starts full so the first burst requests go through immediately.
+	NewTokenBucket 은 매 interval 마다 토큰 하나를 나눠주고 최대 burst 개까지 저장하는 limiter 를 반환한다.
+	burstyLimiter 처럼 bucket 은 가득 찬 상태로 시작하므로 처음 burst 개의 요청은 즉시 통과한다.
+*/
+func NewTokenBucket(every time.Duration, burst int) *TokenBucket {
+	return NewTokenBucketWithClock(clock.Real{}, every, burst)
+}
+
+/*
+	NewTokenBucketWithClock is NewTokenBucket on the given clock, so a FakeClock can drive the refills.
+	NewTokenBucketWithClock 은 주어진 clock 위의 NewTokenBucket 이라서 FakeClock 이 refill 을 움직일 수 있다.
+*/
+func NewTokenBucketWithClock(clk clock.Clock, every time.Duration, burst int) *TokenBucket {
+	if every <= 0 {
+		panic("rate_limiting: non-positive interval for NewTokenBucket")
+	}
+	if burst < 1 {
+		burst = 1
+	}
+	tb := &TokenBucket{
+		clock:    clk,
+		every:    every,
+		burst:    burst,
+		tokens:   burst,
+		next:     clk.Now().Add(every),
+		refilled: make(chan struct{}),
+		ticker:   clk.NewTicker(every),
+		done:     make(chan struct{}),
+	}
+	go tb.refill()
+	return tb
+}
+
+/*
+	refill is the goroutine that replaces `for t := range time.Tick(...)`.
+	It adds one token per tick and wakes up everyone blocked in Wait.
+	refill 은 `for t := range time.Tick(...)` 을 대체하는 고루틴이다.
+	tick 마다 토큰을 하나 추가하고 Wait 에서 막혀있는 모두를 깨운다.
+*/
+func (tb *TokenBucket) refill() {
+	for {
+		select {
+		case <-tb.done:
+			return
+		case t := <-tb.ticker.C():
+			tb.mu.Lock()
+			if tb.tokens < tb.burst {
+				tb.tokens++
+			}
+			tb.next = t.Add(tb.every)
+			close(tb.refilled)
+			tb.refilled = make(chan struct{})
+			tb.mu.Unlock()
+		}
+	}
+}
+
+/*
+	Allow takes a token if one is available right now and reports whether it did.
+	Allow 는 지금 사용 가능한 토큰이 있으면 가져가고 가져갔는지를 알려준다.
+*/
+func (tb *TokenBucket) Allow() bool {
+	tb.mu.Lock()
+	defer tb.mu.Unlock()
+	if tb.stopped() || tb.tokens <= 0 {
+		return false
+	}
+	tb.tokens--
+	return true
+}
+
+/*
+	Wait blocks until a token is available, ctx is done or the limiter is stopped.
+	Wait 는 토큰이 사용 가능해지거나, ctx 가 끝나거나, limiter 가 멈출 때까지 막힌다.
+*/
+func (tb *TokenBucket) Wait(ctx context.Context) error {
+	for {
+		tb.mu.Lock()
+		if tb.stopped() {
+			tb.mu.Unlock()
+			return ErrLimiterStopped
+		}
+		if tb.tokens > 0 {
+			tb.tokens--
+			tb.mu.Unlock()
+			return nil
+		}
+		refilled := tb.refilled
+		tb.mu.Unlock()
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-tb.done:
+			return ErrLimiterStopped
+		case <-refilled:
+		}
+	}
+}
+
+/*
+	Reservation is a token taken in advance.
+	Delay says how long the caller has to wait before acting on it.
+	Reservation 은 미리 가져간 토큰이다.
+	Delay 는 호출자가 그것을 사용하기 전에 얼마나 기다려야 하는지 말해준다.
+*/
+type Reservation struct {
+	ok     bool
+	delay  time.Duration
+	cancel func()
+}
+
+/*
+	OK reports whether the reservation was made; it is false once the limiter is stopped.
+	OK 는 예약이 만들어졌는지 알려준다; limiter 가 멈추면 false 이다.
+*/
+func (r *Reservation) OK() bool {
+	return r.ok
+}
+
+func (r *Reservation) Delay() time.Duration {
+	return r.delay
+}
+
+/*
+	Cancel gives the reserved token back, for callers that decide not to wait after all.
+	Cancel 은 결국 기다리지 않기로 한 호출자를 위해 예약된 토큰을 돌려준다.
+*/
+func (r *Reservation) Cancel() {
+	if !r.ok {
+		return
+	}
+	r.ok = false
+	r.cancel()
+}
+
+/*
+	Reserve always takes a token, letting the bucket go into debt when it is empty.
+	The returned delay is when that token will actually have been refilled.
+	Reserve 는 항상 토큰을 가져가며, bucket 이 비어있으면 빚을 지게 한다.
+	반환된 delay 는 그 토큰이 실제로 다시 채워지는 시점이다.
+*/
+func (tb *TokenBucket) Reserve() *Reservation {
+	tb.mu.Lock()
+	defer tb.mu.Unlock()
+	if tb.stopped() {
+		return &Reservation{}
+	}
+	tb.tokens--
+	r := &Reservation{ok: true, cancel: func() {
+		tb.mu.Lock()
+		defer tb.mu.Unlock()
+		if tb.tokens < tb.burst {
+			tb.tokens++
+		}
+	}}
+	if tb.tokens < 0 {
+		r.delay = tb.clock.Until(tb.next) + time.Duration(-tb.tokens-1)*tb.every
+		if r.delay < 0 {
+			r.delay = 0
+		}
+	}
+	return r
+}
+
+/*
+	SetRate changes how often a token is added.
+	SetRate 는 토큰이 추가되는 주기를 바꾼다.
+*/
+func (tb *TokenBucket) SetRate(every time.Duration) {
+	if every <= 0 {
+		panic("rate_limiting: non-positive interval for SetRate")
+	}
+	tb.mu.Lock()
+	defer tb.mu.Unlock()
+	tb.every = every
+	tb.next = tb.clock.Now().Add(every)
+	tb.ticker.Reset(every)
+}
+
+/*
+	SetBurst changes how many tokens can be saved up.
+	Shrinking the burst drops the tokens that no longer fit.
+	SetBurst 는 저장할 수 있는 토큰의 개수를 바꾼다.
+	burst 를 줄이면 더 이상 들어가지 않는 토큰들은 버려진다.
+*/
+func (tb *TokenBucket) SetBurst(burst int) {
+	if burst < 1 {
+		burst = 1
+	}
+	tb.mu.Lock()
+	defer tb.mu.Unlock()
+	tb.burst = burst
+	if tb.tokens > burst {
+		tb.tokens = burst
+	}
+}
+
+/*
+	Stop stops the ticker and the refill goroutine and wakes up all waiters.
+	It is safe to call more than once.
+	Stop 은 ticker 와 refill 고루틴을 멈추고 기다리는 모두를 깨운다.
+	여러 번 호출해도 안전하다.
+*/
+func (tb *TokenBucket) Stop() {
+	tb.stopOnce.Do(func() {
+		tb.ticker.Stop()
+		close(tb.done)
+	})
+}
+
+func (tb *TokenBucket) stopped() bool {
+	select {
+	case <-tb.done:
+		return true
+	default:
+		return false
+	}
+}
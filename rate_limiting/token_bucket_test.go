@@ -0,0 +1,42 @@
+package main
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"gobyexample/internal/clock"
+)
+
+/*
+	TestTokenBucket runs the burstyRequests loop from main against a bucket on a fake clock.
+	The requester reports the fake time of every request it serves, so the test only advances the clock once the previous request is in:
+	3 requests at 0ms from the burst, then one at 200ms and one at 400ms.
+	TestTokenBucket 은 main 의 burstyRequests 반복문을 가짜 clock 위의 bucket 에 대해 실행한다.
+	요청하는 쪽은 처리한 모든 요청의 가짜 시간을 알려주므로, 테스트는 이전 요청이 들어온 뒤에만 시계를 advance 한다:
+	burst 에서 0ms 에 3개의 요청, 그 다음 200ms 에 하나 그리고 400ms 에 하나.
+*/
+func TestTokenBucket(t *testing.T) {
+	start := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	fake := clock.NewFake(start)
+	bucket := NewTokenBucketWithClock(fake, 200*time.Millisecond, 3)
+	defer bucket.Stop()
+
+	served := make(chan time.Duration)
+	go func() {
+		for i := 1; i <= 5; i++ {
+			bucket.Wait(context.Background())
+			served <- fake.Since(start)
+		}
+	}()
+
+	want := []time.Duration{0, 0, 0, 200 * time.Millisecond, 400 * time.Millisecond}
+	for i, w := range want {
+		if i >= 3 {
+			fake.Advance(200 * time.Millisecond)
+		}
+		if got := <-served; got != w {
+			t.Errorf("request %d served at %v, want %v", i+1, got, w)
+		}
+	}
+}
@@ -0,0 +1,115 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"math/rand"
+	"os"
+	"runtime"
+	"testing"
+	"text/tabwriter"
+)
+
+/*
+	The atomic, mutexes and stateful_goroutines examples each print an operation count after sleeping for a second.
+	Those counts are noisy and depend on the machine, so they cannot tell us which strategy to pick.
+	Here the same strategies run as testing.B benchmarks, and main prints a table of ns/op for every combination of
+	reader/writer ratio, number of keys and GOMAXPROCS. BenchmarkStores in state_management_test.go runs the same benchmarks under go test.
+	atomic, mutexes 그리고 stateful_goroutines 예제는 각각 1초 동안 잠든 후에 연산 횟수를 출력한다.
+	그 횟수들은 잡음이 많고 기계에 따라 달라서 어떤 전략을 골라야 할지 알려줄 수 없다.
+	여기서는 같은 전략들을 testing.B 벤치마크로 실행하고, main 은 reader/writer 비율, key 의 개수 그리고 GOMAXPROCS 의
+	모든 조합에 대해 ns/op 표를 출력한다. state_management_test.go 의 BenchmarkStores 는 같은 벤치마크들을 go test 아래에서 실행한다.
+*/
+
+var strategies = []struct {
+	name string
+	new  func(keys int) store
+}{
+	{"mutex", newMutexStore},
+	{"rwmutex", newRWMutexStore},
+	{"atomic", newAtomicStore},
+	{"goroutine", newGoroutineStore},
+}
+
+var (
+	ratios    = []int{90, 50, 10}
+	keyCounts = []int{5, 1024}
+)
+
+/*
+	op is one step of the workload: a read readPercent of the time and a write otherwise, on a random one of keys keys.
+	op 은 작업의 한 단계이다: keys 개의 key 중 임의의 하나에 대해 readPercent 만큼 읽고 나머지는 쓴다.
+*/
+func op(s store, readPercent, keys int) int {
+	key := rand.Intn(keys)
+	if rand.Intn(100) < readPercent {
+		return s.get(key)
+	}
+	s.set(key, key)
+	return 0
+}
+
+/*
+	benchmarkStore returns a benchmark in which every parallel goroutine runs op over keys keys.
+	benchmarkStore 는 모든 병렬 고루틴이 keys 개의 key 에 대해 op 을 실행하는 벤치마크를 반환한다.
+*/
+func benchmarkStore(newStore func(keys int) store, readPercent, keys int) func(b *testing.B) {
+	return func(b *testing.B) {
+		s := newStore(keys)
+		defer s.close()
+		b.ReportAllocs()
+		b.RunParallel(func(pb *testing.PB) {
+			total := 0
+			for pb.Next() {
+				total += op(s, readPercent, keys)
+			}
+		})
+	}
+}
+
+func main() {
+	/*
+		testing.Init registers the -test.* flags, so -test.benchtime works here just like with go test.
+		We default to a shorter benchtime since there are many combinations to run.
+		testing.Init 은 -test.* 플래그들을 등록하므로 -test.benchtime 이 go test 에서처럼 여기서도 동작한다.
+		실행할 조합이 많기 때문에 기본 benchtime 을 더 짧게 한다.
+	*/
+	testing.Init()
+	flag.Set("test.benchtime", "200ms")
+	flag.Parse()
+
+	procs := []int{1, runtime.NumCPU()}
+	if procs[1] == 1 {
+		procs = procs[:1]
+	}
+	defer runtime.GOMAXPROCS(runtime.GOMAXPROCS(0))
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprint(w, "reads%\tkeys\tprocs\t")
+	for _, st := range strategies {
+		fmt.Fprintf(w, "%s ns/op\t", st.name)
+	}
+	fmt.Fprintln(w)
+
+	for _, p := range procs {
+		runtime.GOMAXPROCS(p)
+		for _, keys := range keyCounts {
+			for _, ratio := range ratios {
+				fmt.Fprintf(w, "%d\t%d\t%d\t", ratio, keys, p)
+				for _, st := range strategies {
+					r := testing.Benchmark(benchmarkStore(st.new, ratio, keys))
+					fmt.Fprintf(w, "%d\t", r.NsPerOp())
+				}
+				fmt.Fprintln(w)
+			}
+		}
+	}
+	w.Flush()
+
+	/*
+		Lower is better. On most machines atomics win whenever they are an option, the RWMutex pulls ahead of the Mutex as reads dominate and procs grow,
+		and the goroutine owner pays for two channel operations per request - which is the price of its simpler reasoning, not a reason to avoid it.
+		낮을수록 좋다. 대부분의 기계에서 atomic 은 선택지가 될 때마다 이기고, 읽기가 많아지고 procs 가 늘어날수록 RWMutex 가 Mutex 를 앞서며,
+		고루틴 소유자는 요청마다 두 번의 채널 연산 비용을 치른다 - 이것은 더 단순한 추론의 대가이지 그것을 피할 이유는 아니다.
+	*/
+}
@@ -0,0 +1,41 @@
+package main
+
+import (
+	"fmt"
+	"testing"
+)
+
+/*
+	BenchmarkStores runs the benchmarks from main's table as sub-benchmarks such as mutex/reads=90/keys=5.
+	GOMAXPROCS comes from go test's -cpu flag, so `go test -bench . -cpu 1,4` gives the whole table.
+	BenchmarkStores 는 main 의 표에 있는 벤치마크들을 mutex/reads=90/keys=5 같은 하위 벤치마크로 실행한다.
+	GOMAXPROCS 는 go test 의 -cpu 플래그에서 오므로, `go test -bench . -cpu 1,4` 가 전체 표를 준다.
+*/
+func BenchmarkStores(b *testing.B) {
+	for _, st := range strategies {
+		for _, keys := range keyCounts {
+			for _, ratio := range ratios {
+				b.Run(fmt.Sprintf("%s/reads=%d/keys=%d", st.name, ratio, keys), benchmarkStore(st.new, ratio, keys))
+			}
+		}
+	}
+}
+
+/*
+	TestStores checks that every strategy reads back what was written, so the benchmarks compare stores that actually work.
+	TestStores 는 모든 전략이 쓴 것을 다시 읽어오는지 확인해서, 벤치마크가 실제로 동작하는 store 들을 비교하게 한다.
+*/
+func TestStores(t *testing.T) {
+	for _, st := range strategies {
+		s := st.new(8)
+		for k := 0; k < 8; k++ {
+			s.set(k, k*k)
+		}
+		for k := 0; k < 8; k++ {
+			if got := s.get(k); got != k*k {
+				t.Errorf("%s: get(%d) = %d, want %d", st.name, k, got, k*k)
+			}
+		}
+		s.close()
+	}
+}
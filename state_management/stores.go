@@ -0,0 +1,156 @@
+package main
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+/*
+	These are the state management strategies from the atomic, mutexes and stateful_goroutines examples, behind one small interface so they can be benchmarked side by side.
+	The state is a fixed set of int keys, like the map[int]int in those examples.
+	이것들은 atomic, mutexes 그리고 stateful_goroutines 예제의 상태 관리 전략들로, 나란히 벤치마크할 수 있도록 하나의 작은 인터페이스 뒤에 있다.
+	상태는 그 예제들의 map[int]int 처럼 고정된 int key 집합이다.
+*/
+type store interface {
+	get(key int) int
+	set(key, val int)
+	close()
+}
+
+/*
+	mutexStore is the mutexes example: one map, one sync.Mutex for readers and writers alike.
+	mutexStore 는 mutexes 예제이다: 하나의 map, reader 와 writer 모두를 위한 하나의 sync.Mutex.
+*/
+type mutexStore struct {
+	mu    sync.Mutex
+	state map[int]int
+}
+
+func newMutexStore(keys int) store {
+	return &mutexStore{state: make(map[int]int, keys)}
+}
+
+func (s *mutexStore) get(key int) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.state[key]
+}
+
+func (s *mutexStore) set(key, val int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.state[key] = val
+}
+
+func (s *mutexStore) close() {}
+
+/*
+	rwMutexStore lets readers share the lock, and only writers take it exclusively.
+	rwMutexStore 는 reader 들이 lock 을 공유하게 하고, writer 들만 배타적으로 가져간다.
+*/
+type rwMutexStore struct {
+	mu    sync.RWMutex
+	state map[int]int
+}
+
+func newRWMutexStore(keys int) store {
+	return &rwMutexStore{state: make(map[int]int, keys)}
+}
+
+func (s *rwMutexStore) get(key int) int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.state[key]
+}
+
+func (s *rwMutexStore) set(key, val int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.state[key] = val
+}
+
+func (s *rwMutexStore) close() {}
+
+/*
+	atomicStore is the atomic example's counter, one per key.
+	It only works because the keys are known up front, which is exactly when atomics are an option.
+	atomicStore 는 atomic 예제의 카운터를 key 마다 하나씩 둔 것이다.
+	key 가 미리 알려져 있기 때문에만 동작하는데, 그게 바로 atomic 이 선택지가 되는 경우이다.
+*/
+type atomicStore struct {
+	state []atomic.Int64
+}
+
+func newAtomicStore(keys int) store {
+	return &atomicStore{state: make([]atomic.Int64, keys)}
+}
+
+func (s *atomicStore) get(key int) int {
+	return int(s.state[key].Load())
+}
+
+func (s *atomicStore) set(key, val int) {
+	s.state[key].Store(int64(val))
+}
+
+func (s *atomicStore) close() {}
+
+/*
+	goroutineStore is the stateful_goroutines example: the map is owned by one goroutine and everyone else sends it readOps and writeOps.
+	goroutineStore 는 stateful_goroutines 예제이다: map 은 하나의 고루틴이 소유하고 다른 모두는 그것에게 readOp 과 writeOp 을 보낸다.
+*/
+type readOp struct {
+	key  int
+	resp chan int
+}
+
+type writeOp struct {
+	key  int
+	val  int
+	resp chan bool
+}
+
+type goroutineStore struct {
+	reads  chan readOp
+	writes chan writeOp
+	done   chan struct{}
+}
+
+func newGoroutineStore(keys int) store {
+	s := &goroutineStore{
+		reads:  make(chan readOp),
+		writes: make(chan writeOp),
+		done:   make(chan struct{}),
+	}
+	go func() {
+		var state = make(map[int]int, keys)
+		for {
+			select {
+			case read := <-s.reads:
+				read.resp <- state[read.key]
+			case write := <-s.writes:
+				state[write.key] = write.val
+				write.resp <- true
+			case <-s.done:
+				return
+			}
+		}
+	}()
+	return s
+}
+
+func (s *goroutineStore) get(key int) int {
+	read := readOp{key: key, resp: make(chan int)}
+	s.reads <- read
+	return <-read.resp
+}
+
+func (s *goroutineStore) set(key, val int) {
+	write := writeOp{key: key, val: val, resp: make(chan bool)}
+	s.writes <- write
+	<-write.resp
+}
+
+func (s *goroutineStore) close() {
+	close(s.done)
+}
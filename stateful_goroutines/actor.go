@@ -0,0 +1,225 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+/*
+	The owner goroutine in main is a select loop over the reads and writes channels, written out by hand.
+	Actor is that loop once and for all: one goroutine owns whatever handle closes over, and everyone else talks to it through a typed mailbox.
+	The mailbox is bounded, so when the owner falls behind senders block (or fail with TrySend) instead of piling up messages without limit.
+	main 의 소유자 고루틴은 손으로 작성한 reads 와 writes 채널 위의 select 반복문이다.
+	Actor 는 그 반복문을 한 번에 정리한 것이다: 하나의 고루틴이 handle 이 감싸는 것을 소유하고, 다른 모두는 타입이 있는 mailbox 를 통해 그것과 이야기한다.
+	mailbox 는 크기가 제한되어 있어서, 소유자가 뒤처지면 보내는 쪽은 메시지를 한없이 쌓는 대신 막힌다(또는 TrySend 로 실패한다).
+*/
+
+var (
+	ErrActorStopped = errors.New("actor stopped")
+	ErrActorCrashed = errors.New("actor crashed")
+	ErrMailboxFull  = errors.New("actor mailbox full")
+)
+
+type Actor[M any] struct {
+	mailbox chan M
+	handle  func(msg M)
+
+	mu       sync.RWMutex
+	closed   bool
+	stopping chan struct{}
+	done     chan struct{}
+
+	errMu sync.Mutex
+	err   error
+
+	startOnce sync.Once
+	stopOnce  sync.Once
+}
+
+/*
+	NewActor makes an actor with room for size messages; it does not run until Start.
+	NewActor 는 size 개의 메시지를 위한 자리를 가진 actor 를 만든다; Start 전까지는 실행되지 않는다.
+*/
+func NewActor[M any](size int, handle func(msg M)) *Actor[M] {
+	return &Actor[M]{
+		mailbox:  make(chan M, size),
+		handle:   handle,
+		stopping: make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+}
+
+/*
+	Start runs the owner goroutine. Calling it more than once does nothing.
+	Start 는 소유자 고루틴을 실행한다. 두 번 이상 호출해도 아무 일도 하지 않는다.
+*/
+func (a *Actor[M]) Start() {
+	a.startOnce.Do(func() {
+		go a.run()
+	})
+}
+
+/*
+	If handle panics the owner is gone for good, so instead of leaving everyone blocked on it
+	run closes the mailbox the same way Stop does and records the panic for Err and Ask to report.
+	handle 이 panic 하면 소유자는 영영 사라지므로, 모두를 그것에 막힌 채로 두는 대신
+	run 은 Stop 과 같은 방식으로 mailbox 를 닫고 Err 와 Ask 가 알려줄 수 있도록 panic 을 기록한다.
+*/
+func (a *Actor[M]) run() {
+	defer close(a.done)
+	defer func() {
+		if r := recover(); r != nil {
+			a.errMu.Lock()
+			a.err = fmt.Errorf("%w: %v", ErrActorCrashed, r)
+			a.errMu.Unlock()
+			a.close()
+		}
+	}()
+	for msg := range a.mailbox {
+		a.handle(msg)
+	}
+}
+
+/*
+	Closing stopping first wakes senders blocked on a full mailbox, so they release the read lock and we can close the mailbox.
+	stopping 을 먼저 닫으면 가득 찬 mailbox 에서 막힌 보내는 쪽들이 깨어나서 읽기 잠금을 풀기 때문에 mailbox 를 닫을 수 있다.
+*/
+func (a *Actor[M]) close() {
+	a.stopOnce.Do(func() {
+		close(a.stopping)
+		a.mu.Lock()
+		a.closed = true
+		close(a.mailbox)
+		a.mu.Unlock()
+	})
+}
+
+/*
+	Send puts msg in the mailbox, blocking while it is full.
+	It gives up with ctx's error or with ErrActorStopped once Stop has been called.
+	Send 는 msg 를 mailbox 에 넣으며, mailbox 가 가득 찬 동안 막힌다.
+	ctx 의 에러와 함께, 또는 Stop 이 호출되면 ErrActorStopped 와 함께 포기한다.
+*/
+func (a *Actor[M]) Send(ctx context.Context, msg M) error {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	if a.closed {
+		return a.stoppedErr()
+	}
+	select {
+	case a.mailbox <- msg:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-a.stopping:
+		return a.stoppedErr()
+	}
+}
+
+/*
+	TrySend is Send that never blocks: a full mailbox is reported as ErrMailboxFull so the caller can shed the load.
+	TrySend 는 절대 막히지 않는 Send 이다: 가득 찬 mailbox 는 ErrMailboxFull 로 알려져서 호출자가 부하를 덜어낼 수 있다.
+*/
+func (a *Actor[M]) TrySend(msg M) error {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	if a.closed {
+		return a.stoppedErr()
+	}
+	select {
+	case a.mailbox <- msg:
+		return nil
+	default:
+		return ErrMailboxFull
+	}
+}
+
+/*
+	stoppedErr tells a crash from a Stop when the owner is already gone.
+	stoppedErr 는 소유자가 이미 사라졌을 때 crash 와 Stop 을 구별한다.
+*/
+func (a *Actor[M]) stoppedErr() error {
+	if err := a.Err(); err != nil {
+		return err
+	}
+	return ErrActorStopped
+}
+
+/*
+	Stop stops accepting messages, lets the owner drain what is already in the mailbox and waits for it to finish.
+	If ctx ends first Stop returns its error, and the owner keeps draining in the background.
+	Stop 은 메시지를 받는 것을 멈추고, 소유자가 이미 mailbox 에 있는 것을 비우게 한 뒤 끝나기를 기다린다.
+	ctx 가 먼저 끝나면 Stop 은 그 에러를 반환하며, 소유자는 백그라운드에서 계속 비운다.
+*/
+func (a *Actor[M]) Stop(ctx context.Context) error {
+	a.close()
+	a.Start()
+	select {
+	case <-a.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+/*
+	Done is closed once the owner goroutine has drained the mailbox and exited.
+	Done 은 소유자 고루틴이 mailbox 를 비우고 종료하면 닫힌다.
+*/
+func (a *Actor[M]) Done() <-chan struct{} {
+	return a.done
+}
+
+/*
+	Err returns the panic that crashed the owner, wrapped in ErrActorCrashed; it is nil while the owner runs and after a clean Stop.
+	Err 는 소유자를 망가뜨린 panic 을 ErrActorCrashed 로 감싸서 반환한다; 소유자가 실행 중일 때와 깨끗한 Stop 뒤에는 nil 이다.
+*/
+func (a *Actor[M]) Err() error {
+	a.errMu.Lock()
+	defer a.errMu.Unlock()
+	return a.err
+}
+
+/*
+	Ask is request/reply on top of Send. build wraps the reply channel into a message, the same way readOp carries its resp channel.
+	The reply channel has room for one value, so the owner never blocks on a caller that has already given up.
+	A timeout of zero means only ctx bounds the wait.
+	Ask 는 Send 위의 요청/응답이다. build 는 readOp 이 resp 채널을 가지는 것과 같은 방식으로 응답 채널을 메시지로 감싼다.
+	응답 채널은 값 하나를 위한 자리가 있어서, 소유자는 이미 포기한 호출자 때문에 절대 막히지 않는다.
+	timeout 이 0 이면 ctx 만 기다림을 제한한다.
+*/
+func Ask[M, R any](ctx context.Context, a *Actor[M], timeout time.Duration, build func(reply chan R) M) (R, error) {
+	var zero R
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+	reply := make(chan R, 1)
+	if err := a.Send(ctx, build(reply)); err != nil {
+		return zero, err
+	}
+	select {
+	case r := <-reply:
+		return r, nil
+	case <-ctx.Done():
+		return zero, ctx.Err()
+	case <-a.done:
+		/*
+			The owner may have replied just before exiting.
+			소유자가 종료하기 직전에 응답했을 수도 있다.
+		*/
+		select {
+		case r := <-reply:
+			return r, nil
+		default:
+		}
+		if err := a.Err(); err != nil {
+			return zero, err
+		}
+		return zero, ErrActorStopped
+	}
+}
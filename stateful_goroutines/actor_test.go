@@ -0,0 +1,155 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+)
+
+/*
+	TestActorStopDrains fills the mailbox of an actor that has not started yet, so Stop is what runs the owner,
+	and checks that every message already in the mailbox is handled before Done is closed and that nothing gets in afterwards.
+	TestActorStopDrains 는 아직 시작하지 않은 actor 의 mailbox 를 채우므로 소유자를 실행하는 것은 Stop 이며,
+	Done 이 닫히기 전에 이미 mailbox 에 있는 모든 메시지가 처리되는지, 그리고 그 뒤에는 아무것도 들어가지 못하는지 확인한다.
+*/
+func TestActorStopDrains(t *testing.T) {
+	var handled []int
+	a := NewActor(3, func(msg int) {
+		handled = append(handled, msg)
+	})
+	ctx := context.Background()
+	for i := 1; i <= 3; i++ {
+		if err := a.TrySend(i); err != nil {
+			t.Fatalf("TrySend(%d): %v", i, err)
+		}
+	}
+	if err := a.Stop(ctx); err != nil {
+		t.Fatalf("stop: %v", err)
+	}
+	if !slices.Equal(handled, []int{1, 2, 3}) {
+		t.Errorf("handled %v, want [1 2 3]", handled)
+	}
+	if err := a.Send(ctx, 4); !errors.Is(err, ErrActorStopped) {
+		t.Errorf("Send after Stop: %v, want ErrActorStopped", err)
+	}
+	if err := a.TrySend(4); !errors.Is(err, ErrActorStopped) {
+		t.Errorf("TrySend after Stop: %v, want ErrActorStopped", err)
+	}
+	if err := a.Err(); err != nil {
+		t.Errorf("Err after a clean Stop: %v", err)
+	}
+}
+
+/*
+	TestActorBackpressure holds the owner inside handle, so the mailbox of one fills up:
+	TrySend fails with ErrMailboxFull, Send waits until its ctx gives up, and a Send blocked with no deadline is woken by Stop.
+	Stop itself gives up with its own ctx while the owner is held, and once the owner is let go it still handles the queued message.
+	TestActorBackpressure 는 소유자를 handle 안에 잡아두므로 크기 하나의 mailbox 가 가득 찬다:
+	TrySend 는 ErrMailboxFull 로 실패하고, Send 는 자신의 ctx 가 포기할 때까지 기다리며, 기한 없이 막힌 Send 는 Stop 이 깨운다.
+	Stop 자신도 소유자가 잡혀있는 동안에는 자신의 ctx 와 함께 포기하고, 소유자가 풀려나면 소유자는 여전히 큐에 있는 메시지를 처리한다.
+*/
+func TestActorBackpressure(t *testing.T) {
+	taken := make(chan int, 3)
+	gate := make(chan struct{})
+	a := NewActor(1, func(msg int) {
+		taken <- msg
+		<-gate
+	})
+	a.Start()
+	ctx := context.Background()
+
+	if err := a.TrySend(1); err != nil {
+		t.Fatalf("TrySend(1): %v", err)
+	}
+	<-taken
+	if err := a.TrySend(2); err != nil {
+		t.Fatalf("TrySend(2): %v", err)
+	}
+	if err := a.TrySend(3); !errors.Is(err, ErrMailboxFull) {
+		t.Errorf("TrySend on a full mailbox: %v, want ErrMailboxFull", err)
+	}
+	short, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+	defer cancel()
+	if err := a.Send(short, 3); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Send on a full mailbox: %v, want context.DeadlineExceeded", err)
+	}
+
+	blocked := make(chan error, 1)
+	go func() {
+		blocked <- a.Send(ctx, 4)
+	}()
+	stopCtx, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+	defer cancel()
+	if err := a.Stop(stopCtx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Stop with the owner held: %v, want context.DeadlineExceeded", err)
+	}
+	if err := <-blocked; !errors.Is(err, ErrActorStopped) {
+		t.Errorf("blocked Send: %v, want ErrActorStopped", err)
+	}
+
+	close(gate)
+	<-a.Done()
+	if msg := <-taken; msg != 2 {
+		t.Errorf("drained %d, want 2", msg)
+	}
+	if n := len(taken); n != 0 {
+		t.Errorf("%d more messages handled, want none", n)
+	}
+}
+
+/*
+	TestAsk asks a fresh actor per case. The owner answers "echo", never answers "drop" and panics on "panic".
+	Ask gives up with its timeout or with the caller's ctx, whichever comes first, and reports a crashed or stopped owner instead of waiting forever.
+	TestAsk 는 경우마다 새 actor 에게 묻는다. 소유자는 "echo" 에 응답하고, "drop" 에는 절대 응답하지 않으며, "panic" 에서 panic 한다.
+	Ask 는 자신의 timeout 또는 호출자의 ctx 중 먼저 오는 것과 함께 포기하고, 영원히 기다리는 대신 망가지거나 멈춘 소유자를 알려준다.
+*/
+func TestAsk(t *testing.T) {
+	type request struct {
+		kind  string
+		reply chan int
+	}
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+	for _, tc := range []struct {
+		name    string
+		ctx     context.Context
+		timeout time.Duration
+		stop    bool
+		kind    string
+		want    int
+		err     error
+	}{
+		{"reply", context.Background(), time.Second, false, "echo", 42, nil},
+		{"reply without a timeout", context.Background(), 0, false, "echo", 42, nil},
+		{"timeout", context.Background(), 10 * time.Millisecond, false, "drop", 0, context.DeadlineExceeded},
+		{"canceled ctx", canceled, 0, false, "drop", 0, context.Canceled},
+		{"canceled ctx before the timeout", canceled, time.Second, false, "drop", 0, context.Canceled},
+		{"crashed owner", context.Background(), 0, false, "panic", 0, ErrActorCrashed},
+		{"stopped owner", context.Background(), 0, true, "echo", 0, ErrActorStopped},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			a := NewActor(1, func(req request) {
+				switch req.kind {
+				case "echo":
+					req.reply <- 42
+				case "panic":
+					panic("corrupted state")
+				}
+			})
+			a.Start()
+			defer a.Stop(context.Background())
+			if tc.stop {
+				a.Stop(context.Background())
+			}
+
+			got, err := Ask(tc.ctx, a, tc.timeout, func(reply chan int) request {
+				return request{kind: tc.kind, reply: reply}
+			})
+			if got != tc.want || !errors.Is(err, tc.err) {
+				t.Errorf("got %d, %v, want %d, %v", got, err, tc.want, tc.err)
+			}
+		})
+	}
+}
@@ -0,0 +1,321 @@
+package main
+
+import (
+	"context"
+	"time"
+
+	"gobyexample/internal/clock"
+)
+
+/*
+	KV is the state-owning goroutine from main rebuilt on Actor.
+	The mailbox carries kvOps, and readOp and writeOp become kvOps by knowing how to apply themselves to the state,
+	so the owner's loop no longer needs a select case per kind of request.
+	KV 는 main 의 상태를 소유하는 고루틴을 Actor 위에 다시 만든 것이다.
+	mailbox 는 kvOp 들을 나르고, readOp 과 writeOp 은 상태에 자신을 적용하는 방법을 앎으로써 kvOp 이 되기 때문에,
+	소유자의 반복문은 더 이상 요청의 종류마다 select case 가 필요하지 않다.
+*/
+type kvOp interface {
+	apply(state *kvState)
+}
+
+/*
+	kvState is the owner's map plus, for every key, the revision of the write that last changed it.
+	Revisions only grow, so a reader that remembers one can later tell whether the key has been written since.
+	A key can also have a deadline in expires; after that it is removed by the first read or write to it, or by the timer in ttl.go.
+	kvState 는 소유자의 map 에 더해, 모든 key 마다 그것을 마지막으로 바꾼 쓰기의 revision 을 가진다.
+	revision 은 증가하기만 하므로, 그것을 기억하는 reader 는 나중에 그 key 가 그 사이에 쓰였는지 알 수 있다.
+	key 는 expires 에 기한을 가질 수도 있다; 그 뒤에는 그것에 대한 첫 읽기나 쓰기, 또는 ttl.go 의 타이머에 의해 제거된다.
+*/
+type kvState struct {
+	values   map[int]int
+	versions map[int]uint64
+	expires  map[int]*deadline
+	revision uint64
+
+	clock     clock.Clock
+	deadlines deadlineHeap
+	timer     clock.Timer
+	timerAt   time.Time
+	stats     TTLStats
+
+	wal *wal
+
+	watchers map[uint64]*watcher
+	nextID   uint64
+	history  changeRing
+}
+
+/*
+	newKVState keeps its deadlines on clk. The expiry timer starts stopped and is only armed once a key has a deadline.
+	newKVState 는 기한들을 clk 위에 둔다. 만료 타이머는 멈춘 채로 시작하고 key 가 기한을 가질 때에만 맞춰진다.
+*/
+func newKVState(clk clock.Clock) *kvState {
+	s := &kvState{
+		values:   make(map[int]int),
+		versions: make(map[int]uint64),
+		expires:  make(map[int]*deadline),
+		watchers: make(map[uint64]*watcher),
+		clock:    clk,
+		timer:    clk.NewTimer(time.Hour),
+	}
+	s.timer.Stop()
+	return s
+}
+
+/*
+	get is how every op reads a key. An expired key is removed first, which logs and notifies like any delete,
+	so nothing is ever built on a value that is already gone, and a key that is read again does not wait for the timer.
+	get 은 모든 op 이 key 를 읽는 방법이다. 만료된 key 는 먼저 제거되며, 이것은 여느 삭제처럼 log 에 쓰고 알리므로,
+	이미 사라진 값 위에는 아무것도 쌓이지 않고, 다시 읽히는 key 는 타이머를 기다리지 않는다.
+*/
+func (s *kvState) get(key int) (int, bool) {
+	if d, ok := s.expires[key]; ok && !s.clock.Now().Before(d.at) {
+		s.del(key)
+		s.stats.ExpiredLazily++
+	}
+	val, ok := s.values[key]
+	return val, ok
+}
+
+/*
+	set keeps the key's deadline, the way INCR does in Redis; writeOp clears it like SET.
+	set 은 Redis 의 INCR 처럼 key 의 기한을 유지한다; writeOp 은 SET 처럼 그것을 지운다.
+*/
+func (s *kvState) set(key, val int) {
+	old, existed := s.values[key]
+	s.revision++
+	s.values[key] = val
+	s.versions[key] = s.revision
+	s.log(walRecord{kind: recSet, key: key, val: val, rev: s.revision})
+	s.notify(Change{Key: key, Old: old, New: val, Created: !existed, Revision: s.revision})
+}
+
+/*
+	del bumps the version too, so an optimistic batch that read the key sees that it is gone.
+	del 도 version 을 올리므로, 그 key 를 읽은 낙관적 batch 는 그것이 사라졌다는 것을 본다.
+*/
+func (s *kvState) del(key int) bool {
+	if _, ok := s.values[key]; !ok {
+		return false
+	}
+	old := s.values[key]
+	s.revision++
+	delete(s.values, key)
+	s.clearDeadline(key)
+	s.versions[key] = s.revision
+	s.log(walRecord{kind: recDel, key: key, rev: s.revision})
+	s.notify(Change{Key: key, Old: old, Deleted: true, Revision: s.revision})
+	return true
+}
+
+/*
+	Reading may remove an expired key, so the read ops reply after the commit, like the writes.
+	읽기는 만료된 key 를 제거할 수 있으므로, 읽기 op 들은 쓰기처럼 commit 뒤에 응답한다.
+*/
+func (op readOp) apply(state *kvState) {
+	val, _ := state.read(op.key)
+	reply(state, op.resp, val)
+}
+
+func (op writeOp) apply(state *kvState) {
+	state.set(op.key, op.val)
+	state.persist(op.key)
+	reply(state, op.resp, true)
+}
+
+type lookupOp struct {
+	key  int
+	resp chan Versioned
+}
+
+func (op lookupOp) apply(state *kvState) {
+	val, ok := state.read(op.key)
+	if !ok {
+		reply(state, op.resp, Versioned{})
+		return
+	}
+	reply(state, op.resp, Versioned{Val: val, Version: state.versions[op.key]})
+}
+
+type delOp struct {
+	keys []int
+	resp chan int
+}
+
+func (op delOp) apply(state *kvState) {
+	n := 0
+	for _, key := range op.keys {
+		if _, ok := state.get(key); ok && state.del(key) {
+			n++
+		}
+	}
+	reply(state, op.resp, n)
+}
+
+type expireOp struct {
+	key  int
+	at   time.Time
+	resp chan bool
+}
+
+func (op expireOp) apply(state *kvState) {
+	if _, ok := state.get(op.key); !ok {
+		reply(state, op.resp, false)
+		return
+	}
+	state.expireAt(op.key, op.at)
+	reply(state, op.resp, true)
+}
+
+/*
+	panicOp crashes the owner on purpose, standing in for a bug in a handler.
+	panicOp 은 handler 안의 버그를 대신해서 일부러 소유자를 망가뜨린다.
+*/
+type panicOp struct {
+	reason string
+}
+
+func (op panicOp) apply(state *kvState) {
+	panic(op.reason)
+}
+
+type KV struct {
+	actor   *Actor[kvOp]
+	clock   clock.Clock
+	timeout time.Duration
+	closed  chan struct{}
+}
+
+/*
+	NewKV starts an owner with a mailbox of size requests; every Get and Set waits at most timeout for its reply.
+	NewKV 는 size 개의 요청을 담는 mailbox 를 가진 소유자를 시작한다; 모든 Get 과 Set 은 응답을 최대 timeout 만큼 기다린다.
+*/
+func NewKV(size int, timeout time.Duration) *KV {
+	return NewKVWithClock(clock.Real{}, size, timeout)
+}
+
+/*
+	NewKVWithClock is NewKV on the given clock, which the TTLs are measured on.
+	NewKVWithClock 은 주어진 clock 위의 NewKV 이며, TTL 들은 그 clock 위에서 재어진다.
+*/
+func NewKVWithClock(clk clock.Clock, size int, timeout time.Duration) *KV {
+	return newKV(size, timeout, newKVState(clk))
+}
+
+func newKV(size int, timeout time.Duration, state *kvState) *KV {
+	kv := &KV{
+		actor: NewActor(size, func(op kvOp) {
+			op.apply(state)
+			state.commit()
+		}),
+		clock:   state.clock,
+		timeout: timeout,
+		closed:  make(chan struct{}),
+	}
+	if len(state.deadlines) > 0 {
+		state.arm(state.deadlines[0].at)
+	}
+	kv.actor.Start()
+	/*
+		The expiry timer fires on this goroutine, so it asks the owner to do the work; once the owner is gone there is nothing to expire.
+		만료 타이머는 이 고루틴에서 울리므로 소유자에게 일을 하도록 요청한다; 소유자가 사라지면 만료할 것도 없다.
+	*/
+	go func() {
+		for {
+			select {
+			case <-state.timer.C():
+				kv.actor.Send(context.Background(), expireDueOp{})
+			case <-kv.actor.Done():
+				return
+			}
+		}
+	}()
+	/*
+		Once the owner has exited nobody else touches state, so this goroutine may close the watches it left open
+		and the log, and only then report the KV as done.
+		소유자가 종료하고 나면 아무도 state 를 건드리지 않으므로, 이 고루틴이 남겨진 watch 들과 log 를 닫아도 되며,
+		그런 다음에야 KV 가 끝났다고 알린다.
+	*/
+	go func() {
+		defer close(kv.closed)
+		<-kv.actor.Done()
+		state.timer.Stop()
+		state.closeWatchers(kv.actor.stoppedErr())
+		state.closeWAL()
+	}()
+	return kv
+}
+
+func (kv *KV) Get(ctx context.Context, key int) (int, error) {
+	return Ask(ctx, kv.actor, kv.timeout, func(resp chan int) kvOp {
+		return readOp{key: key, resp: resp}
+	})
+}
+
+func (kv *KV) Set(ctx context.Context, key, val int) error {
+	_, err := Ask(ctx, kv.actor, kv.timeout, func(resp chan bool) kvOp {
+		return writeOp{key: key, val: val, resp: resp}
+	})
+	return err
+}
+
+/*
+	Lookup is Get that also tells a missing key from one holding zero.
+	Lookup 은 없는 key 와 0 을 가진 key 를 구별해주기도 하는 Get 이다.
+*/
+func (kv *KV) Lookup(ctx context.Context, key int) (int, bool, error) {
+	v, err := Ask(ctx, kv.actor, kv.timeout, func(resp chan Versioned) kvOp {
+		return lookupOp{key: key, resp: resp}
+	})
+	return v.Val, v.Version != 0, err
+}
+
+/*
+	Delete removes keys and returns how many of them existed.
+	Delete 는 keys 를 제거하고 그중 몇 개가 존재했는지 반환한다.
+*/
+func (kv *KV) Delete(ctx context.Context, keys ...int) (int, error) {
+	return Ask(ctx, kv.actor, kv.timeout, func(resp chan int) kvOp {
+		return delOp{keys: keys, resp: resp}
+	})
+}
+
+/*
+	Expire removes key once ttl has passed, unless a Set or Delete gets there first. It reports false if key does not exist.
+	Expire 는 Set 이나 Delete 가 먼저 오지 않는 한 ttl 이 지나면 key 를 제거한다. key 가 존재하지 않으면 false 를 알려준다.
+*/
+func (kv *KV) Expire(ctx context.Context, key int, ttl time.Duration) (bool, error) {
+	at := kv.clock.Now().Add(ttl)
+	return Ask(ctx, kv.actor, kv.timeout, func(resp chan bool) kvOp {
+		return expireOp{key: key, at: at, resp: resp}
+	})
+}
+
+/*
+	Done and Err tell a supervisor when the owner has exited and whether it crashed.
+	Done 과 Err 는 supervisor 에게 소유자가 언제 종료했는지와 그것이 망가졌는지를 알려준다.
+*/
+func (kv *KV) Done() <-chan struct{} {
+	return kv.closed
+}
+
+func (kv *KV) Err() error {
+	return kv.actor.Err()
+}
+
+/*
+	Stop drains the requests already in the mailbox before the owner exits.
+	Stop 은 소유자가 종료하기 전에 이미 mailbox 에 있는 요청들을 비운다.
+*/
+func (kv *KV) Stop(ctx context.Context) error {
+	if err := kv.actor.Stop(ctx); err != nil {
+		return err
+	}
+	select {
+	case <-kv.closed:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,612 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"gobyexample/internal/clock"
+)
+
+/*
+	Without persistence the map is gone when the process exits. OpenKV keeps it in a directory instead:
+	every change the owner applies is appended to a write-ahead log, the records of one op framed together under one checksum,
+	and every SnapshotEvery records the whole state is written to a snapshot so the log can start over.
+	On startup the newest snapshot is loaded and the log after it is replayed, stopping at the first batch that is torn or fails its checksum,
+	which is what a crash in the middle of a write leaves behind. A multi-key op is therefore recovered whole or not at all.
+	All file I/O happens on the owner goroutine, so the log needs no lock of its own. If it fails the owner panics,
+	which crashes the actor the same way a bad handler does, so a supervisor can reopen the directory.
+	영속성이 없으면 map 은 프로세스가 종료될 때 사라진다. OpenKV 는 대신 그것을 디렉터리에 보관한다:
+	소유자가 적용하는 모든 변경은 write-ahead log 에 덧붙여지는데, 한 op 의 레코드들은 하나의 checksum 아래 함께 묶이고,
+	SnapshotEvery 개의 레코드마다 전체 상태가 snapshot 에 쓰여서 log 가 다시 시작할 수 있다.
+	시작할 때 가장 새로운 snapshot 을 읽고 그 뒤의 log 를 다시 실행하는데, 찢어졌거나 checksum 이 맞지 않는 첫 batch 에서 멈춘다,
+	쓰기 도중의 crash 가 남기는 것이 바로 그것이다. 그러므로 여러 key 의 op 은 통째로 복구되거나 전혀 복구되지 않는다.
+	모든 파일 I/O 는 소유자 고루틴에서 일어나므로 log 는 자신만의 lock 이 필요 없다. 그것이 실패하면 소유자는 panic 하며,
+	이는 잘못된 handler 와 같은 방식으로 actor 를 망가뜨리므로 supervisor 가 디렉터리를 다시 열 수 있다.
+*/
+
+type PersistOptions struct {
+	/*
+		SyncInterval batches fsyncs: the log is synced at most this often, so a power failure loses at most the last interval of acknowledged writes.
+		Zero syncs after every record.
+		SyncInterval 은 fsync 를 묶는다: log 는 최대 이 주기마다 sync 되므로, 정전은 최대 마지막 주기 동안의 응답된 쓰기만 잃는다.
+		0 이면 모든 레코드 뒤에 sync 한다.
+	*/
+	SyncInterval time.Duration
+	/*
+		SnapshotEvery is the number of records between snapshots; zero never takes one.
+		SnapshotEvery 는 snapshot 사이의 레코드 개수이다; 0 이면 절대 찍지 않는다.
+	*/
+	SnapshotEvery int
+}
+
+const (
+	recSet byte = iota + 1
+	recDel
+	recExpire
+	recPersist
+)
+
+/*
+	A batch on disk is a 4 byte payload length, a 4 byte CRC-32C of the payload, and the payload: the records of one op, one after another.
+	seq numbers records so replay knows which ones a snapshot already covers; rev is the state's revision after the record.
+	디스크 위의 batch 는 4 바이트 payload 길이, payload 의 4 바이트 CRC-32C, 그리고 payload 이다: 한 op 의 레코드들이 차례로 있다.
+	seq 는 레코드에 번호를 매겨서 다시 실행할 때 snapshot 이 이미 어떤 것들을 포함하는지 알게 한다; rev 는 레코드 뒤의 상태의 revision 이다.
+*/
+type walRecord struct {
+	seq  uint64
+	kind byte
+	key  int
+	val  int
+	rev  uint64
+	at   int64
+}
+
+const (
+	walHeaderSize = 8
+	walRecordSize = 8 + 1 + 8 + 8 + 8 + 8
+)
+
+var (
+	crcTable = crc32.MakeTable(crc32.Castagnoli)
+
+	errTornRecord    = errors.New("wal: torn record")
+	errCorruptRecord = errors.New("wal: checksum mismatch")
+	errLogGap        = errors.New("wal: records missing")
+)
+
+func encodeBatch(recs []walRecord) []byte {
+	buf := make([]byte, walHeaderSize, walHeaderSize+walRecordSize*len(recs))
+	for _, r := range recs {
+		buf = binary.LittleEndian.AppendUint64(buf, r.seq)
+		buf = append(buf, r.kind)
+		buf = binary.LittleEndian.AppendUint64(buf, uint64(r.key))
+		buf = binary.LittleEndian.AppendUint64(buf, uint64(r.val))
+		buf = binary.LittleEndian.AppendUint64(buf, r.rev)
+		buf = binary.LittleEndian.AppendUint64(buf, uint64(r.at))
+	}
+	p := buf[walHeaderSize:]
+	binary.LittleEndian.PutUint32(buf[0:], uint32(len(p)))
+	binary.LittleEndian.PutUint32(buf[4:], crc32.Checksum(p, crcTable))
+	return buf
+}
+
+/*
+	readBatch returns io.EOF only at a clean batch boundary; a partial batch is errTornRecord.
+	left is how much of the file is still unread, so a damaged length is caught before anything is allocated for it.
+	readBatch 는 깨끗한 batch 경계에서만 io.EOF 를 반환한다; 일부만 있는 batch 는 errTornRecord 이다.
+	left 는 파일에서 아직 읽지 않은 양이므로, 손상된 길이는 그것을 위해 무엇이든 할당하기 전에 잡힌다.
+*/
+func readBatch(r io.Reader, left int64) ([]walRecord, error) {
+	var header [walHeaderSize]byte
+	if _, err := io.ReadFull(r, header[:]); err != nil {
+		if err == io.ErrUnexpectedEOF {
+			return nil, errTornRecord
+		}
+		return nil, err
+	}
+	n := int64(binary.LittleEndian.Uint32(header[0:]))
+	if n == 0 || n%walRecordSize != 0 {
+		return nil, errCorruptRecord
+	}
+	if n > left-walHeaderSize {
+		return nil, errTornRecord
+	}
+	p := make([]byte, n)
+	if _, err := io.ReadFull(r, p); err != nil {
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			return nil, errTornRecord
+		}
+		return nil, err
+	}
+	if crc32.Checksum(p, crcTable) != binary.LittleEndian.Uint32(header[4:]) {
+		return nil, errCorruptRecord
+	}
+	recs := make([]walRecord, 0, n/walRecordSize)
+	for ; len(p) > 0; p = p[walRecordSize:] {
+		recs = append(recs, walRecord{
+			seq:  binary.LittleEndian.Uint64(p[0:]),
+			kind: p[8],
+			key:  int(binary.LittleEndian.Uint64(p[9:])),
+			val:  int(binary.LittleEndian.Uint64(p[17:])),
+			rev:  binary.LittleEndian.Uint64(p[25:]),
+			at:   int64(binary.LittleEndian.Uint64(p[33:])),
+		})
+	}
+	return recs, nil
+}
+
+/*
+	readWAL reads batches until the end of the file or the first bad one, and returns the offset just after the last good batch.
+	readWAL 은 파일의 끝이나 첫 잘못된 batch 까지 batch 들을 읽고, 마지막 좋은 batch 바로 뒤의 offset 을 반환한다.
+*/
+func readWAL(path string) (batches [][]walRecord, good int64, err error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return nil, 0, err
+	}
+	r := bufio.NewReader(f)
+	for {
+		recs, err := readBatch(r, info.Size()-good)
+		if err == io.EOF {
+			return batches, good, nil
+		}
+		if err != nil {
+			return batches, good, err
+		}
+		batches = append(batches, recs)
+		good += walHeaderSize + walRecordSize*int64(len(recs))
+	}
+}
+
+/*
+	pending holds the records of the op being applied until commit writes them as one batch.
+	snap is the seq of the newest snapshot known to load, or 0; it and the log after it are kept until the next snapshot is on disk.
+	pending 은 적용 중인 op 의 레코드들을 commit 이 그것들을 하나의 batch 로 쓸 때까지 가진다.
+	snap 은 읽힌다고 알려진 가장 새로운 snapshot 의 seq 이거나 0 이다; 그것과 그 뒤의 log 는 다음 snapshot 이 디스크에 있을 때까지 보관된다.
+*/
+type wal struct {
+	dir       string
+	opts      PersistOptions
+	f         *os.File
+	w         *bufio.Writer
+	seq       uint64
+	snap      uint64
+	pending   []walRecord
+	sinceSnap int
+	dirty     bool
+	lastSync  time.Time
+}
+
+func walName(seq uint64) string      { return fmt.Sprintf("wal-%020d.log", seq) }
+func snapshotName(seq uint64) string { return fmt.Sprintf("snapshot-%020d.snap", seq) }
+
+/*
+	log records a change the owner has just applied; it reaches the file when the op commits. Nothing is logged while state is being recovered, since wal is still nil.
+	log 는 소유자가 방금 적용한 변경을 기록한다; 그것은 op 이 commit 할 때 파일에 닿는다. 상태가 복구되는 동안에는 wal 이 아직 nil 이므로 아무것도 기록되지 않는다.
+*/
+func (s *kvState) log(rec walRecord) {
+	l := s.wal
+	if l == nil {
+		return
+	}
+	l.seq++
+	rec.seq = l.seq
+	l.pending = append(l.pending, rec)
+}
+
+/*
+	commit appends everything the current op logged as one batch, and only then syncs or snapshots, so neither lands in the middle of an op.
+	An op that panics never commits, and its records are dropped with the owner.
+	commit 은 현재 op 이 기록한 모든 것을 하나의 batch 로 덧붙이고, 그 뒤에야 sync 하거나 snapshot 을 찍으므로, 어느 것도 op 의 중간에 놓이지 않는다.
+	panic 하는 op 은 절대 commit 하지 않으며, 그 레코드들은 소유자와 함께 버려진다.
+*/
+func (s *kvState) commit() {
+	l := s.wal
+	if l == nil || len(l.pending) == 0 {
+		return
+	}
+	if _, err := l.w.Write(encodeBatch(l.pending)); err != nil {
+		panic(fmt.Errorf("wal: append: %w", err))
+	}
+	l.sinceSnap += len(l.pending)
+	l.pending = l.pending[:0]
+	l.dirty = true
+	if l.opts.SyncInterval == 0 || time.Since(l.lastSync) >= l.opts.SyncInterval {
+		l.sync()
+	}
+	if l.opts.SnapshotEvery > 0 && l.sinceSnap >= l.opts.SnapshotEvery {
+		s.snapshot()
+	}
+}
+
+/*
+	reply commits what the op has logged, then answers the caller, so nobody hears about a write before it is in the log.
+	Every op that writes replies through it; the owner also commits after each op, for ops like expireDueOp that have nobody to answer.
+	reply 는 op 이 기록한 것을 commit 한 뒤 호출자에게 답하므로, 아무도 log 에 들어가기 전의 쓰기에 대해 듣지 않는다.
+	쓰는 모든 op 은 그것을 통해 응답한다; 소유자도 각 op 뒤에 commit 하는데, expireDueOp 처럼 답할 상대가 없는 op 들을 위한 것이다.
+*/
+func reply[R any](state *kvState, resp chan R, r R) {
+	state.commit()
+	resp <- r
+}
+
+func (l *wal) sync() {
+	if !l.dirty {
+		return
+	}
+	if err := l.w.Flush(); err != nil {
+		panic(fmt.Errorf("wal: flush: %w", err))
+	}
+	if err := l.f.Sync(); err != nil {
+		panic(fmt.Errorf("wal: fsync: %w", err))
+	}
+	l.dirty = false
+	l.lastSync = time.Now()
+}
+
+/*
+	syncOp is sent by a ticker so that writes that stop arriving are still synced within SyncInterval.
+	syncOp 은 ticker 가 보내서, 도착이 멈춘 쓰기도 SyncInterval 안에 sync 되게 한다.
+*/
+type syncOp struct{}
+
+func (op syncOp) apply(state *kvState) {
+	if state.wal != nil {
+		state.wal.sync()
+	}
+}
+
+/*
+	snapshot writes the whole state to a temporary file, syncs it and renames it into place, so a snapshot on disk is always complete.
+	Then the log starts a new file. The previous snapshot and the log after it stay, in case the new snapshot is damaged later;
+	everything older is removed.
+	snapshot 은 전체 상태를 임시 파일에 쓰고, sync 한 뒤 제자리로 이름을 바꾸므로, 디스크 위의 snapshot 은 항상 완전하다.
+	그런 다음 log 는 새 파일을 시작한다. 새 snapshot 이 나중에 손상될 경우를 위해 이전 snapshot 과 그 뒤의 log 는 남고,
+	그보다 오래된 모든 것은 제거된다.
+*/
+func (s *kvState) snapshot() {
+	l := s.wal
+	l.sync()
+
+	buf := make([]byte, 24, 24+32*len(s.values)+4)
+	binary.LittleEndian.PutUint64(buf[0:], l.seq)
+	binary.LittleEndian.PutUint64(buf[8:], s.revision)
+	binary.LittleEndian.PutUint64(buf[16:], uint64(len(s.values)))
+	for key, val := range s.values {
+		var at int64
+		if d, ok := s.expires[key]; ok {
+			at = d.at.UnixNano()
+		}
+		buf = binary.LittleEndian.AppendUint64(buf, uint64(key))
+		buf = binary.LittleEndian.AppendUint64(buf, uint64(val))
+		buf = binary.LittleEndian.AppendUint64(buf, s.versions[key])
+		buf = binary.LittleEndian.AppendUint64(buf, uint64(at))
+	}
+	buf = binary.LittleEndian.AppendUint32(buf, crc32.Checksum(buf, crcTable))
+
+	path := filepath.Join(l.dir, snapshotName(l.seq))
+	if err := writeFileSync(path+".tmp", buf); err != nil {
+		panic(fmt.Errorf("wal: snapshot: %w", err))
+	}
+	if err := os.Rename(path+".tmp", path); err != nil {
+		panic(fmt.Errorf("wal: snapshot: %w", err))
+	}
+	if err := l.rotate(); err != nil {
+		panic(fmt.Errorf("wal: snapshot: %w", err))
+	}
+	l.sinceSnap = 0
+
+	/*
+		Only now is it safe to forget what came before the previous snapshot: the new snapshot and log are both on disk.
+		A log file can go once the next one starts right after the previous snapshot, or earlier.
+		이제서야 이전 snapshot 전에 있던 것을 잊어도 안전하다: 새 snapshot 과 log 가 둘 다 디스크에 있다.
+		log 파일은 다음 파일이 이전 snapshot 바로 뒤에서, 또는 그 전에 시작하면 사라질 수 있다.
+	*/
+	snaps, wals, _ := listPersisted(l.dir)
+	for _, seq := range snaps {
+		if seq != l.snap && seq != l.seq {
+			os.Remove(filepath.Join(l.dir, snapshotName(seq)))
+		}
+	}
+	for i, seq := range wals {
+		if i+1 < len(wals) && wals[i+1] <= l.snap+1 {
+			os.Remove(filepath.Join(l.dir, walName(seq)))
+		}
+	}
+	l.snap = l.seq
+}
+
+/*
+	rotate closes the current log file and starts a new one named after the next record's seq.
+	rotate 는 현재 log 파일을 닫고 다음 레코드의 seq 로 이름 지은 새 파일을 시작한다.
+*/
+func (l *wal) rotate() error {
+	if l.f != nil {
+		if err := l.f.Close(); err != nil {
+			return err
+		}
+	}
+	f, err := os.OpenFile(filepath.Join(l.dir, walName(l.seq+1)), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return err
+	}
+	l.f = f
+	l.w = bufio.NewWriter(f)
+	return syncDir(l.dir)
+}
+
+/*
+	closeWAL writes what was committed; records an op logged before it panicked are not.
+	closeWAL 은 commit 된 것을 쓴다; op 이 panic 하기 전에 기록한 레코드들은 쓰지 않는다.
+*/
+func (s *kvState) closeWAL() {
+	l := s.wal
+	if l == nil {
+		return
+	}
+	l.w.Flush()
+	l.f.Sync()
+	l.f.Close()
+}
+
+func writeFileSync(path string, data []byte) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+/*
+	syncDir makes a create or rename in dir durable, not just the file's contents.
+	syncDir 은 파일의 내용만이 아니라 dir 안의 생성이나 이름 바꾸기를 영속적으로 만든다.
+*/
+func syncDir(dir string) error {
+	d, err := os.Open(dir)
+	if err != nil {
+		return err
+	}
+	defer d.Close()
+	return d.Sync()
+}
+
+/*
+	listPersisted returns the seqs in the names of the snapshots and log files in dir, each in ascending order.
+	listPersisted 는 dir 안의 snapshot 과 log 파일 이름의 seq 들을 각각 오름차순으로 반환한다.
+*/
+func listPersisted(dir string) (snaps, wals []uint64, err error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, e := range entries {
+		var seq uint64
+		if _, err := fmt.Sscanf(e.Name(), "snapshot-%d.snap", &seq); err == nil && e.Name() == snapshotName(seq) {
+			snaps = append(snaps, seq)
+		} else if _, err := fmt.Sscanf(e.Name(), "wal-%d.log", &seq); err == nil && e.Name() == walName(seq) {
+			wals = append(wals, seq)
+		}
+	}
+	sort.Slice(snaps, func(i, j int) bool { return snaps[i] < snaps[j] })
+	sort.Slice(wals, func(i, j int) bool { return wals[i] < wals[j] })
+	return snaps, wals, nil
+}
+
+func loadSnapshot(path string, state *kvState) (seq uint64, err error) {
+	buf, err := os.ReadFile(path)
+	if err != nil {
+		return 0, err
+	}
+	if len(buf) < 28 || crc32.Checksum(buf[:len(buf)-4], crcTable) != binary.LittleEndian.Uint32(buf[len(buf)-4:]) {
+		return 0, fmt.Errorf("%s: %w", path, errCorruptRecord)
+	}
+	seq = binary.LittleEndian.Uint64(buf[0:])
+	state.revision = binary.LittleEndian.Uint64(buf[8:])
+	n := binary.LittleEndian.Uint64(buf[16:])
+	if uint64(len(buf)) != 28+32*n {
+		return 0, fmt.Errorf("%s: %w", path, errCorruptRecord)
+	}
+	for p := buf[24 : len(buf)-4]; len(p) > 0; p = p[32:] {
+		key := int(binary.LittleEndian.Uint64(p[0:]))
+		state.values[key] = int(binary.LittleEndian.Uint64(p[8:]))
+		state.versions[key] = binary.LittleEndian.Uint64(p[16:])
+		if at := int64(binary.LittleEndian.Uint64(p[24:])); at != 0 {
+			state.restoreDeadline(key, time.Unix(0, at))
+		}
+	}
+	return seq, nil
+}
+
+func (s *kvState) replay(rec walRecord) {
+	switch rec.kind {
+	case recSet:
+		s.values[rec.key] = rec.val
+		s.versions[rec.key] = rec.rev
+	case recDel:
+		delete(s.values, rec.key)
+		s.clearDeadline(rec.key)
+		s.versions[rec.key] = rec.rev
+	case recExpire:
+		s.restoreDeadline(rec.key, time.Unix(0, rec.at))
+	case recPersist:
+		s.clearDeadline(rec.key)
+	}
+	s.revision = rec.rev
+}
+
+/*
+	recoverState loads the newest snapshot in dir that passes its checksum, falling back to older ones, and replays the log after it.
+	A torn or corrupt batch is what a crash leaves at the end of the log, so it ends recovery: its file is truncated there
+	and any later log files are removed, so new records are appended right after the last good one.
+	Records missing between the snapshot and the log, or between two batches, are not something a crash does; recovery stops with errLogGap
+	and leaves the files as they are.
+	recoverState 는 dir 안에서 checksum 을 통과하는 가장 새로운 snapshot 을 읽고, 안 되면 더 오래된 것으로 물러나며, 그 뒤의 log 를 다시 실행한다.
+	찢어졌거나 손상된 batch 는 crash 가 log 의 끝에 남기는 것이므로 복구를 끝낸다: 그 파일은 거기서 잘리고
+	그 뒤의 log 파일들은 제거되므로, 새 레코드들은 마지막 좋은 레코드 바로 뒤에 덧붙여진다.
+	snapshot 과 log 사이, 또는 두 batch 사이에 빠진 레코드는 crash 가 하는 일이 아니다; 복구는 errLogGap 으로 멈추고
+	파일들을 그대로 둔다.
+*/
+func recoverState(dir string, opts PersistOptions) (*kvState, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	snaps, wals, err := listPersisted(dir)
+	if err != nil {
+		return nil, err
+	}
+	state := newKVState(clock.Real{})
+	var seq uint64
+	for i := len(snaps) - 1; i >= 0; i-- {
+		seq, err = loadSnapshot(filepath.Join(dir, snapshotName(snaps[i])), state)
+		if err == nil {
+			break
+		}
+		if !errors.Is(err, errCorruptRecord) {
+			return nil, err
+		}
+		state, seq = newKVState(clock.Real{}), 0
+	}
+	snap := seq
+
+	last := ""
+	for i, start := range wals {
+		path := filepath.Join(dir, walName(start))
+		batches, good, err := readWAL(path)
+		for _, recs := range batches {
+			if recs[len(recs)-1].seq <= seq {
+				continue
+			}
+			if recs[0].seq != seq+1 {
+				return nil, fmt.Errorf("%w: %s: expected record %d, found %d", errLogGap, path, seq+1, recs[0].seq)
+			}
+			for _, rec := range recs {
+				state.replay(rec)
+			}
+			seq = recs[len(recs)-1].seq
+		}
+		if err == nil {
+			last = path
+			continue
+		}
+		if !errors.Is(err, errTornRecord) && !errors.Is(err, errCorruptRecord) {
+			return nil, err
+		}
+		if err := os.Truncate(path, good); err != nil {
+			return nil, err
+		}
+		last = path
+		for _, later := range wals[i+1:] {
+			os.Remove(filepath.Join(dir, walName(later)))
+		}
+		break
+	}
+
+	l := &wal{dir: dir, opts: opts, seq: seq, snap: snap, lastSync: time.Now()}
+	if last == "" {
+		if err := l.rotate(); err != nil {
+			return nil, err
+		}
+	} else {
+		f, err := os.OpenFile(last, os.O_WRONLY|os.O_APPEND, 0o644)
+		if err != nil {
+			return nil, err
+		}
+		l.f = f
+		l.w = bufio.NewWriter(f)
+	}
+	state.wal = l
+	return state, nil
+}
+
+/*
+	OpenKV is NewKV backed by dir, starting from whatever state dir holds.
+	OpenKV 는 dir 에 기반한 NewKV 로, dir 이 가진 상태에서 시작한다.
+*/
+func OpenKV(dir string, size int, timeout time.Duration, opts PersistOptions) (*KV, error) {
+	state, err := recoverState(dir, opts)
+	if err != nil {
+		return nil, err
+	}
+	kv := newKV(size, timeout, state)
+	if opts.SyncInterval > 0 {
+		go func() {
+			ticker := time.NewTicker(opts.SyncInterval)
+			defer ticker.Stop()
+			for {
+				select {
+				case <-ticker.C:
+					kv.actor.TrySend(syncOp{})
+				case <-kv.actor.Done():
+					return
+				}
+			}
+		}()
+	}
+	return kv, nil
+}
+
+/*
+	persistExample writes through a persisted KV, stops it and opens the directory again, which rebuilds the state from the snapshot and the log tail.
+	persistExample 은 영속되는 KV 를 통해 쓰고, 그것을 멈춘 뒤 디렉터리를 다시 여는데, 이는 snapshot 과 log 의 끝부분으로부터 상태를 다시 만든다.
+*/
+func persistExample(out io.Writer) {
+	dir, err := os.MkdirTemp("", "kv-persist")
+	if err != nil {
+		fmt.Fprintln(out, "persist: ", err)
+		return
+	}
+	defer os.RemoveAll(dir)
+	ctx := context.Background()
+	opts := PersistOptions{SyncInterval: 10 * time.Millisecond, SnapshotEvery: 50}
+
+	kv, err := OpenKV(dir, 100, time.Second, opts)
+	if err != nil {
+		fmt.Fprintln(out, "open: ", err)
+		return
+	}
+	for i := 0; i < 120; i++ {
+		kv.Increment(ctx, i%4, 1)
+	}
+	kv.SetWithTTL(ctx, 9, 99, time.Hour)
+	kv.Stop(ctx)
+
+	snaps, wals, _ := listPersisted(dir)
+	fmt.Fprintf(out, "on disk: %d snapshot(s), %d log file(s)\n", len(snaps), len(wals))
+
+	kv, err = OpenKV(dir, 100, time.Second, opts)
+	if err != nil {
+		fmt.Fprintln(out, "reopen: ", err)
+		return
+	}
+	defer kv.Stop(ctx)
+	var vals []int
+	for key := 0; key < 4; key++ {
+		val, _ := kv.Get(ctx, key)
+		vals = append(vals, val)
+	}
+	session, _ := kv.Get(ctx, 9)
+	fmt.Fprintln(out, "recovered: ", vals, "key 9:", session)
+
+}
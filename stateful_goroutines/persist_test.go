@@ -0,0 +1,251 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func recoveredState(dir string, keys int) (map[int]int, error) {
+	ctx := context.Background()
+	kv, err := OpenKV(dir, 100, time.Second, PersistOptions{})
+	if err != nil {
+		return nil, err
+	}
+	defer kv.Stop(ctx)
+	state := map[int]int{}
+	for key := 0; key < keys; key++ {
+		val, ok, err := kv.Lookup(ctx, key)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			state[key] = val
+		}
+	}
+	return state, nil
+}
+
+/*
+	batchEnds returns the offset just after each batch in an undamaged log.
+	batchEnds 는 손상되지 않은 log 안의 각 batch 바로 뒤의 offset 을 반환한다.
+*/
+func batchEnds(t *testing.T, log []byte) []int {
+	var ends []int
+	r := bufio.NewReader(bytes.NewReader(log))
+	end := 0
+	for {
+		recs, err := readBatch(r, int64(len(log)-end))
+		if err == io.EOF {
+			return ends
+		}
+		if err != nil {
+			t.Fatalf("reading the source log: %v", err)
+		}
+		end += walHeaderSize + walRecordSize*len(recs)
+		ends = append(ends, end)
+	}
+}
+
+/*
+	TestCrashRecovery writes a log, then over and over cuts it off at a random offset, or flips a random byte, and recovers from what is left.
+	Every op writes one batch, and a Txn's batch holds two records, so models[i] is the state after the first i batches
+	and a recovered state between two of them would be a torn Txn.
+	Every time, the recovered state must be exactly the state after the last whole batch before the damage,
+	and the recovered KV must accept and keep new writes.
+	TestCrashRecovery 는 log 를 쓴 뒤, 그것을 무작위 offset 에서 자르거나 무작위 바이트를 뒤집고 남은 것으로부터 복구하는 것을 반복한다.
+	모든 op 은 하나의 batch 를 쓰고 Txn 의 batch 는 두 레코드를 가지므로, models[i] 는 처음 i 개의 batch 뒤의 상태이며
+	그 둘 사이의 복구된 상태는 찢어진 Txn 일 것이다.
+	매번 복구된 상태는 정확히 손상 전의 마지막 온전한 batch 뒤의 상태여야 하며,
+	복구된 KV 는 새 쓰기를 받아들이고 유지해야 한다.
+*/
+func TestCrashRecovery(t *testing.T) {
+	const keys, ops, trials = 8, 200, 50
+	ctx := context.Background()
+	dir := t.TempDir()
+
+	kv, err := OpenKV(filepath.Join(dir, "source"), 100, time.Second, PersistOptions{SyncInterval: time.Second})
+	if err != nil {
+		t.Fatal(err)
+	}
+	model := map[int]int{}
+	models := []map[int]int{{}}
+	for i := 0; i < ops; i++ {
+		key := rand.Intn(keys)
+		_, exists := model[key]
+		switch n := rand.Intn(4); {
+		case n == 0 && exists:
+			kv.Delete(ctx, key)
+			delete(model, key)
+		case n == 1:
+			val, _ := kv.Increment(ctx, key, 1)
+			model[key] = val
+		case n == 2:
+			other := (key + 1) % keys
+			kv.Txn(ctx, []int{key, other}, func(vals map[int]int) error {
+				vals[key]--
+				vals[other]++
+				return nil
+			})
+			model[key]--
+			model[other]++
+		default:
+			val := rand.Intn(1000)
+			kv.Set(ctx, key, val)
+			model[key] = val
+		}
+		models = append(models, maps.Clone(model))
+	}
+	kv.Stop(ctx)
+	log, err := os.ReadFile(filepath.Join(dir, "source", walName(1)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ends := batchEnds(t, log)
+	if len(ends) != ops {
+		t.Fatalf("%d batches in the log, want one per op", len(ends))
+	}
+	survivors := func(offset int) int {
+		n := 0
+		for n < len(ends) && ends[n] <= offset {
+			n++
+		}
+		return n
+	}
+
+	for _, tc := range []struct {
+		name   string
+		damage func(log []byte) ([]byte, int)
+	}{
+		{"cut", func(log []byte) ([]byte, int) {
+			cut := rand.Intn(len(log) + 1)
+			return log[:cut], survivors(cut)
+		}},
+		{"flip", func(log []byte) ([]byte, int) {
+			at := rand.Intn(len(log))
+			log[at] ^= 0xff
+			return log, survivors(at)
+		}},
+	} {
+		for trial := 0; trial < trials; trial++ {
+			damaged, want := tc.damage(append([]byte(nil), log...))
+			trialDir := filepath.Join(dir, fmt.Sprint(tc.name, "-", trial))
+			os.MkdirAll(trialDir, 0o755)
+			os.WriteFile(filepath.Join(trialDir, walName(1)), damaged, 0o644)
+
+			got, err := recoveredState(trialDir, keys)
+			if err != nil || !maps.Equal(got, models[want]) {
+				t.Errorf("%s %d: want state after %d batches %v, got %v (%v)", tc.name, trial, want, models[want], got, err)
+				continue
+			}
+
+			/*
+				A write after recovery has to land after the last good batch, not after the damage.
+				복구 뒤의 쓰기는 손상 뒤가 아니라 마지막 좋은 batch 뒤에 놓여야 한다.
+			*/
+			kv, err := OpenKV(trialDir, 100, time.Second, PersistOptions{})
+			if err != nil {
+				t.Errorf("%s %d: reopen: %v", tc.name, trial, err)
+				continue
+			}
+			kv.Set(ctx, 0, -1)
+			kv.Stop(ctx)
+			got, err = recoveredState(trialDir, keys)
+			if err != nil || got[0] != -1 {
+				t.Errorf("%s %d: write after recovery lost: %v (%v)", tc.name, trial, got, err)
+			}
+		}
+	}
+}
+
+/*
+	TestRecoverLogGap cuts a batch out of the middle of the log. A crash cannot do that, so recovery must refuse to start
+	instead of truncating the log there, and must leave the file as it found it.
+	TestRecoverLogGap 은 log 의 중간에서 batch 하나를 잘라낸다. crash 는 그렇게 할 수 없으므로, 복구는 거기서 log 를 자르는 대신
+	시작을 거부해야 하며, 파일을 찾은 그대로 두어야 한다.
+*/
+func TestRecoverLogGap(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	kv, err := OpenKV(dir, 100, time.Second, PersistOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 10; i++ {
+		kv.Increment(ctx, i%3, 1)
+	}
+	kv.Stop(ctx)
+
+	path := filepath.Join(dir, walName(1))
+	log, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ends := batchEnds(t, log)
+	gapped := append(append([]byte(nil), log[:ends[4]]...), log[ends[5]:]...)
+	os.WriteFile(path, gapped, 0o644)
+
+	if _, err := OpenKV(dir, 100, time.Second, PersistOptions{}); !errors.Is(err, errLogGap) {
+		t.Errorf("open: %v, want errLogGap", err)
+	}
+	if after, _ := os.ReadFile(path); !bytes.Equal(after, gapped) {
+		t.Errorf("recovery changed the log from %d to %d bytes", len(gapped), len(after))
+	}
+}
+
+/*
+	TestRecoverCorruptSnapshot damages snapshots after a run that took three. The previous snapshot and the log after it are kept,
+	so losing the newest one falls back to them; losing both leaves records missing, which is an error.
+	TestRecoverCorruptSnapshot 은 세 개의 snapshot 을 찍은 실행 뒤에 snapshot 들을 손상시킨다. 이전 snapshot 과 그 뒤의 log 가 보관되므로,
+	가장 새로운 것을 잃으면 그것들로 물러난다; 둘 다 잃으면 레코드가 빠지게 되며, 이것은 에러이다.
+*/
+func TestRecoverCorruptSnapshot(t *testing.T) {
+	for _, tc := range []struct {
+		name    string
+		corrupt []uint64
+		wantErr error
+	}{
+		{"none", nil, nil},
+		{"newest", []uint64{30}, nil},
+		{"both", []uint64{20, 30}, errLogGap},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := context.Background()
+			dir := t.TempDir()
+			kv, err := OpenKV(dir, 100, time.Second, PersistOptions{SnapshotEvery: 10})
+			if err != nil {
+				t.Fatal(err)
+			}
+			for i := 0; i < 35; i++ {
+				kv.Increment(ctx, i%3, 1)
+			}
+			kv.Stop(ctx)
+			if snaps, _, _ := listPersisted(dir); fmt.Sprint(snaps) != "[20 30]" {
+				t.Fatalf("snapshots %v on disk, want the last two", snaps)
+			}
+
+			for _, seq := range tc.corrupt {
+				path := filepath.Join(dir, snapshotName(seq))
+				buf, _ := os.ReadFile(path)
+				buf[len(buf)/2] ^= 0xff
+				os.WriteFile(path, buf, 0o644)
+			}
+			got, err := recoveredState(dir, 3)
+			if !errors.Is(err, tc.wantErr) {
+				t.Fatalf("recover: %v, want %v", err, tc.wantErr)
+			}
+			if want := map[int]int{0: 12, 1: 12, 2: 11}; err == nil && !maps.Equal(got, want) {
+				t.Errorf("recovered %v, want %v", got, want)
+			}
+		})
+	}
+}
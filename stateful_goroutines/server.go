@@ -0,0 +1,451 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+/*
+	Server puts a KV behind a TCP listener that speaks the part of the Redis protocol (RESP) needed for GET, SET, DEL, INCR, EXPIRE and PING,
+	so redis-cli or a test over loopback can poke the state without a real Redis.
+	Every connection gets its own goroutine, which turns commands into KV calls; the owner goroutine still serializes them all.
+	The map holds ints, so keys and values must be integers; anything else is answered with an error instead of a value.
+	Server 는 KV 를 GET, SET, DEL, INCR, EXPIRE, PING 에 필요한 만큼의 Redis 프로토콜(RESP)을 말하는 TCP listener 뒤에 둔다,
+	그래서 redis-cli 나 loopback 위의 테스트가 진짜 Redis 없이 상태를 건드릴 수 있다.
+	모든 연결은 자신의 고루틴을 가지며, 그 고루틴은 명령을 KV 호출로 바꾼다; 소유자 고루틴은 여전히 그것들 모두를 직렬화한다.
+	map 은 int 를 가지므로 key 와 value 는 정수여야 한다; 다른 것에는 값 대신 에러로 응답한다.
+*/
+type Server struct {
+	kv *KV
+
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	mu     sync.Mutex
+	ln     net.Listener
+	conns  map[net.Conn]struct{}
+	closed bool
+	wg     sync.WaitGroup
+}
+
+func NewServer(kv *KV) *Server {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Server{kv: kv, ctx: ctx, cancel: cancel, conns: make(map[net.Conn]struct{})}
+}
+
+/*
+	Serve accepts connections on ln until Close, which makes it return nil.
+	Serve 는 Close 전까지 ln 에서 연결을 받으며, Close 되면 nil 을 반환한다.
+*/
+func (s *Server) Serve(ln net.Listener) error {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		ln.Close()
+		return nil
+	}
+	s.ln = ln
+	s.mu.Unlock()
+
+	for {
+		conn, err := ln.Accept()
+		if err != nil {
+			s.mu.Lock()
+			closed := s.closed
+			s.mu.Unlock()
+			if closed {
+				return nil
+			}
+			return err
+		}
+		s.mu.Lock()
+		if s.closed {
+			s.mu.Unlock()
+			conn.Close()
+			return nil
+		}
+		s.conns[conn] = struct{}{}
+		s.wg.Add(1)
+		s.mu.Unlock()
+		go s.handle(conn)
+	}
+}
+
+/*
+	Close stops accepting, closes every connection and waits for their goroutines. It does not stop the KV.
+	Close 는 받는 것을 멈추고, 모든 연결을 닫고, 그들의 고루틴을 기다린다. KV 를 멈추지는 않는다.
+*/
+func (s *Server) Close() error {
+	s.mu.Lock()
+	s.closed = true
+	var err error
+	if s.ln != nil {
+		err = s.ln.Close()
+	}
+	for conn := range s.conns {
+		conn.Close()
+	}
+	s.mu.Unlock()
+	s.cancel()
+	s.wg.Wait()
+	return err
+}
+
+/*
+	Replies go into a buffered writer that is flushed only once no more requests are waiting in the reader,
+	so a client that pipelines many commands in one write gets its replies back in one write too.
+	응답은 reader 에 더 이상 기다리는 요청이 없을 때만 flush 되는 버퍼 writer 에 들어간다,
+	그래서 하나의 write 로 많은 명령을 pipeline 하는 클라이언트는 응답도 하나의 write 로 돌려받는다.
+*/
+func (s *Server) handle(conn net.Conn) {
+	defer s.wg.Done()
+	defer func() {
+		s.mu.Lock()
+		delete(s.conns, conn)
+		s.mu.Unlock()
+		conn.Close()
+	}()
+
+	r := bufio.NewReader(conn)
+	w := bufio.NewWriter(conn)
+	for {
+		args, err := readCommand(r)
+		if err != nil {
+			var perr protocolError
+			if errors.As(err, &perr) {
+				writeError(w, "ERR Protocol error: "+string(perr))
+				w.Flush()
+			}
+			return
+		}
+		if len(args) > 0 {
+			s.execute(w, args)
+		}
+		if r.Buffered() == 0 {
+			if err := w.Flush(); err != nil {
+				return
+			}
+		}
+	}
+}
+
+/*
+	arity is the number of arguments each command takes, counting the command name.
+	As in Redis, a negative arity means at least that many arguments.
+	arity 는 명령 이름을 포함해서 각 명령이 받는 인자의 개수이다.
+	Redis 에서처럼, 음수 arity 는 최소한 그만큼의 인자를 뜻한다.
+*/
+var arity = map[string]int{"ping": -1, "get": 2, "set": 3, "del": -2, "incr": 2, "expire": 3}
+
+func (s *Server) execute(w *bufio.Writer, args []string) {
+	name := strings.ToLower(args[0])
+	n, ok := arity[name]
+	if !ok {
+		writeError(w, fmt.Sprintf("ERR unknown command '%s'", args[0]))
+		return
+	}
+	if (n > 0 && len(args) != n) || (n < 0 && len(args) < -n) || (name == "ping" && len(args) > 2) {
+		writeError(w, fmt.Sprintf("ERR wrong number of arguments for '%s' command", name))
+		return
+	}
+
+	if name == "ping" {
+		if len(args) == 2 {
+			writeBulk(w, args[1])
+		} else {
+			writeSimple(w, "PONG")
+		}
+		return
+	}
+
+	keys := make([]int, 0, len(args)-1)
+	keyArgs := args[1:]
+	if name == "set" || name == "expire" {
+		keyArgs = args[1:2]
+	}
+	for _, arg := range keyArgs {
+		key, err := strconv.Atoi(arg)
+		if err != nil {
+			writeError(w, "ERR key is not an integer")
+			return
+		}
+		keys = append(keys, key)
+	}
+
+	ctx := s.ctx
+	switch name {
+	case "get":
+		val, ok, err := s.kv.Lookup(ctx, keys[0])
+		switch {
+		case err != nil:
+			writeError(w, "ERR "+err.Error())
+		case !ok:
+			writeNull(w)
+		default:
+			writeBulk(w, strconv.Itoa(val))
+		}
+	case "set":
+		val, err := strconv.Atoi(args[2])
+		if err != nil {
+			writeError(w, "ERR value is not an integer or out of range")
+			return
+		}
+		if err := s.kv.Set(ctx, keys[0], val); err != nil {
+			writeError(w, "ERR "+err.Error())
+			return
+		}
+		writeSimple(w, "OK")
+	case "del":
+		n, err := s.kv.Delete(ctx, keys...)
+		if err != nil {
+			writeError(w, "ERR "+err.Error())
+			return
+		}
+		writeInt(w, n)
+	case "incr":
+		n, err := s.kv.Increment(ctx, keys[0], 1)
+		if err != nil {
+			writeError(w, "ERR "+err.Error())
+			return
+		}
+		writeInt(w, n)
+	case "expire":
+		secs, err := strconv.Atoi(args[2])
+		if err != nil {
+			writeError(w, "ERR value is not an integer or out of range")
+			return
+		}
+		ok, err := s.kv.Expire(ctx, keys[0], time.Duration(secs)*time.Second)
+		if err != nil {
+			writeError(w, "ERR "+err.Error())
+			return
+		}
+		if ok {
+			writeInt(w, 1)
+		} else {
+			writeInt(w, 0)
+		}
+	}
+}
+
+type protocolError string
+
+func (e protocolError) Error() string {
+	return "protocol error: " + string(e)
+}
+
+/*
+	A client chooses how much the server reads, so every length is capped before anything is allocated for it:
+	a line, the number of arguments, one bulk string, and all the bulk strings of one command together.
+	Without the last cap, maxArgs bulk strings of maxBulkSize each would let one command hold a gigabyte.
+	클라이언트가 서버가 얼마나 읽을지 고르기 때문에, 모든 길이는 그것을 위해 무엇이든 할당하기 전에 제한된다:
+	한 줄, 인자의 개수, bulk string 하나, 그리고 한 명령의 모든 bulk string 의 합.
+	마지막 제한이 없으면, 각각 maxBulkSize 인 maxArgs 개의 bulk string 으로 한 명령이 1GB 를 잡을 수 있다.
+*/
+const (
+	maxLineSize    = 64 << 10
+	maxArgs        = 1024
+	maxBulkSize    = 1 << 20
+	maxCommandSize = 2 << 20
+)
+
+/*
+	readCommand reads one request: either a RESP array of bulk strings, which is what clients send,
+	or an inline command, a line of space-separated words, which is what someone typing into telnet sends.
+	readCommand 는 하나의 요청을 읽는다: 클라이언트들이 보내는 bulk string 들의 RESP 배열이거나,
+	telnet 에 타이핑하는 사람이 보내는 공백으로 구분된 단어들의 줄인 inline 명령이다.
+*/
+func readCommand(r *bufio.Reader) ([]string, error) {
+	line, err := readLine(r)
+	if err != nil {
+		return nil, err
+	}
+	if len(line) == 0 || line[0] != '*' {
+		return strings.Fields(line), nil
+	}
+	n, err := strconv.Atoi(line[1:])
+	if err != nil || n > maxArgs {
+		return nil, protocolError("invalid multibulk length")
+	}
+	args := make([]string, 0, max(n, 0))
+	total := 0
+	for i := 0; i < n; i++ {
+		line, err := readLine(r)
+		if err != nil {
+			return nil, err
+		}
+		if len(line) == 0 || line[0] != '$' {
+			return nil, protocolError(fmt.Sprintf("expected '$', got '%s'", line))
+		}
+		size, err := strconv.Atoi(line[1:])
+		if err != nil || size < 0 || size > maxBulkSize {
+			return nil, protocolError("invalid bulk length")
+		}
+		if total += size; total > maxCommandSize {
+			return nil, protocolError("command too large")
+		}
+		buf := make([]byte, size+2)
+		if _, err := io.ReadFull(r, buf); err != nil {
+			return nil, err
+		}
+		if buf[size] != '\r' || buf[size+1] != '\n' {
+			return nil, protocolError("bulk string not terminated by CRLF")
+		}
+		args = append(args, string(buf[:size]))
+	}
+	return args, nil
+}
+
+/*
+	readLine reads up to maxLineSize bytes looking for the end of the line, so a client that never sends one cannot grow it without bound.
+	readLine 은 줄의 끝을 찾으며 최대 maxLineSize 바이트까지 읽으므로, 줄 끝을 절대 보내지 않는 클라이언트가 그것을 끝없이 키울 수 없다.
+*/
+func readLine(r *bufio.Reader) (string, error) {
+	var line []byte
+	for {
+		chunk, err := r.ReadSlice('\n')
+		if len(line)+len(chunk) > maxLineSize {
+			return "", protocolError("line too long")
+		}
+		line = append(line, chunk...)
+		if err == bufio.ErrBufferFull {
+			continue
+		}
+		if err != nil {
+			return "", err
+		}
+		break
+	}
+	return strings.TrimSuffix(strings.TrimSuffix(string(line), "\n"), "\r"), nil
+}
+
+func writeSimple(w *bufio.Writer, s string) {
+	fmt.Fprintf(w, "+%s\r\n", s)
+}
+
+func writeError(w *bufio.Writer, s string) {
+	fmt.Fprintf(w, "-%s\r\n", s)
+}
+
+func writeInt(w *bufio.Writer, n int) {
+	fmt.Fprintf(w, ":%d\r\n", n)
+}
+
+func writeBulk(w *bufio.Writer, s string) {
+	fmt.Fprintf(w, "$%d\r\n%s\r\n", len(s), s)
+}
+
+func writeNull(w *bufio.Writer) {
+	w.WriteString("$-1\r\n")
+}
+
+/*
+	readReply reads one reply and renders it the way redis-cli does, which is all the example client needs.
+	readReply 는 하나의 응답을 읽고 redis-cli 처럼 표시한다, 예제 클라이언트에게는 그것이면 충분하다.
+*/
+func readReply(r *bufio.Reader) (string, error) {
+	line, err := readLine(r)
+	if err != nil {
+		return "", err
+	}
+	if line == "" {
+		return "", protocolError("empty reply")
+	}
+	switch line[0] {
+	case '+':
+		return line[1:], nil
+	case '-':
+		return "(error) " + line[1:], nil
+	case ':':
+		return "(integer) " + line[1:], nil
+	case '$':
+		size, err := strconv.Atoi(line[1:])
+		if err != nil {
+			return "", protocolError("invalid bulk length")
+		}
+		if size < 0 {
+			return "(nil)", nil
+		}
+		buf := make([]byte, size+2)
+		if _, err := io.ReadFull(r, buf); err != nil {
+			return "", err
+		}
+		return strconv.Quote(string(buf[:size])), nil
+	}
+	return "", protocolError(fmt.Sprintf("unexpected reply '%s'", line))
+}
+
+/*
+	serverExample starts a server on a loopback port and sends it a pipeline of commands in a single write, then reads all the replies.
+	serverExample 은 loopback 포트에서 서버를 시작하고 하나의 write 로 명령들의 pipeline 을 보낸 뒤, 모든 응답을 읽는다.
+*/
+func serverExample(out io.Writer) {
+	kv := NewKV(100, time.Second)
+	defer kv.Stop(context.Background())
+	srv := NewServer(kv)
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		fmt.Fprintln(out, "listen: ", err)
+		return
+	}
+	go srv.Serve(ln)
+	defer srv.Close()
+
+	conn, err := net.Dial("tcp", ln.Addr().String())
+	if err != nil {
+		fmt.Fprintln(out, "dial: ", err)
+		return
+	}
+	defer conn.Close()
+
+	commands := [][]string{
+		{"PING"},
+		{"SET", "1", "10"},
+		{"INCR", "1"},
+		{"GET", "1"},
+		{"GET", "2"},
+		{"SET", "name", "gopher"},
+		{"EXPIRE", "1", "0"},
+		{"GET", "1"},
+		{"SET", "2", "20"},
+		{"DEL", "1", "2", "3"},
+		{"FLUSHALL"},
+	}
+	var req strings.Builder
+	for _, args := range commands {
+		fmt.Fprintf(&req, "*%d\r\n", len(args))
+		for _, arg := range args {
+			fmt.Fprintf(&req, "$%d\r\n%s\r\n", len(arg), arg)
+		}
+	}
+	if _, err := io.WriteString(conn, req.String()); err != nil {
+		fmt.Fprintln(out, "write: ", err)
+		return
+	}
+
+	r := bufio.NewReader(conn)
+	for _, args := range commands {
+		reply, err := readReply(r)
+		if err != nil {
+			fmt.Fprintln(out, "read: ", err)
+			return
+		}
+		fmt.Fprintf(out, "%s -> %s\n", strings.Join(args, " "), reply)
+	}
+
+	/*
+		Inline commands work too.
+		inline 명령도 동작한다.
+	*/
+	io.WriteString(conn, "PING hello\r\n")
+	reply, err := readReply(r)
+	fmt.Fprintln(out, "PING hello (inline) ->", reply, err)
+}
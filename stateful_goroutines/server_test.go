@@ -0,0 +1,120 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"testing"
+	"time"
+)
+
+func bulk(s string) string {
+	return fmt.Sprintf("$%d\r\n%s\r\n", len(s), s)
+}
+
+/*
+	TestReadCommand feeds readCommand one request each and checks the arguments it reads or the protocol error it gives up with.
+	The size limits are checked before anything is read into memory, so the oversized cases fail without the server holding them.
+	TestReadCommand 는 readCommand 에 요청을 하나씩 주고 그것이 읽는 인자나 포기하며 내는 프로토콜 에러를 확인한다.
+	크기 제한은 무엇이든 메모리로 읽기 전에 확인되므로, 너무 큰 경우들은 서버가 그것을 들고 있지 않은 채로 실패한다.
+*/
+func TestReadCommand(t *testing.T) {
+	big := strings.Repeat("x", maxBulkSize)
+	for _, tc := range []struct {
+		name    string
+		req     string
+		want    []string
+		wantErr string
+	}{
+		{"array", "*3\r\n" + bulk("SET") + bulk("1") + bulk("10"), []string{"SET", "1", "10"}, ""},
+		{"inline", "PING hello\r\n", []string{"PING", "hello"}, ""},
+		{"inline without CR", "GET 1\n", []string{"GET", "1"}, ""},
+		{"empty line", "\r\n", []string{}, ""},
+		{"line too long", strings.Repeat("a", maxLineSize+1) + "\r\n", nil, "line too long"},
+		{"line never ends", strings.Repeat("a", 2*maxLineSize), nil, "line too long"},
+		{"too many arguments", fmt.Sprintf("*%d\r\n", maxArgs+1), nil, "invalid multibulk length"},
+		{"bulk too big", fmt.Sprintf("*1\r\n$%d\r\n", maxBulkSize+1), nil, "invalid bulk length"},
+		{"command too big", "*3\r\n" + bulk(big) + bulk(big) + "$1\r\n", nil, "command too large"},
+		{"bulk without CRLF", "*1\r\n$3\r\nGETX\r\n", nil, "bulk string not terminated by CRLF"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			args, err := readCommand(bufio.NewReader(strings.NewReader(tc.req)))
+			var perr protocolError
+			switch {
+			case tc.wantErr != "":
+				if !errors.As(err, &perr) || string(perr) != tc.wantErr {
+					t.Errorf("got %v, want protocol error %q", err, tc.wantErr)
+				}
+			case err != nil:
+				t.Errorf("got %v", err)
+			case fmt.Sprint(args) != fmt.Sprint(tc.want):
+				t.Errorf("got %q, want %q", args, tc.want)
+			}
+		})
+	}
+}
+
+/*
+	TestServer sends each case's requests as one pipelined write over loopback and checks the replies as readReply renders them.
+	A protocol error is answered and then the connection is closed, so the read after it has to hit EOF.
+	Each request ends where the server gives up, since closing a connection with unread input resets it instead of ending it.
+	TestServer 는 각 경우의 요청들을 loopback 위에서 하나의 pipeline write 로 보내고 readReply 가 표시하는 응답을 확인한다.
+	프로토콜 에러에는 응답한 뒤 연결을 닫으므로, 그 뒤의 읽기는 EOF 를 만나야 한다.
+	읽지 않은 입력이 있는 연결을 닫으면 끝나는 대신 reset 되기 때문에, 각 요청은 서버가 포기하는 곳에서 끝난다.
+*/
+func TestServer(t *testing.T) {
+	for _, tc := range []struct {
+		name   string
+		req    string
+		want   []string
+		closed bool
+	}{
+		{"commands", "*3\r\n" + bulk("SET") + bulk("1") + bulk("10") + "INCR 1\r\nGET 1\r\nGET 2\r\nDEL 1 2\r\nPING\r\n",
+			[]string{"OK", "(integer) 11", `"11"`, "(nil)", "(integer) 1", "PONG"}, false},
+		{"bad arguments", "GET\r\nSET name gopher\r\nSET 1 x\r\nFLUSHALL\r\n", []string{
+			"(error) ERR wrong number of arguments for 'get' command",
+			"(error) ERR key is not an integer",
+			"(error) ERR value is not an integer or out of range",
+			"(error) ERR unknown command 'FLUSHALL'",
+		}, false},
+		{"line too long", "PING\r\n" + strings.Repeat("a", maxLineSize+1) + "\r\n",
+			[]string{"PONG", "(error) ERR Protocol error: line too long"}, true},
+		{"too many arguments", fmt.Sprintf("*%d\r\n", maxArgs+1),
+			[]string{"(error) ERR Protocol error: invalid multibulk length"}, true},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			kv := NewKV(100, time.Second)
+			defer kv.Stop(context.Background())
+			srv := NewServer(kv)
+			ln, err := net.Listen("tcp", "127.0.0.1:0")
+			if err != nil {
+				t.Fatal(err)
+			}
+			go srv.Serve(ln)
+			defer srv.Close()
+
+			conn, err := net.Dial("tcp", ln.Addr().String())
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer conn.Close()
+			go io.WriteString(conn, tc.req)
+
+			r := bufio.NewReader(conn)
+			for _, want := range tc.want {
+				if got, err := readReply(r); got != want || err != nil {
+					t.Fatalf("got %q, %v, want %q", got, err, want)
+				}
+			}
+			if tc.closed {
+				if _, err := readReply(r); err != io.EOF {
+					t.Errorf("read after the protocol error: %v, want EOF", err)
+				}
+			}
+		})
+	}
+}
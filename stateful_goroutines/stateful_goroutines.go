@@ -1,8 +1,10 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"math/rand"
+	"os"
 	"sync/atomic"
 	"time"
 )
@@ -136,4 +138,82 @@ func main() {
 		예를 들어 다른 채널이 관련되거나 그러한 mutex를 여러 개 관리할 경우 오류가 발생하기 쉬운 경우에 유용할 수 있다.
 		특히 프로그램의 정확성을 이해하는 데 있어 가장 자연스럽게 느껴지는 접근법을 사용해야 한다.
 	*/
+
+	/*
+		KV packages the owner goroutine as an Actor, so here is the same harness with none of the select loop written out.
+		Get and Set take a context, and Stop drains what is already queued before the owner exits.
+		KV 는 소유자 고루틴을 Actor 로 포장하므로, 여기에 select 반복문을 하나도 작성하지 않은 같은 장치가 있다.
+		Get 과 Set 은 context 를 받고, Stop 은 소유자가 종료하기 전에 이미 줄 서 있는 것을 비운다.
+	*/
+	kv := NewKV(100, time.Second)
+	ctx, cancel := context.WithCancel(context.Background())
+	var kvReadOps, kvWriteOps uint64
+	for r := 0; r < 100; r++ {
+		go func() {
+			for {
+				if _, err := kv.Get(ctx, rand.Intn(5)); err != nil {
+					return
+				}
+				atomic.AddUint64(&kvReadOps, 1)
+				time.Sleep(time.Millisecond)
+			}
+		}()
+	}
+	for w := 0; w < 100; w++ {
+		go func() {
+			for {
+				if err := kv.Set(ctx, rand.Intn(5), rand.Intn(100)); err != nil {
+					return
+				}
+				atomic.AddUint64(&kvWriteOps, 1)
+				time.Sleep(time.Millisecond)
+			}
+		}()
+	}
+	time.Sleep(time.Second)
+	cancel()
+	fmt.Println("kv readOps: ", atomic.LoadUint64(&kvReadOps))
+	fmt.Println("kv writeOps: ", atomic.LoadUint64(&kvWriteOps))
+
+	if err := kv.Stop(context.Background()); err != nil {
+		fmt.Println("stop: ", err)
+	}
+	_, err := kv.Get(context.Background(), 0)
+	fmt.Println("get after stop: ", err)
+
+	/*
+		A crash is worse than a Stop: nobody drains the mailbox. A supervisor turns it into an error for callers and a fresh owner.
+		crash 는 Stop 보다 나쁘다: 아무도 mailbox 를 비우지 않는다. supervisor 는 그것을 호출자들에게는 에러로, 그리고 새 소유자로 바꾼다.
+	*/
+	supervisorExample(os.Stdout)
+
+	/*
+		Because the owner applies one op at a time, an op that reads and writes several keys is a transaction.
+		소유자는 한 번에 하나의 op 만 적용하기 때문에, 여러 key 를 읽고 쓰는 op 은 트랜잭션이다.
+	*/
+	inventoryExample(os.Stdout)
+
+	/*
+		The same messages can come from the network: one goroutine per connection turns RESP commands into KV calls.
+		같은 메시지들은 네트워크에서 올 수도 있다: 연결마다 하나의 고루틴이 RESP 명령을 KV 호출로 바꾼다.
+	*/
+	serverExample(os.Stdout)
+
+	/*
+		Rather than polling with readOp, consumers can have the owner tell them about every write.
+		readOp 으로 polling 하는 대신, 소비자들은 소유자가 모든 쓰기에 대해 알려주게 할 수 있다.
+	*/
+	watchExample(os.Stdout)
+
+	/*
+		With deadlines on keys the KV works as a session cache; a timer in the owner evicts sessions nobody reads again.
+		key 에 기한이 있으면 KV 는 세션 캐시로 동작한다; 소유자 안의 타이머가 아무도 다시 읽지 않는 세션들을 내보낸다.
+	*/
+	sessionCacheExample(os.Stdout)
+
+	/*
+		None of the above survives the process; a KV opened on a directory does.
+		위의 어떤 것도 프로세스보다 오래 살지 못한다; 디렉터리 위에 열린 KV 는 살아남는다.
+	*/
+	persistExample(os.Stdout)
 }
@@ -0,0 +1,424 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"runtime/debug"
+	"sync/atomic"
+	"time"
+
+	"gobyexample/internal/clock"
+)
+
+/*
+	If the state-owning goroutine in main panics, the whole program goes down; if it merely stopped, every later `reads <- read` would block forever.
+	Supervisor runs goroutines as children, turns their panics into errors and restarts them, the way Erlang supervisors do:
+	OneForOne restarts only the child that failed, OneForAll restarts every child, and RestForOne restarts the failed child and every child added after it.
+	If more than maxRestarts happen within period the supervisor gives up, stops all children and returns ErrTooManyRestarts.
+	main 의 상태를 소유하는 고루틴이 panic 하면 프로그램 전체가 죽는다; 그냥 멈춘 것이라면 이후의 모든 `reads <- read` 는 영원히 막힐 것이다.
+	Supervisor 는 고루틴들을 자식으로 실행하고, 그들의 panic 을 에러로 바꾸고, Erlang 의 supervisor 처럼 그들을 재시작한다:
+	OneForOne 은 실패한 자식만 재시작하고, OneForAll 은 모든 자식을 재시작하며, RestForOne 은 실패한 자식과 그 뒤에 추가된 모든 자식을 재시작한다.
+	period 안에 maxRestarts 번보다 많은 재시작이 일어나면 supervisor 는 포기하고, 모든 자식을 멈춘 뒤 ErrTooManyRestarts 를 반환한다.
+*/
+type Strategy int
+
+const (
+	OneForOne Strategy = iota
+	OneForAll
+	RestForOne
+)
+
+var ErrTooManyRestarts = errors.New("supervisor: too many restarts")
+
+/*
+	PanicError is what a child's panic becomes, with the stack where it happened.
+	PanicError 는 자식의 panic 이 변한 것으로, panic 이 일어난 곳의 스택을 가진다.
+*/
+type PanicError struct {
+	Value any
+	Stack []byte
+}
+
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("panic: %v", e.Value)
+}
+
+/*
+	A child runs until ctx is done. Returning nil means it finished and is not restarted; returning an error or panicking means it failed.
+	A child has to return when ctx is done, or OneForAll and RestForOne cannot restart it.
+	자식은 ctx 가 끝날 때까지 실행된다. nil 을 반환하면 끝났다는 뜻이고 재시작되지 않는다; 에러를 반환하거나 panic 하면 실패했다는 뜻이다.
+	자식은 ctx 가 끝나면 반환해야 하며, 그렇지 않으면 OneForAll 과 RestForOne 이 그것을 재시작할 수 없다.
+*/
+type Child struct {
+	Name string
+	Run  func(ctx context.Context) error
+}
+
+/*
+	RestartEvent reports one restart: which child failed and why, and which children are restarted after what backoff.
+	RestartEvent 는 한 번의 재시작을 알려준다: 어떤 자식이 왜 실패했는지, 그리고 어떤 자식들이 얼마의 backoff 후에 재시작되는지.
+*/
+type RestartEvent struct {
+	Child     string
+	Err       error
+	Restarted []string
+	Backoff   time.Duration
+}
+
+/*
+	defaultMinBackoff is the first backoff when NewSupervisor is given none; doubling a zero backoff would never leave zero.
+	defaultMinBackoff 는 NewSupervisor 가 아무것도 받지 못했을 때의 첫 backoff 이다; 0인 backoff 는 두 배로 해도 0을 벗어나지 못한다.
+*/
+const defaultMinBackoff = 10 * time.Millisecond
+
+type Supervisor struct {
+	clock       clock.Clock
+	strategy    Strategy
+	maxRestarts int
+	period      time.Duration
+	minBackoff  time.Duration
+	maxBackoff  time.Duration
+	children    []Child
+
+	/*
+		OnRestart, if set, is called for every restart before the backoff.
+		OnRestart 가 설정되어 있으면 모든 재시작마다 backoff 전에 호출된다.
+	*/
+	OnRestart func(RestartEvent)
+}
+
+/*
+	NewSupervisor allows maxRestarts within period. The backoff before a restart doubles with every restart still inside the period,
+	starting at minBackoff and capped at maxBackoff. A minBackoff of zero or less starts at defaultMinBackoff, and a maxBackoff below minBackoff is raised to it.
+	NewSupervisor 는 period 안에 maxRestarts 번을 허용한다. 재시작 전의 backoff 는 아직 period 안에 있는 재시작마다 두 배가 되며,
+	minBackoff 에서 시작하고 maxBackoff 로 제한된다. 0 이하의 minBackoff 는 defaultMinBackoff 에서 시작하고, minBackoff 보다 작은 maxBackoff 는 그것까지 올려진다.
+*/
+func NewSupervisor(strategy Strategy, maxRestarts int, period, minBackoff, maxBackoff time.Duration) *Supervisor {
+	return NewSupervisorWithClock(clock.Real{}, strategy, maxRestarts, period, minBackoff, maxBackoff)
+}
+
+/*
+	NewSupervisorWithClock is NewSupervisor on the given clock, which measures the period and times the backoffs.
+	NewSupervisorWithClock 은 주어진 clock 위의 NewSupervisor 이며, 그 clock 이 period 를 재고 backoff 의 시간을 잰다.
+*/
+func NewSupervisorWithClock(clk clock.Clock, strategy Strategy, maxRestarts int, period, minBackoff, maxBackoff time.Duration) *Supervisor {
+	if minBackoff <= 0 {
+		minBackoff = defaultMinBackoff
+	}
+	if maxBackoff < minBackoff {
+		maxBackoff = minBackoff
+	}
+	return &Supervisor{
+		clock:       clk,
+		strategy:    strategy,
+		maxRestarts: maxRestarts,
+		period:      period,
+		minBackoff:  minBackoff,
+		maxBackoff:  maxBackoff,
+	}
+}
+
+/*
+	Add must be called before Run; the order children are added in is the order RestForOne uses.
+	Add 는 Run 전에 호출되어야 한다; 자식들이 추가된 순서가 RestForOne 이 사용하는 순서이다.
+*/
+func (s *Supervisor) Add(child Child) {
+	s.children = append(s.children, child)
+}
+
+type childExit struct {
+	index int
+	gen   int
+	err   error
+}
+
+type runningChild struct {
+	gen     int
+	cancel  context.CancelFunc
+	running bool
+}
+
+/*
+	Run starts every child and supervises them until ctx is done, which stops them all and returns nil,
+	or until the restart intensity is exceeded.
+	Run 은 모든 자식을 시작하고 ctx 가 끝날 때까지(모두를 멈추고 nil 을 반환한다) 또는 재시작 강도를 넘을 때까지 그들을 감독한다.
+*/
+func (s *Supervisor) Run(ctx context.Context) error {
+	exits := make(chan childExit)
+	running := make([]runningChild, len(s.children))
+	start := func(i int) {
+		childCtx, cancel := context.WithCancel(ctx)
+		running[i].gen++
+		running[i].cancel = cancel
+		running[i].running = true
+		go runChild(childCtx, i, running[i].gen, s.children[i].Run, exits)
+	}
+	/*
+		stop cancels a child and waits for it, so a restarted child never overlaps with its previous run.
+		Exits of other children that arrive meanwhile are kept and handled afterwards.
+		stop 은 자식을 취소하고 기다리므로, 재시작된 자식은 절대 이전 실행과 겹치지 않는다.
+		그 사이에 도착한 다른 자식들의 종료는 보관되었다가 나중에 처리된다.
+	*/
+	var deferred []childExit
+	stop := func(i int) {
+		if !running[i].running {
+			return
+		}
+		running[i].cancel()
+		for j, e := range deferred {
+			if e.index == i && e.gen == running[i].gen {
+				deferred = append(deferred[:j], deferred[j+1:]...)
+				running[i].running = false
+				return
+			}
+		}
+		for {
+			e := <-exits
+			if e.index == i && e.gen == running[i].gen {
+				break
+			}
+			deferred = append(deferred, e)
+		}
+		running[i].running = false
+	}
+	stopAll := func() {
+		for i := len(running) - 1; i >= 0; i-- {
+			stop(i)
+		}
+	}
+
+	for i := range s.children {
+		start(i)
+	}
+
+	var restarts []time.Time
+	for {
+		var e childExit
+		if len(deferred) > 0 {
+			e, deferred = deferred[0], deferred[1:]
+		} else {
+			select {
+			case <-ctx.Done():
+				stopAll()
+				return nil
+			case e = <-exits:
+			}
+		}
+		if e.gen != running[e.index].gen || !running[e.index].running {
+			continue
+		}
+		running[e.index].running = false
+		running[e.index].cancel()
+		if e.err == nil || ctx.Err() != nil {
+			continue
+		}
+
+		now := s.clock.Now()
+		recent := restarts[:0]
+		for _, t := range restarts {
+			if now.Sub(t) < s.period {
+				recent = append(recent, t)
+			}
+		}
+		restarts = append(recent, now)
+		if len(restarts) > s.maxRestarts {
+			stopAll()
+			return fmt.Errorf("%w: %s: %w", ErrTooManyRestarts, s.children[e.index].Name, e.err)
+		}
+
+		var group []int
+		switch s.strategy {
+		case OneForOne:
+			group = []int{e.index}
+		case OneForAll:
+			for i := range s.children {
+				group = append(group, i)
+			}
+		case RestForOne:
+			for i := e.index; i < len(s.children); i++ {
+				group = append(group, i)
+			}
+		}
+		for i := len(group) - 1; i >= 0; i-- {
+			stop(group[i])
+		}
+
+		backoff := s.backoff(len(restarts))
+		if s.OnRestart != nil {
+			names := make([]string, len(group))
+			for j, i := range group {
+				names[j] = s.children[i].Name
+			}
+			s.OnRestart(RestartEvent{Child: s.children[e.index].Name, Err: e.err, Restarted: names, Backoff: backoff})
+		}
+
+		timer := s.clock.NewTimer(backoff)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			stopAll()
+			return nil
+		case <-timer.C():
+		}
+		for _, i := range group {
+			start(i)
+		}
+	}
+}
+
+/*
+	backoff is the delay before the nth restart within the period. It doubles step by step instead of shifting,
+	so a long run of restarts stops at maxBackoff rather than overflowing.
+	backoff 는 period 안의 n 번째 재시작 전의 지연이다. shift 하는 대신 한 단계씩 두 배로 하므로,
+	긴 재시작의 연속은 넘치는 대신 maxBackoff 에서 멈춘다.
+*/
+func (s *Supervisor) backoff(n int) time.Duration {
+	d := s.minBackoff
+	for i := 1; i < n && d < s.maxBackoff; i++ {
+		if d > s.maxBackoff/2 {
+			return s.maxBackoff
+		}
+		d *= 2
+	}
+	return d
+}
+
+func runChild(ctx context.Context, index, gen int, run func(context.Context) error, exits chan<- childExit) {
+	err := func() (err error) {
+		defer func() {
+			if r := recover(); r != nil {
+				err = &PanicError{Value: r, Stack: debug.Stack()}
+			}
+		}()
+		return run(ctx)
+	}()
+	exits <- childExit{index: index, gen: gen, err: err}
+}
+
+/*
+	SupervisedKV is a KV whose owner runs as a supervised child. Every run starts a fresh KV and publishes it,
+	so a crash costs the state but not the service: callers get ErrActorCrashed until the restart, then talk to the new owner.
+	SupervisedKV 는 소유자가 감독되는 자식으로 실행되는 KV 이다. 매 실행은 새 KV 를 시작하고 그것을 공개하므로,
+	crash 는 상태를 잃게 하지만 서비스를 잃게 하지는 않는다: 호출자들은 재시작 전까지 ErrActorCrashed 를 받고, 그 뒤에는 새 소유자와 이야기한다.
+*/
+type SupervisedKV struct {
+	size    int
+	timeout time.Duration
+	current atomic.Pointer[KV]
+}
+
+func NewSupervisedKV(size int, timeout time.Duration) *SupervisedKV {
+	return &SupervisedKV{size: size, timeout: timeout}
+}
+
+func (s *SupervisedKV) Child(name string) Child {
+	return Child{Name: name, Run: func(ctx context.Context) error {
+		kv := NewKV(s.size, s.timeout)
+		s.current.Store(kv)
+		select {
+		case <-ctx.Done():
+			return kv.Stop(context.Background())
+		case <-kv.Done():
+			return kv.Err()
+		}
+	}}
+}
+
+func (s *SupervisedKV) Get(ctx context.Context, key int) (int, error) {
+	kv := s.current.Load()
+	if kv == nil {
+		return 0, ErrActorStopped
+	}
+	return kv.Get(ctx, key)
+}
+
+func (s *SupervisedKV) Set(ctx context.Context, key, val int) error {
+	kv := s.current.Load()
+	if kv == nil {
+		return ErrActorStopped
+	}
+	return kv.Set(ctx, key, val)
+}
+
+/*
+	supervisorExample crashes a supervised KV, shows which children each strategy restarts,
+	and gives up on a child that fails every time.
+	supervisorExample 은 감독되는 KV 를 망가뜨리고, 각 전략이 어떤 자식들을 재시작하는지 보여주며,
+	매번 실패하는 자식을 포기한다.
+*/
+func supervisorExample(out io.Writer) {
+	skv := NewSupervisedKV(100, time.Second)
+	sup := NewSupervisor(OneForOne, 3, time.Second, 10*time.Millisecond, 100*time.Millisecond)
+	sup.Add(skv.Child("kv"))
+	restarted := make(chan RestartEvent, 1)
+	sup.OnRestart = func(e RestartEvent) { restarted <- e }
+	ctx, cancel := context.WithCancel(context.Background())
+	result := make(chan error)
+	go func() { result <- sup.Run(ctx) }()
+
+	for skv.current.Load() == nil {
+		time.Sleep(time.Millisecond)
+	}
+	first := skv.current.Load()
+	skv.Set(ctx, 1, 10)
+	first.actor.Send(ctx, panicOp{reason: "corrupted state"})
+	<-first.Done()
+	_, err := skv.Get(ctx, 1)
+	fmt.Fprintln(out, "get after crash: ", err)
+	e := <-restarted
+	fmt.Fprintf(out, "restart %s: %v\n", e.Child, e.Err)
+	for skv.current.Load() == first {
+		time.Sleep(time.Millisecond)
+	}
+	val, err := skv.Get(ctx, 1)
+	fmt.Fprintln(out, "get after restart: ", val, err)
+	cancel()
+	fmt.Fprintln(out, "supervisor: ", <-result)
+
+	/*
+		b fails on its first run; the event names the children each strategy restarts with it.
+		b 는 첫 실행에서 실패한다; 이벤트는 각 전략이 그것과 함께 재시작하는 자식들의 이름을 알려준다.
+	*/
+	for _, strategy := range []struct {
+		name     string
+		strategy Strategy
+	}{
+		{"OneForOne", OneForOne},
+		{"OneForAll", OneForAll},
+		{"RestForOne", RestForOne},
+	} {
+		sup := NewSupervisor(strategy.strategy, 3, time.Second, time.Millisecond, 10*time.Millisecond)
+		var failed atomic.Bool
+		for _, name := range []string{"a", "b", "c"} {
+			sup.Add(Child{Name: name, Run: func(ctx context.Context) error {
+				if name == "b" && failed.CompareAndSwap(false, true) {
+					return errors.New("boom")
+				}
+				<-ctx.Done()
+				return nil
+			}})
+		}
+		restarted := make(chan RestartEvent, 1)
+		sup.OnRestart = func(e RestartEvent) { restarted <- e }
+		ctx, cancel := context.WithCancel(context.Background())
+		result := make(chan error)
+		go func() { result <- sup.Run(ctx) }()
+		e := <-restarted
+		cancel()
+		<-result
+		fmt.Fprintf(out, "%s: %s failed, restarted %v\n", strategy.name, e.Child, e.Restarted)
+	}
+
+	/*
+		A child that panics every time exceeds 3 restarts per second long before the second is up.
+		매번 panic 하는 자식은 1초가 지나기 훨씬 전에 초당 3번의 재시작을 넘는다.
+	*/
+	sup = NewSupervisor(OneForOne, 3, time.Second, time.Millisecond, 10*time.Millisecond)
+	sup.Add(Child{Name: "flaky", Run: func(ctx context.Context) error {
+		panic("always")
+	}})
+	err = sup.Run(context.Background())
+	fmt.Fprintln(out, "too many restarts: ", errors.Is(err, ErrTooManyRestarts), err)
+}
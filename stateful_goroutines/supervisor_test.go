@@ -0,0 +1,135 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"gobyexample/internal/clock"
+)
+
+/*
+	TestSupervisorBackoff runs a child that fails as soon as it starts on a Fake clock. Before each restart the test waits for the backoff timer
+	and advances exactly past it, so the child's runs start at the sums of the backoffs. The period is long enough that every restart counts,
+	so the backoff doubles up to maxBackoff until the restart after the last allowed one ends in ErrTooManyRestarts.
+	TestSupervisorBackoff 는 Fake clock 위에서 시작하자마자 실패하는 자식을 실행한다. 각 재시작 전에 테스트는 backoff timer 를 기다리고
+	정확히 그것을 지나도록 advance 하므로, 자식의 실행들은 backoff 들의 합에서 시작한다. period 는 모든 재시작이 세어질 만큼 길어서,
+	backoff 는 maxBackoff 까지 두 배가 되다가 허용된 마지막 재시작 다음의 재시작이 ErrTooManyRestarts 로 끝난다.
+*/
+func TestSupervisorBackoff(t *testing.T) {
+	ms := time.Millisecond
+	for _, tc := range []struct {
+		name        string
+		maxRestarts int
+		minBackoff  time.Duration
+		maxBackoff  time.Duration
+		want        []time.Duration
+	}{
+		{"doubles up to the cap", 5, 10 * ms, 50 * ms, []time.Duration{10 * ms, 20 * ms, 40 * ms, 50 * ms, 50 * ms}},
+		{"zero min backoff", 3, 0, time.Second, []time.Duration{defaultMinBackoff, 2 * defaultMinBackoff, 4 * defaultMinBackoff}},
+		{"max below min", 3, 100 * ms, 0, []time.Duration{100 * ms, 100 * ms, 100 * ms}},
+		{"no restarts", 0, 10 * ms, 50 * ms, nil},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			start := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+			fake := clock.NewFake(start)
+			sup := NewSupervisorWithClock(fake, OneForOne, tc.maxRestarts, time.Hour, tc.minBackoff, tc.maxBackoff)
+			var mu sync.Mutex
+			var runs, backoffs []time.Duration
+			sup.Add(Child{Name: "failing", Run: func(ctx context.Context) error {
+				mu.Lock()
+				defer mu.Unlock()
+				runs = append(runs, fake.Since(start))
+				return errors.New("boom")
+			}})
+			sup.OnRestart = func(e RestartEvent) {
+				mu.Lock()
+				defer mu.Unlock()
+				backoffs = append(backoffs, e.Backoff)
+			}
+			result := make(chan error)
+			go func() { result <- sup.Run(context.Background()) }()
+
+			var wantRuns []time.Duration
+			var at time.Duration
+			for _, d := range tc.want {
+				wantRuns = append(wantRuns, at)
+				fake.BlockUntil(1)
+				fake.Advance(d)
+				at += d
+			}
+			wantRuns = append(wantRuns, at)
+
+			if err := <-result; !errors.Is(err, ErrTooManyRestarts) {
+				t.Errorf("Run: %v, want ErrTooManyRestarts", err)
+			}
+			if !slices.Equal(backoffs, tc.want) {
+				t.Errorf("backoffs %v, want %v", backoffs, tc.want)
+			}
+			if !slices.Equal(runs, wantRuns) {
+				t.Errorf("runs at %v, want %v", runs, wantRuns)
+			}
+		})
+	}
+}
+
+/*
+	TestSupervisorIntensity fails a child on demand and moves a Fake clock between failures.
+	Two restarts within a second are allowed; once a second has passed they are forgotten, so the backoff starts over,
+	and it takes three failures inside one second to make the supervisor give up.
+	TestSupervisorIntensity 는 필요할 때 자식을 실패시키고 실패들 사이에 Fake clock 을 움직인다.
+	1초 안에 두 번의 재시작은 허용된다; 1초가 지나면 그것들은 잊혀지므로 backoff 는 다시 시작하고,
+	supervisor 가 포기하게 하려면 1초 안에 세 번의 실패가 필요하다.
+*/
+func TestSupervisorIntensity(t *testing.T) {
+	ms := time.Millisecond
+	fake := clock.NewFake(time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC))
+	sup := NewSupervisorWithClock(fake, OneForOne, 2, time.Second, 100*ms, time.Second)
+	fail := make(chan error)
+	var runs int
+	sup.Add(Child{Name: "flaky", Run: func(ctx context.Context) error {
+		runs++
+		select {
+		case err := <-fail:
+			return err
+		case <-ctx.Done():
+			return nil
+		}
+	}})
+	restarted := make(chan RestartEvent, 1)
+	sup.OnRestart = func(e RestartEvent) { restarted <- e }
+	result := make(chan error)
+	go func() { result <- sup.Run(context.Background()) }()
+
+	errBoom := errors.New("boom")
+	for _, tc := range []struct {
+		name    string
+		idle    time.Duration
+		backoff time.Duration
+	}{
+		{"first failure", 0, 100 * ms},
+		{"second failure within the period", 0, 200 * ms},
+		{"a second later", time.Second, 100 * ms},
+		{"again within the period", 0, 200 * ms},
+	} {
+		fake.Advance(tc.idle)
+		fail <- errBoom
+		e := <-restarted
+		if e.Backoff != tc.backoff || !errors.Is(e.Err, errBoom) {
+			t.Errorf("%s: restart %+v, want a backoff of %v", tc.name, e, tc.backoff)
+		}
+		fake.BlockUntil(1)
+		fake.Advance(e.Backoff)
+	}
+
+	fail <- errBoom
+	if err := <-result; !errors.Is(err, ErrTooManyRestarts) || !errors.Is(err, errBoom) {
+		t.Errorf("third failure within the period: %v, want ErrTooManyRestarts wrapping boom", err)
+	}
+	if runs != 5 {
+		t.Errorf("ran %d times, want the first run and 4 restarts", runs)
+	}
+}
@@ -0,0 +1,222 @@
+package main
+
+import (
+	"container/heap"
+	"context"
+	"fmt"
+	"io"
+	"time"
+)
+
+/*
+	Removing expired keys in get is enough for correctness, but a session that nobody reads again would sit in the map forever.
+	So the owner also keeps a min-heap of deadlines and one timer for the earliest of them; when it fires, an expireDueOp goes through the mailbox
+	like any other request and the owner evicts every key that is due, then arms the timer for the next deadline.
+	Each deadline knows its place in the heap, so a refreshed deadline is moved and a deleted key's is removed, and the heap holds at most one per key.
+	get 안에서 만료된 key 를 제거하는 것은 정확성에는 충분하지만, 아무도 다시 읽지 않는 세션은 map 에 영원히 남아 있을 것이다.
+	그래서 소유자는 기한들의 min-heap 과 그중 가장 이른 것을 위한 타이머 하나를 가진다; 타이머가 울리면 expireDueOp 이 다른 요청처럼 mailbox 를 지나가고
+	소유자는 기한이 된 모든 key 를 내보낸 뒤, 다음 기한을 위해 타이머를 맞춘다.
+	각 기한은 heap 안에서의 자기 위치를 알기 때문에, 갱신된 기한은 옮겨지고 삭제된 key 의 기한은 제거되며, heap 은 key 하나당 최대 하나를 가진다.
+*/
+
+type deadline struct {
+	key   int
+	at    time.Time
+	index int
+}
+
+type deadlineHeap []*deadline
+
+func (h deadlineHeap) Len() int           { return len(h) }
+func (h deadlineHeap) Less(i, j int) bool { return h[i].at.Before(h[j].at) }
+func (h deadlineHeap) Swap(i, j int) {
+	h[i], h[j] = h[j], h[i]
+	h[i].index = i
+	h[j].index = j
+}
+func (h *deadlineHeap) Push(x any) {
+	d := x.(*deadline)
+	d.index = len(*h)
+	*h = append(*h, d)
+}
+func (h *deadlineHeap) Pop() any {
+	old := *h
+	d := old[len(old)-1]
+	old[len(old)-1] = nil
+	*h = old[:len(old)-1]
+	return d
+}
+
+/*
+	TTLStats counts reads that found a key, reads that did not, and keys removed because their deadline passed,
+	either lazily by a read or write that got there first or by the timer. A read of an expired key is a miss as well as a lazy expiry.
+	TTLStats 는 key 를 찾은 읽기, 찾지 못한 읽기, 그리고 기한이 지나서 제거된 key 들을 센다,
+	먼저 도착한 읽기나 쓰기에 의해 지연해서든 타이머에 의해서든. 만료된 key 의 읽기는 miss 이면서 지연 만료이기도 하다.
+*/
+type TTLStats struct {
+	Hits, Misses                  int
+	ExpiredLazily, ExpiredByTimer int
+}
+
+/*
+	expireAt records a deadline for key and moves the timer earlier if this is now the first one due.
+	expireAt 은 key 의 기한을 기록하고, 이것이 이제 가장 먼저 올 기한이면 타이머를 앞당긴다.
+*/
+func (s *kvState) expireAt(key int, at time.Time) {
+	s.log(walRecord{kind: recExpire, key: key, at: at.UnixNano(), rev: s.revision})
+	s.restoreDeadline(key, at)
+	if s.timerAt.IsZero() || at.Before(s.timerAt) {
+		s.arm(at)
+	}
+}
+
+/*
+	restoreDeadline records a deadline without logging it or touching the timer, for recovery and for expireAt.
+	restoreDeadline 은 기한을 log 에 쓰거나 타이머를 건드리지 않고 기록하며, 복구와 expireAt 을 위한 것이다.
+*/
+func (s *kvState) restoreDeadline(key int, at time.Time) {
+	if d, ok := s.expires[key]; ok {
+		d.at = at
+		heap.Fix(&s.deadlines, d.index)
+		return
+	}
+	d := &deadline{key: key, at: at}
+	s.expires[key] = d
+	heap.Push(&s.deadlines, d)
+}
+
+/*
+	clearDeadline forgets key's deadline, if it has one, without logging it.
+	clearDeadline 은 key 의 기한이 있다면 log 에 쓰지 않고 그것을 잊는다.
+*/
+func (s *kvState) clearDeadline(key int) {
+	if d, ok := s.expires[key]; ok {
+		heap.Remove(&s.deadlines, d.index)
+		delete(s.expires, key)
+	}
+}
+
+/*
+	persist removes key's deadline, the way SET does in Redis.
+	persist 는 Redis 의 SET 처럼 key 의 기한을 제거한다.
+*/
+func (s *kvState) persist(key int) {
+	if _, ok := s.expires[key]; !ok {
+		return
+	}
+	s.clearDeadline(key)
+	s.log(walRecord{kind: recPersist, key: key, rev: s.revision})
+}
+
+func (s *kvState) arm(at time.Time) {
+	s.timerAt = at
+	s.timer.Reset(s.clock.Until(at))
+}
+
+/*
+	expireDue evicts every key whose deadline has passed and arms the timer for the earliest deadline left.
+	expireDue 는 기한이 지난 모든 key 를 내보내고 남은 것 중 가장 이른 기한을 위해 타이머를 맞춘다.
+*/
+func (s *kvState) expireDue(now time.Time) {
+	for len(s.deadlines) > 0 {
+		d := s.deadlines[0]
+		if now.Before(d.at) {
+			s.arm(d.at)
+			return
+		}
+		s.del(d.key)
+		s.stats.ExpiredByTimer++
+	}
+	s.timerAt = time.Time{}
+}
+
+/*
+	read is get for the read paths, which are the ones that count hits and misses.
+	read 는 읽기 경로를 위한 get 으로, hit 과 miss 를 세는 것은 그 경로들이다.
+*/
+func (s *kvState) read(key int) (int, bool) {
+	val, ok := s.get(key)
+	if ok {
+		s.stats.Hits++
+	} else {
+		s.stats.Misses++
+	}
+	return val, ok
+}
+
+type expireDueOp struct{}
+
+func (op expireDueOp) apply(state *kvState) {
+	state.expireDue(state.clock.Now())
+}
+
+type setExOp struct {
+	key, val int
+	at       time.Time
+	resp     chan bool
+}
+
+func (op setExOp) apply(state *kvState) {
+	state.set(op.key, op.val)
+	state.expireAt(op.key, op.at)
+	reply(state, op.resp, true)
+}
+
+type statsOp struct {
+	resp chan TTLStats
+}
+
+func (op statsOp) apply(state *kvState) {
+	op.resp <- state.stats
+}
+
+/*
+	SetWithTTL is Set followed by Expire, done as one op so no reader can see the key without its deadline.
+	SetWithTTL 은 Set 뒤의 Expire 를 하나의 op 으로 한 것이어서, 어떤 reader 도 기한이 없는 key 를 볼 수 없다.
+*/
+func (kv *KV) SetWithTTL(ctx context.Context, key, val int, ttl time.Duration) error {
+	at := kv.clock.Now().Add(ttl)
+	_, err := Ask(ctx, kv.actor, kv.timeout, func(resp chan bool) kvOp {
+		return setExOp{key: key, val: val, at: at, resp: resp}
+	})
+	return err
+}
+
+func (kv *KV) TTLStats(ctx context.Context) (TTLStats, error) {
+	return Ask(ctx, kv.actor, kv.timeout, func(resp chan TTLStats) kvOp {
+		return statsOp{resp: resp}
+	})
+}
+
+/*
+	sessionCacheExample uses the KV as a session cache: session ids map to user ids for a short TTL,
+	some sessions are read after they expire and the rest are never read again, so only the timer can remove them.
+	sessionCacheExample 은 KV 를 세션 캐시로 사용한다: 세션 id 는 짧은 TTL 동안 사용자 id 에 대응하며,
+	어떤 세션들은 만료된 뒤에 읽히고 나머지는 다시 읽히지 않으므로, 타이머만이 그것들을 제거할 수 있다.
+*/
+func sessionCacheExample(out io.Writer) {
+	kv := NewKV(100, time.Second)
+	defer kv.Stop(context.Background())
+	ctx := context.Background()
+
+	for session := 1; session <= 10; session++ {
+		kv.SetWithTTL(ctx, session, 1000+session, time.Duration(session)*10*time.Millisecond)
+	}
+	for session := 1; session <= 10; session++ {
+		kv.Get(ctx, session)
+	}
+	time.Sleep(35 * time.Millisecond)
+	for session := 1; session <= 5; session++ {
+		if user, ok, _ := kv.Lookup(ctx, session); ok {
+			fmt.Fprintf(out, "session %d: user %d\n", session, user)
+		}
+	}
+	time.Sleep(100 * time.Millisecond)
+	for session := 1; session <= 10; session++ {
+		if _, ok, _ := kv.Lookup(ctx, session); ok {
+			fmt.Fprintln(out, "session still cached: ", session)
+		}
+	}
+	stats, _ := kv.TTLStats(ctx)
+	fmt.Fprintf(out, "ttl stats: %+v\n", stats)
+}
@@ -0,0 +1,142 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"gobyexample/internal/clock"
+)
+
+/*
+	TestDeadlineHeap drives kvState directly, as the owner would, and checks that the heap holds one deadline per key
+	however often it is refreshed, and none once the key is persisted or deleted.
+	TestDeadlineHeap 은 소유자가 하듯이 kvState 를 직접 움직이고, heap 이 얼마나 자주 갱신되든 key 하나당 하나의 기한을 가지며,
+	key 가 persist 되거나 삭제되면 아무것도 가지지 않는지 확인한다.
+*/
+func TestDeadlineHeap(t *testing.T) {
+	now := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	for _, tc := range []struct {
+		name string
+		ops  func(s *kvState)
+		want []int
+	}{
+		{"refresh", func(s *kvState) {
+			for i := 0; i < 100; i++ {
+				s.expireAt(1, now.Add(time.Duration(100-i)*time.Hour))
+			}
+		}, []int{1}},
+		{"earliest first", func(s *kvState) {
+			s.expireAt(1, now.Add(3*time.Hour))
+			s.expireAt(2, now.Add(2*time.Hour))
+			s.expireAt(3, now.Add(time.Hour))
+			s.expireAt(3, now.Add(4*time.Hour))
+		}, []int{2, 1, 3}},
+		{"persist", func(s *kvState) {
+			s.expireAt(1, now.Add(time.Hour))
+			s.expireAt(2, now.Add(time.Hour))
+			s.persist(1)
+		}, []int{2}},
+		{"delete", func(s *kvState) {
+			s.expireAt(1, now.Add(time.Hour))
+			s.expireAt(2, now.Add(2*time.Hour))
+			s.del(1)
+		}, []int{2}},
+	} {
+		s := newKVState(clock.NewFake(now))
+		for key := 1; key <= 3; key++ {
+			s.set(key, key)
+		}
+		tc.ops(s)
+
+		var got []int
+		for len(s.deadlines) > 0 {
+			d := s.deadlines[0]
+			if s.expires[d.key] != d {
+				t.Errorf("%s: key %d's deadline is not the one in the heap", tc.name, d.key)
+			}
+			got = append(got, d.key)
+			s.clearDeadline(d.key)
+		}
+		if fmt.Sprint(got) != fmt.Sprint(tc.want) {
+			t.Errorf("%s: deadlines for %v, want %v", tc.name, got, tc.want)
+		}
+	}
+}
+
+/*
+	TestExpiredRead reads a key past its deadline, driving kvState directly so the timer cannot get there first.
+	The read misses and removes the key, as a revision of its own, and a later write starts from nothing.
+	TestExpiredRead 는 기한이 지난 key 를 읽는데, 타이머가 먼저 도착할 수 없도록 kvState 를 직접 움직인다.
+	읽기는 miss 하면서 그 자체의 revision 으로서 key 를 제거하고, 이후의 쓰기는 아무것도 없는 데서 시작한다.
+*/
+func TestExpiredRead(t *testing.T) {
+	fake := clock.NewFake(time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC))
+	s := newKVState(fake)
+	s.set(1, 10)
+	s.expireAt(1, fake.Now().Add(time.Second))
+	rev := s.revision
+	fake.Advance(time.Second)
+
+	if _, ok := s.read(1); ok {
+		t.Errorf("read found an expired key")
+	}
+	if _, stored := s.values[1]; stored || s.revision != rev+1 || s.stats.ExpiredLazily != 1 || s.stats.Misses != 1 || len(s.deadlines) != 0 {
+		t.Errorf("read of an expired key: stored %v, revision %d -> %d, stats %+v, want it removed", stored, rev, s.revision, s.stats)
+	}
+
+	incrOp{key: 1, delta: 1, resp: make(chan int, 1)}.apply(s)
+	if val := s.values[1]; val != 1 || s.revision != rev+2 || s.stats.ExpiredLazily != 1 {
+		t.Errorf("incr after expiry: value %d, revision %d -> %d, stats %+v, want 1 after a set", val, rev, s.revision, s.stats)
+	}
+}
+
+/*
+	TestExpiryTimer puts a KV on a Fake clock and never reads the keys with a TTL while moving the clock past their deadlines,
+	so only the timer can remove them: each deadline wakes the owner through its mailbox, which evicts what is due and arms the timer for the next one.
+	TestExpiryTimer 는 KV 를 Fake clock 위에 두고, 시계를 기한들 너머로 움직이는 동안 TTL 이 있는 key 들을 절대 읽지 않으므로,
+	타이머만이 그것들을 제거할 수 있다: 각 기한은 mailbox 를 통해 소유자를 깨우고, 소유자는 기한이 된 것을 내보낸 뒤 다음 기한을 위해 타이머를 맞춘다.
+*/
+func TestExpiryTimer(t *testing.T) {
+	fake := clock.NewFake(time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC))
+	kv := NewKVWithClock(fake, 10, time.Second)
+	ctx := context.Background()
+	defer kv.Stop(ctx)
+	kv.SetWithTTL(ctx, 1, 10, time.Second)
+	kv.SetWithTTL(ctx, 2, 20, 2*time.Second)
+	kv.Set(ctx, 3, 30)
+
+	for _, tc := range []struct {
+		name    string
+		advance time.Duration
+		expired int
+		want    map[int]bool
+	}{
+		{"before the first deadline", 500 * time.Millisecond, 0, map[int]bool{1: true, 2: true, 3: true}},
+		{"first deadline", 500 * time.Millisecond, 1, map[int]bool{1: false, 2: true, 3: true}},
+		{"second deadline", time.Second, 2, map[int]bool{1: false, 2: false, 3: true}},
+		{"long after", time.Hour, 2, map[int]bool{1: false, 2: false, 3: true}},
+	} {
+		fake.Advance(tc.advance)
+		var stats TTLStats
+		for {
+			var err error
+			if stats, err = kv.TTLStats(ctx); err != nil {
+				t.Fatal(err)
+			}
+			if stats.ExpiredByTimer >= tc.expired {
+				break
+			}
+			time.Sleep(time.Millisecond)
+		}
+		if stats.ExpiredByTimer != tc.expired || stats.ExpiredLazily != 0 {
+			t.Errorf("%s: stats %+v, want %d expired by the timer", tc.name, stats, tc.expired)
+		}
+		for key, want := range tc.want {
+			if _, ok, _ := kv.Lookup(ctx, key); ok != want {
+				t.Errorf("%s: key %d found %v, want %v", tc.name, key, ok, want)
+			}
+		}
+	}
+}
@@ -0,0 +1,315 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"math/rand"
+	"runtime/debug"
+	"sync"
+	"time"
+)
+
+/*
+	writeOp sets one key, so a read followed by a write from another goroutine can lose an update, and two keys can never change together.
+	Because the owner applies one op at a time, any op that does its reads and writes inside apply is atomic for free.
+	The ops below use that: compare-and-swap and increment on one key, Txn for a function over several keys that applies all or nothing,
+	and Commit for optimistic batches that were read earlier and fail with ErrConflict if any key they read has been written since.
+	writeOp 은 하나의 key 만 설정하므로, 다른 고루틴에서의 읽기 뒤의 쓰기는 갱신을 잃을 수 있고, 두 key 는 절대 함께 바뀔 수 없다.
+	소유자는 한 번에 하나의 op 만 적용하기 때문에, apply 안에서 읽기와 쓰기를 하는 op 은 무엇이든 공짜로 원자적이다.
+	아래의 op 들은 그것을 이용한다: 하나의 key 에 대한 compare-and-swap 과 increment, 여러 key 에 대한 함수를 전부 적용하거나 아무것도 적용하지 않는 Txn,
+	그리고 먼저 읽어둔 낙관적 batch 를 위한 Commit 으로, 읽은 key 가 그 사이에 쓰였으면 ErrConflict 로 실패한다.
+*/
+
+var ErrConflict = errors.New("kv: conflict")
+
+type casOp struct {
+	key      int
+	old, new int
+	resp     chan bool
+}
+
+func (op casOp) apply(state *kvState) {
+	if val, _ := state.get(op.key); val != op.old {
+		reply(state, op.resp, false)
+		return
+	}
+	state.set(op.key, op.new)
+	reply(state, op.resp, true)
+}
+
+type incrOp struct {
+	key   int
+	delta int
+	resp  chan int
+}
+
+func (op incrOp) apply(state *kvState) {
+	val, _ := state.get(op.key)
+	state.set(op.key, val+op.delta)
+	reply(state, op.resp, val+op.delta)
+}
+
+type txnOp struct {
+	keys []int
+	fn   func(vals map[int]int) error
+	resp chan error
+}
+
+/*
+	vals only holds the keys that exist, so a key fn stores into is one it wrote, even when it stores 0 into a missing key.
+	A key whose value fn leaves as it found it is not written.
+	vals 는 존재하는 key 들만 가지므로, fn 이 값을 넣은 key 는 그것이 쓴 key 이다, 없는 key 에 0 을 넣더라도.
+	fn 이 찾은 그대로 값을 남겨둔 key 는 쓰이지 않는다.
+*/
+func (op txnOp) apply(state *kvState) {
+	vals := make(map[int]int, len(op.keys))
+	existed := make(map[int]bool, len(op.keys))
+	for _, key := range op.keys {
+		if val, ok := state.get(key); ok {
+			vals[key] = val
+			existed[key] = true
+		}
+	}
+	read := maps.Clone(vals)
+	if err := callTxn(op.fn, vals); err != nil {
+		reply(state, op.resp, err)
+		return
+	}
+	for _, key := range op.keys {
+		val, ok := vals[key]
+		if ok && (!existed[key] || val != read[key]) {
+			state.set(key, val)
+		}
+	}
+	reply(state, op.resp, nil)
+}
+
+/*
+	callTxn runs fn on the owner goroutine, where a panic would take the owner and every other caller down with it.
+	It becomes the op's error instead, as a PanicError like a supervised child's.
+	callTxn 은 fn 을 소유자 고루틴에서 실행하는데, 그곳에서의 panic 은 소유자와 다른 모든 호출자를 함께 쓰러뜨릴 것이다.
+	대신 그것은 감독되는 자식의 것처럼 PanicError 로 op 의 에러가 된다.
+*/
+func callTxn(fn func(vals map[int]int) error, vals map[int]int) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = &PanicError{Value: r, Stack: debug.Stack()}
+		}
+	}()
+	return fn(vals)
+}
+
+/*
+	Versioned is a value together with the revision that wrote it; a key that was never written has version 0.
+	Versioned 는 값과 그것을 쓴 revision 을 함께 가진다; 한 번도 쓰이지 않은 key 의 version 은 0 이다.
+*/
+type Versioned struct {
+	Val     int
+	Version uint64
+}
+
+type readVersionsOp struct {
+	keys []int
+	resp chan map[int]Versioned
+}
+
+func (op readVersionsOp) apply(state *kvState) {
+	vals := make(map[int]Versioned, len(op.keys))
+	for _, key := range op.keys {
+		val, _ := state.get(key)
+		vals[key] = Versioned{Val: val, Version: state.versions[key]}
+	}
+	reply(state, op.resp, vals)
+}
+
+/*
+	Batch is the commit half of read-validate-commit: Reads holds the versions the batch was computed from, Writes what it wants to set.
+	Batch 는 읽기-검증-커밋의 커밋 부분이다: Reads 는 batch 가 계산될 때 쓴 version 들을, Writes 는 설정하려는 것을 가진다.
+*/
+type Batch struct {
+	Reads  map[int]uint64
+	Writes map[int]int
+}
+
+type commitOp struct {
+	batch Batch
+	resp  chan error
+}
+
+func (op commitOp) apply(state *kvState) {
+	for key, version := range op.batch.Reads {
+		if state.get(key); state.versions[key] != version {
+			reply(state, op.resp, fmt.Errorf("%w: key %d", ErrConflict, key))
+			return
+		}
+	}
+	for key, val := range op.batch.Writes {
+		state.set(key, val)
+	}
+	reply(state, op.resp, nil)
+}
+
+/*
+	CompareAndSwap sets key to new only if it currently holds old, and reports whether it did.
+	CompareAndSwap 은 key 가 현재 old 를 가지고 있을 때만 new 로 설정하고, 설정했는지를 알려준다.
+*/
+func (kv *KV) CompareAndSwap(ctx context.Context, key, old, new int) (bool, error) {
+	return Ask(ctx, kv.actor, kv.timeout, func(resp chan bool) kvOp {
+		return casOp{key: key, old: old, new: new, resp: resp}
+	})
+}
+
+/*
+	Increment adds delta to key and returns the new value.
+	Increment 는 key 에 delta 를 더하고 새 값을 반환한다.
+*/
+func (kv *KV) Increment(ctx context.Context, key, delta int) (int, error) {
+	return Ask(ctx, kv.actor, kv.timeout, func(resp chan int) kvOp {
+		return incrOp{key: key, delta: delta, resp: resp}
+	})
+}
+
+/*
+	Txn calls fn with the current values of keys and, unless fn returns an error, writes back whatever fn changed in the map.
+	fn runs on the owner goroutine, so it sees and changes the keys with nothing in between, but it must be quick and must not call the KV itself.
+	Only keys are written back; anything else fn puts in the map is ignored.
+	A key that does not exist is missing from the map rather than 0, and storing into it creates it.
+	If fn panics, nothing is written and Txn returns the panic as a *PanicError.
+	Txn 은 keys 의 현재 값으로 fn 을 호출하고, fn 이 에러를 반환하지 않으면 fn 이 map 에서 바꾼 것을 다시 쓴다.
+	fn 은 소유자 고루틴에서 실행되므로 그 사이에 아무것도 없이 key 들을 보고 바꾸지만, 빨라야 하고 KV 자신을 호출해서는 안 된다.
+	keys 만 다시 쓰인다; fn 이 map 에 넣은 다른 것은 무시된다.
+	존재하지 않는 key 는 map 에서 0 이 아니라 빠져 있고, 그것에 값을 넣으면 key 가 만들어진다.
+	fn 이 panic 하면 아무것도 쓰이지 않고 Txn 은 그 panic 을 *PanicError 로 반환한다.
+*/
+func (kv *KV) Txn(ctx context.Context, keys []int, fn func(vals map[int]int) error) error {
+	result, err := Ask(ctx, kv.actor, kv.timeout, func(resp chan error) kvOp {
+		return txnOp{keys: keys, fn: fn, resp: resp}
+	})
+	if err != nil {
+		return err
+	}
+	return result
+}
+
+/*
+	ReadVersions reads several keys at one revision, for a Batch to be computed from.
+	ReadVersions 는 Batch 를 계산하기 위해 여러 key 를 하나의 revision 에서 읽는다.
+*/
+func (kv *KV) ReadVersions(ctx context.Context, keys ...int) (map[int]Versioned, error) {
+	return Ask(ctx, kv.actor, kv.timeout, func(resp chan map[int]Versioned) kvOp {
+		return readVersionsOp{keys: keys, resp: resp}
+	})
+}
+
+/*
+	Commit applies every write in b if none of the keys in b.Reads has been written since it was read, and nothing otherwise.
+	A conflict is reported as an error wrapping ErrConflict; the caller reads again and retries.
+	Commit 은 b.Reads 의 어떤 key 도 읽힌 뒤에 쓰이지 않았으면 b 의 모든 쓰기를 적용하고, 그렇지 않으면 아무것도 적용하지 않는다.
+	충돌은 ErrConflict 를 감싼 에러로 알려진다; 호출자는 다시 읽고 재시도한다.
+*/
+func (kv *KV) Commit(ctx context.Context, b Batch) error {
+	result, err := Ask(ctx, kv.actor, kv.timeout, func(resp chan error) kvOp {
+		return commitOp{batch: b, resp: resp}
+	})
+	if err != nil {
+		return err
+	}
+	return result
+}
+
+/*
+	inventoryExample moves stock between three warehouses from many goroutines, half of them with Txn and half with optimistic batches.
+	A move that would leave a warehouse below zero is refused, and the total never changes.
+	inventoryExample 은 많은 고루틴에서 세 창고 사이로 재고를 옮기는데, 절반은 Txn 으로, 절반은 낙관적 batch 로 옮긴다.
+	창고를 0 아래로 만들 이동은 거부되며, 합계는 절대 바뀌지 않는다.
+*/
+func inventoryExample(out io.Writer) {
+	const warehouses, stock = 3, 100
+	kv := NewKV(100, time.Second)
+	defer kv.Stop(context.Background())
+	ctx := context.Background()
+
+	for w := 0; w < warehouses; w++ {
+		kv.Set(ctx, w, stock)
+	}
+	swapped, _ := kv.CompareAndSwap(ctx, 0, 0, 95)
+	fmt.Fprintln(out, "cas 0 -> 95: ", swapped)
+	swapped, _ = kv.CompareAndSwap(ctx, 0, stock, 95)
+	fmt.Fprintln(out, "cas 100 -> 95: ", swapped)
+	n, _ := kv.Increment(ctx, 0, 5)
+	fmt.Fprintln(out, "increment 5: ", n)
+
+	errShortage := errors.New("not enough stock")
+	var mu sync.Mutex
+	var moved, refused, conflicts int
+	var wg sync.WaitGroup
+	for g := 0; g < 20; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 200; i++ {
+				from, to := rand.Intn(warehouses), rand.Intn(warehouses)
+				if from == to {
+					continue
+				}
+				qty := rand.Intn(10) + 1
+				var err error
+				if g%2 == 0 {
+					err = kv.Txn(ctx, []int{from, to}, func(vals map[int]int) error {
+						if vals[from] < qty {
+							return errShortage
+						}
+						vals[from] -= qty
+						vals[to] += qty
+						return nil
+					})
+				} else {
+					for {
+						var vals map[int]Versioned
+						vals, err = kv.ReadVersions(ctx, from, to)
+						if err != nil {
+							break
+						}
+						if vals[from].Val < qty {
+							err = errShortage
+							break
+						}
+						err = kv.Commit(ctx, Batch{
+							Reads:  map[int]uint64{from: vals[from].Version, to: vals[to].Version},
+							Writes: map[int]int{from: vals[from].Val - qty, to: vals[to].Val + qty},
+						})
+						if !errors.Is(err, ErrConflict) {
+							break
+						}
+						mu.Lock()
+						conflicts++
+						mu.Unlock()
+					}
+				}
+				mu.Lock()
+				if err == nil {
+					moved++
+				} else {
+					refused++
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	keys := make([]int, warehouses)
+	for w := range keys {
+		keys[w] = w
+	}
+	vals, _ := kv.ReadVersions(ctx, keys...)
+	total := 0
+	for _, v := range vals {
+		total += v.Val
+	}
+	fmt.Fprintf(out, "moves: %d, refused: %d, conflicts retried: %d\n", moved, refused, conflicts)
+	fmt.Fprintf(out, "inventory total: %d (want %d)\n", total, warehouses*stock)
+}
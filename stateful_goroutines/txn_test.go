@@ -0,0 +1,209 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+/*
+	TestTxn runs each fn against keys 1 and 2, where only key 1 exists, and checks what ends up written.
+	A key that was never written has version 0, so the versions tell created keys apart from untouched ones.
+	TestTxn 은 key 1 만 존재하는 상태에서 key 1 과 2 에 대해 각 fn 을 실행하고 무엇이 쓰이는지 확인한다.
+	한 번도 쓰이지 않은 key 의 version 은 0 이므로, version 들이 만들어진 key 와 건드려지지 않은 key 를 구별해준다.
+*/
+func TestTxn(t *testing.T) {
+	errRefused := errors.New("refused")
+	for _, tc := range []struct {
+		name    string
+		fn      func(vals map[int]int) error
+		wantErr func(error) bool
+		want    map[int]int
+		created bool
+	}{
+		{"move between keys", func(vals map[int]int) error {
+			vals[1] -= 3
+			vals[2] += 3
+			return nil
+		}, nil, map[int]int{1: 7, 2: 3}, true},
+
+		{"create a missing key with 0", func(vals map[int]int) error {
+			vals[2] = 0
+			return nil
+		}, nil, map[int]int{1: 10, 2: 0}, true},
+
+		{"missing key is not in the map", func(vals map[int]int) error {
+			if _, ok := vals[2]; ok {
+				return errRefused
+			}
+			return nil
+		}, nil, map[int]int{1: 10, 2: 0}, false},
+
+		{"error writes nothing", func(vals map[int]int) error {
+			vals[1] = 0
+			vals[2] = 5
+			return errRefused
+		}, func(err error) bool { return errors.Is(err, errRefused) }, map[int]int{1: 10, 2: 0}, false},
+
+		{"panic writes nothing and becomes the error", func(vals map[int]int) error {
+			vals[2] = 5
+			panic("bad txn")
+		}, func(err error) bool {
+			var pe *PanicError
+			return errors.As(err, &pe) && pe.Value == "bad txn"
+		}, map[int]int{1: 10, 2: 0}, false},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := context.Background()
+			kv := NewKV(10, time.Second)
+			defer kv.Stop(ctx)
+			kv.Set(ctx, 1, 10)
+
+			err := kv.Txn(ctx, []int{1, 2}, tc.fn)
+			if tc.wantErr == nil && err != nil || tc.wantErr != nil && !tc.wantErr(err) {
+				t.Errorf("txn returned %v", err)
+			}
+			vals, err := kv.ReadVersions(ctx, 1, 2)
+			if err != nil {
+				t.Fatalf("the owner did not survive the txn: %v", err)
+			}
+			for key, want := range tc.want {
+				if vals[key].Val != want {
+					t.Errorf("key %d = %d, want %d", key, vals[key].Val, want)
+				}
+			}
+			if created := vals[2].Version != 0; created != tc.created {
+				t.Errorf("key 2 created %v, want %v", created, tc.created)
+			}
+		})
+	}
+}
+
+/*
+	TestCompareAndSwap swaps key 1, which holds 10, and key 2, which does not exist and so holds 0 for the comparison.
+	TestCompareAndSwap 은 10 을 가진 key 1 과, 존재하지 않아서 비교에서 0 을 가지는 key 2 를 swap 한다.
+*/
+func TestCompareAndSwap(t *testing.T) {
+	for _, tc := range []struct {
+		name    string
+		key     int
+		old     int
+		swapped bool
+		want    int
+	}{
+		{"hit", 1, 10, true, 20},
+		{"miss", 1, 5, false, 10},
+		{"missing key compares as 0", 2, 0, true, 20},
+		{"miss on a missing key", 2, 5, false, 0},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := context.Background()
+			kv := NewKV(10, time.Second)
+			defer kv.Stop(ctx)
+			kv.Set(ctx, 1, 10)
+
+			swapped, err := kv.CompareAndSwap(ctx, tc.key, tc.old, 20)
+			if swapped != tc.swapped || err != nil {
+				t.Errorf("CompareAndSwap(%d, %d, 20) = %v, %v, want %v", tc.key, tc.old, swapped, err, tc.swapped)
+			}
+			if val, err := kv.Get(ctx, tc.key); val != tc.want || err != nil {
+				t.Errorf("key %d = %d, %v, want %d", tc.key, val, err, tc.want)
+			}
+		})
+	}
+}
+
+/*
+	TestIncrement adds to key 1, which holds 10, and to key 2, which does not exist and starts from 0; either way the key's version moves.
+	TestIncrement 는 10 을 가진 key 1 과, 존재하지 않아서 0 에서 시작하는 key 2 에 더한다; 어느 쪽이든 key 의 version 은 움직인다.
+*/
+func TestIncrement(t *testing.T) {
+	for _, tc := range []struct {
+		name  string
+		key   int
+		delta int
+		want  int
+	}{
+		{"existing key", 1, 5, 15},
+		{"negative delta", 1, -3, 7},
+		{"missing key starts at 0", 2, 5, 5},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := context.Background()
+			kv := NewKV(10, time.Second)
+			defer kv.Stop(ctx)
+			kv.Set(ctx, 1, 10)
+			before, err := kv.ReadVersions(ctx, tc.key)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if val, err := kv.Increment(ctx, tc.key, tc.delta); val != tc.want || err != nil {
+				t.Errorf("Increment(%d, %d) = %d, %v, want %d", tc.key, tc.delta, val, err, tc.want)
+			}
+			after, err := kv.ReadVersions(ctx, tc.key)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if after[tc.key].Val != tc.want || after[tc.key].Version <= before[tc.key].Version {
+				t.Errorf("key %d went from %+v to %+v, want %d at a later version", tc.key, before[tc.key], after[tc.key], tc.want)
+			}
+		})
+	}
+}
+
+/*
+	TestCommit reads keys 1 and 2 with ReadVersions, lets another caller write in between, and commits a batch computed from the read.
+	Any write to a key the batch read, even one that stores the same value or creates a missing key, bumps its version and must make the commit fail
+	with ErrConflict and write nothing; a write to a key the batch did not read must not.
+	TestCommit 은 ReadVersions 로 key 1 과 2 를 읽고, 그 사이에 다른 호출자가 쓰게 한 뒤, 읽은 것으로 계산된 batch 를 커밋한다.
+	batch 가 읽은 key 에 대한 어떤 쓰기든, 같은 값을 넣거나 없는 key 를 만드는 것이라도, 그것의 version 을 올리며 커밋이
+	ErrConflict 로 실패하고 아무것도 쓰지 않게 해야 한다; batch 가 읽지 않은 key 에 대한 쓰기는 그래서는 안 된다.
+*/
+func TestCommit(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		key, val int
+		conflict bool
+		want     map[int]int
+	}{
+		{"no write in between", 0, 0, false, map[int]int{1: 7, 2: 3}},
+		{"write to a read key", 1, 11, true, map[int]int{1: 11, 2: 0}},
+		{"same value to a read key", 1, 10, true, map[int]int{1: 10, 2: 0}},
+		{"creating a read key", 2, 0, true, map[int]int{1: 10, 2: 0}},
+		{"write to a key not read", 3, 1, false, map[int]int{1: 7, 2: 3, 3: 1}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := context.Background()
+			kv := NewKV(10, time.Second)
+			defer kv.Stop(ctx)
+			kv.Set(ctx, 1, 10)
+
+			read, err := kv.ReadVersions(ctx, 1, 2)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if read[1].Val != 10 || read[1].Version == 0 || read[2] != (Versioned{}) {
+				t.Fatalf("ReadVersions(1, 2) = %v, want 10 at a version and a zero Versioned", read)
+			}
+			if tc.key != 0 {
+				kv.Set(ctx, tc.key, tc.val)
+			}
+
+			b := Batch{
+				Reads:  map[int]uint64{1: read[1].Version, 2: read[2].Version},
+				Writes: map[int]int{1: read[1].Val - 3, 2: read[2].Val + 3},
+			}
+			err = kv.Commit(ctx, b)
+			if conflict := errors.Is(err, ErrConflict); conflict != tc.conflict || !conflict && err != nil {
+				t.Errorf("Commit returned %v, want conflict %v", err, tc.conflict)
+			}
+			for key, want := range tc.want {
+				if val, _ := kv.Get(ctx, key); val != want {
+					t.Errorf("key %d = %d, want %d", key, val, want)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,343 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+/*
+	Instead of polling with readOp, a consumer can watch one key, or every key whose decimal form starts with a prefix (the form the RESP server uses).
+	Every write the owner commits becomes a Change with the old value, the new value and the revision of the write, fanned out to matching watches.
+	The owner must never block on a watcher, so each watch has a buffer and an OverflowPolicy for when that buffer is full.
+	The owner also keeps the last watchHistory changes, so a watcher that lost its watch can resume from the last revision it saw.
+	readOp 으로 polling 하는 대신, 소비자는 하나의 key 나, 10진수 형태(RESP 서버가 쓰는 형태)가 prefix 로 시작하는 모든 key 를 watch 할 수 있다.
+	소유자가 커밋하는 모든 쓰기는 이전 값, 새 값, 그리고 쓰기의 revision 을 가진 Change 가 되어, 일치하는 watch 들로 퍼져나간다.
+	소유자는 절대 watcher 때문에 막혀서는 안 되므로, 각 watch 는 버퍼와 그 버퍼가 가득 찼을 때를 위한 OverflowPolicy 를 가진다.
+	소유자는 또한 마지막 watchHistory 개의 변경을 보관하므로, watch 를 잃은 watcher 는 마지막으로 본 revision 부터 재개할 수 있다.
+*/
+
+const watchHistory = 1024
+
+var (
+	ErrWatchOverflow = errors.New("kv: watch overflowed")
+	ErrCompacted     = errors.New("kv: revision compacted")
+)
+
+/*
+	Change is one committed write. Created means the key did not exist before; Deleted means it does not exist after, and New is zero.
+	Change 는 하나의 커밋된 쓰기이다. Created 는 key 가 이전에 존재하지 않았다는 뜻이다; Deleted 는 이후에 존재하지 않는다는 뜻이고, New 는 0 이다.
+*/
+type Change struct {
+	Key      int
+	Old, New int
+	Created  bool
+	Deleted  bool
+	Revision uint64
+}
+
+type OverflowPolicy int
+
+const (
+	/*
+		OverflowClose ends the watch with ErrWatchOverflow; the watcher resumes from the last revision it saw and misses nothing.
+		OverflowClose 는 watch 를 ErrWatchOverflow 로 끝낸다; watcher 는 마지막으로 본 revision 부터 재개하며 아무것도 놓치지 않는다.
+	*/
+	OverflowClose OverflowPolicy = iota
+	/*
+		OverflowDropNewest drops the change that does not fit; OverflowDropOldest drops the oldest buffered change to make room.
+		Both count what they drop in Dropped, for watchers that only care about the latest value.
+		OverflowDropNewest 는 들어가지 않는 변경을 버린다; OverflowDropOldest 는 자리를 만들기 위해 가장 오래된 버퍼의 변경을 버린다.
+		둘 다 버린 것을 Dropped 에 세며, 최신 값만 신경 쓰는 watcher 들을 위한 것이다.
+	*/
+	OverflowDropNewest
+	OverflowDropOldest
+)
+
+type WatchOptions struct {
+	Buffer   int
+	Overflow OverflowPolicy
+	/*
+		FromRevision, if not zero, first replays the changes after that revision; it fails with ErrCompacted if they are no longer kept.
+		FromRevision 이 0 이 아니면 먼저 그 revision 이후의 변경들을 다시 보낸다; 그것들이 더 이상 보관되어 있지 않으면 ErrCompacted 로 실패한다.
+	*/
+	FromRevision uint64
+}
+
+/*
+	changeRing keeps the last watchHistory changes. Once it is full each push overwrites the oldest change in place,
+	so the owner pays the same for a write however long the history is.
+	changeRing 은 마지막 watchHistory 개의 변경을 보관한다. 가득 찬 뒤에는 각 push 가 가장 오래된 변경을 제자리에서 덮어쓰므로,
+	소유자는 history 가 얼마나 길든 쓰기마다 같은 비용을 치른다.
+*/
+type changeRing struct {
+	buf   []Change
+	start int
+}
+
+func (r *changeRing) push(c Change) {
+	if len(r.buf) < watchHistory {
+		r.buf = append(r.buf, c)
+		return
+	}
+	r.buf[r.start] = c
+	r.start = (r.start + 1) % watchHistory
+}
+
+func (r *changeRing) len() int {
+	return len(r.buf)
+}
+
+/*
+	at returns the i-th oldest change kept.
+	at 은 보관된 것 중 i 번째로 오래된 변경을 반환한다.
+*/
+func (r *changeRing) at(i int) Change {
+	return r.buf[(r.start+i)%len(r.buf)]
+}
+
+type watcher struct {
+	id       uint64
+	match    func(key int) bool
+	ch       chan Change
+	overflow OverflowPolicy
+	dropped  atomic.Uint64
+	err      error
+	closed   chan struct{}
+}
+
+/*
+	Watch is the consumer's end. C is closed when the watch ends, and after that Err says why: nil after Cancel,
+	ErrWatchOverflow, the context's error, or the error that stopped the KV.
+	Watch 는 소비자 쪽의 끝이다. C 는 watch 가 끝나면 닫히고, 그 뒤에 Err 가 이유를 말한다: Cancel 뒤에는 nil,
+	ErrWatchOverflow, context 의 에러, 또는 KV 를 멈추게 한 에러.
+*/
+type Watch struct {
+	C <-chan Change
+
+	kv   *KV
+	w    *watcher
+	once sync.Once
+}
+
+func (w *Watch) Err() error {
+	return w.w.err
+}
+
+func (w *Watch) Dropped() uint64 {
+	return w.w.dropped.Load()
+}
+
+func (w *Watch) Cancel() {
+	w.cancel(nil)
+}
+
+func (w *Watch) cancel(err error) {
+	w.once.Do(func() {
+		/*
+			If the owner is gone the goroutine in NewKV closes the watch instead.
+			소유자가 사라졌으면 NewKV 의 고루틴이 대신 watch 를 닫는다.
+		*/
+		w.kv.actor.Send(context.Background(), unwatchOp{id: w.w.id, err: err})
+	})
+}
+
+type watchOp struct {
+	w    *watcher
+	from uint64
+	resp chan error
+}
+
+func (op watchOp) apply(state *kvState) {
+	var replay []Change
+	if op.from > 0 && op.from < state.revision {
+		h := &state.history
+		if h.len() == 0 || h.at(0).Revision > op.from+1 {
+			oldest := state.revision
+			if h.len() > 0 {
+				oldest = h.at(0).Revision
+			}
+			op.resp <- fmt.Errorf("%w: oldest kept revision is %d", ErrCompacted, oldest)
+			return
+		}
+		for i := 0; i < h.len(); i++ {
+			if c := h.at(i); c.Revision > op.from && op.w.match(c.Key) {
+				replay = append(replay, c)
+			}
+		}
+	}
+	/*
+		The replay always fits, so resuming cannot overflow straight away.
+		다시 보내는 것은 항상 들어가므로, 재개가 곧바로 넘칠 수는 없다.
+	*/
+	op.w.ch = make(chan Change, cap(op.w.ch)+len(replay))
+	for _, c := range replay {
+		op.w.ch <- c
+	}
+	state.nextID++
+	op.w.id = state.nextID
+	state.watchers[op.w.id] = op.w
+	op.resp <- nil
+}
+
+type unwatchOp struct {
+	id  uint64
+	err error
+}
+
+func (op unwatchOp) apply(state *kvState) {
+	if w, ok := state.watchers[op.id]; ok {
+		state.closeWatcher(w, op.err)
+	}
+}
+
+func (s *kvState) closeWatcher(w *watcher, err error) {
+	delete(s.watchers, w.id)
+	w.err = err
+	close(w.ch)
+	close(w.closed)
+}
+
+func (s *kvState) closeWatchers(err error) {
+	for _, w := range s.watchers {
+		s.closeWatcher(w, err)
+	}
+}
+
+/*
+	notify runs on the owner goroutine for every committed write, so none of its sends may block.
+	notify 는 모든 커밋된 쓰기마다 소유자 고루틴에서 실행되므로, 그 안의 어떤 보내기도 막혀서는 안 된다.
+*/
+func (s *kvState) notify(c Change) {
+	s.history.push(c)
+
+	for _, w := range s.watchers {
+		if !w.match(c.Key) {
+			continue
+		}
+		select {
+		case w.ch <- c:
+			continue
+		default:
+		}
+		switch w.overflow {
+		case OverflowClose:
+			s.closeWatcher(w, ErrWatchOverflow)
+		case OverflowDropNewest:
+			w.dropped.Add(1)
+		case OverflowDropOldest:
+			/*
+				The watcher may drain the buffer between our two selects, in which case there is nothing to drop.
+				watcher 가 두 select 사이에 버퍼를 비울 수도 있으며, 그 경우에는 버릴 것이 없다.
+			*/
+			select {
+			case <-w.ch:
+				w.dropped.Add(1)
+			default:
+			}
+			w.ch <- c
+		}
+	}
+}
+
+/*
+	Watch watches one key. The watch ends when ctx is done or Cancel is called.
+	Watch 는 하나의 key 를 watch 한다. watch 는 ctx 가 끝나거나 Cancel 이 호출되면 끝난다.
+*/
+func (kv *KV) Watch(ctx context.Context, key int, opts WatchOptions) (*Watch, error) {
+	return kv.watch(ctx, func(k int) bool { return k == key }, opts)
+}
+
+/*
+	WatchPrefix watches every key whose decimal form starts with prefix, so "1" matches 1, 10 and 123 but not 21.
+	WatchPrefix 는 10진수 형태가 prefix 로 시작하는 모든 key 를 watch 하므로, "1" 은 1, 10, 123 과 일치하지만 21 과는 일치하지 않는다.
+*/
+func (kv *KV) WatchPrefix(ctx context.Context, prefix string, opts WatchOptions) (*Watch, error) {
+	return kv.watch(ctx, func(k int) bool { return strings.HasPrefix(strconv.Itoa(k), prefix) }, opts)
+}
+
+func (kv *KV) watch(ctx context.Context, match func(int) bool, opts WatchOptions) (*Watch, error) {
+	w := &watcher{match: match, ch: make(chan Change, max(opts.Buffer, 1)), overflow: opts.Overflow, closed: make(chan struct{})}
+	result, err := Ask(ctx, kv.actor, kv.timeout, func(resp chan error) kvOp {
+		return watchOp{w: w, from: opts.FromRevision, resp: resp}
+	})
+	if err == nil {
+		err = result
+	}
+	if err != nil {
+		return nil, err
+	}
+	watch := &Watch{C: w.ch, kv: kv, w: w}
+	go func() {
+		select {
+		case <-ctx.Done():
+			watch.cancel(ctx.Err())
+		case <-w.closed:
+		case <-kv.actor.Done():
+		}
+	}()
+	return watch, nil
+}
+
+/*
+	watchExample watches one key and a prefix, then lets a slow watcher overflow and resume from the last revision it saw.
+	watchExample 은 하나의 key 와 prefix 를 watch 한 뒤, 느린 watcher 가 넘치게 하고 마지막으로 본 revision 부터 재개하게 한다.
+*/
+func watchExample(out io.Writer) {
+	kv := NewKV(100, time.Second)
+	ctx := context.Background()
+
+	one, _ := kv.Watch(ctx, 1, WatchOptions{Buffer: 16})
+	ones, _ := kv.WatchPrefix(ctx, "1", WatchOptions{Buffer: 16})
+	slow, _ := kv.Watch(ctx, 2, WatchOptions{Buffer: 2, Overflow: OverflowClose})
+
+	kv.Set(ctx, 1, 10)
+	kv.Increment(ctx, 1, 1)
+	kv.Set(ctx, 12, 120)
+	kv.Set(ctx, 21, 210)
+	kv.Delete(ctx, 1)
+	for i := 1; i <= 5; i++ {
+		kv.Set(ctx, 2, i)
+	}
+
+	one.Cancel()
+	for c := range one.C {
+		fmt.Fprintf(out, "watch 1: %+v\n", c)
+	}
+	fmt.Fprintln(out, "watch 1 ended: ", one.Err())
+	ones.Cancel()
+	var keys []int
+	for c := range ones.C {
+		keys = append(keys, c.Key)
+	}
+	fmt.Fprintln(out, "watch prefix 1 saw keys: ", keys)
+
+	var last uint64
+	var seen []int
+	for c := range slow.C {
+		seen = append(seen, c.New)
+		last = c.Revision
+	}
+	fmt.Fprintln(out, "slow watch ended: ", slow.Err(), "after", seen)
+	resumed, err := kv.Watch(ctx, 2, WatchOptions{Buffer: 2, FromRevision: last})
+	if err != nil {
+		fmt.Fprintln(out, "resume: ", err)
+		return
+	}
+	resumed.Cancel()
+	seen = seen[:0]
+	for c := range resumed.C {
+		seen = append(seen, c.New)
+	}
+	fmt.Fprintln(out, "resumed from revision", last, "saw", seen)
+
+	kv.Stop(ctx)
+	_, err = kv.Watch(ctx, 2, WatchOptions{})
+	fmt.Fprintln(out, "watch after stop: ", err)
+}
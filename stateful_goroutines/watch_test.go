@@ -0,0 +1,61 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+/*
+	TestWatchResume writes more changes than the history keeps, so the ring has wrapped, and then resumes from several revisions.
+	A resume must replay every kept change after its revision, oldest first, or fail with ErrCompacted once one of them is gone.
+	TestWatchResume 은 history 가 보관하는 것보다 많은 변경을 써서 ring 이 한 바퀴 돈 뒤, 여러 revision 에서 재개한다.
+	재개는 그 revision 이후의 보관된 모든 변경을 오래된 것부터 다시 보내거나, 그중 하나라도 사라졌으면 ErrCompacted 로 실패해야 한다.
+*/
+func TestWatchResume(t *testing.T) {
+	const writes = watchHistory + 500
+	ctx := context.Background()
+	kv := NewKV(100, time.Second)
+	defer kv.Stop(ctx)
+	for i := 1; i <= writes; i++ {
+		kv.Set(ctx, 1+i%2, i)
+	}
+
+	for _, tc := range []struct {
+		name    string
+		key     int
+		from    uint64
+		want    int
+		wantErr error
+	}{
+		{"latest", 1, writes, 0, nil},
+		{"recent", 2, writes - 10, 5, nil},
+		{"oldest kept", 1, writes - watchHistory, watchHistory / 2, nil},
+		{"compacted", 1, writes - watchHistory - 1, 0, ErrCompacted},
+	} {
+		w, err := kv.Watch(ctx, tc.key, WatchOptions{FromRevision: tc.from})
+		if !errors.Is(err, tc.wantErr) {
+			t.Errorf("%s: got %v, want %v", tc.name, err, tc.wantErr)
+			continue
+		}
+		if err != nil {
+			continue
+		}
+		w.Cancel()
+		var got []Change
+		for c := range w.C {
+			got = append(got, c)
+		}
+		if len(got) != tc.want {
+			t.Errorf("%s: replayed %d changes, want %d", tc.name, len(got), tc.want)
+		}
+		rev := tc.from
+		for _, c := range got {
+			if c.Key != tc.key || c.Revision <= rev || c.New != int(c.Revision) {
+				t.Errorf("%s: replayed %+v after revision %d", tc.name, c, rev)
+			}
+			rev = c.Revision
+		}
+	}
+}
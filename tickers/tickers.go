@@ -2,7 +2,11 @@ package main
 
 import (
 	"fmt"
+	"io"
+	"os"
 	"time"
+
+	"gobyexample/internal/clock"
 )
 
 /*
@@ -12,14 +16,18 @@ import (
 	여기 우리가 멈출 때까지 주기적으로 똑딱거리는 똑딱이의 예가 있다.
 */
 
-func main() {
+/*
+	The example takes its Clock and its output as arguments, so main can run it for real and TestTickers can replay it on a fake clock.
+	예제는 자신의 Clock 과 출력을 인자로 받아서, main 은 실제로 실행하고 TestTickers 는 가짜 clock 위에서 다시 재생할 수 있다.
+*/
+func tickers(clock clock.Clock, out io.Writer) {
 	/*
 		Tickers use a similar mechanism to timers: a channel that is sent values.
 		Here we'll use the select builtin on the channel to await the values as they arrive every 500ms.
 		Ticker는 timer와 비슷한 매커니즘으로 사용한다: 값을 보내는 채널.
 		우리는 채널에 내장된 select 를 사용해서 매 500ms 마다 값들이 도착하는 걸 기다린다.
 	*/
-	ticker := time.NewTicker(500 * time.Millisecond)
+	ticker := clock.NewTicker(500 * time.Millisecond)
 	done := make(chan bool)
 
 	go func() {
@@ -27,8 +35,8 @@ func main() {
 			select {
 			case <-done:
 				return
-			case t := <-ticker.C:
-				fmt.Println("Tick at ", t)
+			case t := <-ticker.C():
+				fmt.Fprintln(out, "Tick at ", t)
 			}
 		}
 	}()
@@ -40,12 +48,16 @@ func main() {
 		한번 ticker가 멈추면 이 채널에서는 더이상 값을 받지 않는다.
 		우리는 1600ms 뒤에 ticker를 멈출 것이다.
 	*/
-	time.Sleep(1600 * time.Millisecond)
+	clock.Sleep(1600 * time.Millisecond)
 	ticker.Stop()
 	done <- true
-	fmt.Println("Ticker stopped")
+	fmt.Fprintln(out, "Ticker stopped")
 	/*
 		When we run this program the ticker should tick 3 times before we stop it.
 		우리가 이 프로그램을 실행시킬 때 ticker는 우리가 ticker를 멈추기 전까지 3번 tick할 것이다.
 	*/
 }
+
+func main() {
+	tickers(clock.Real{}, os.Stdout)
+}
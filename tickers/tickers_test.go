@@ -0,0 +1,46 @@
+package main
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"gobyexample/internal/clock"
+)
+
+/*
+	lineWriter hands every line the example prints to the test, so the test knows a tick was handled before it moves the clock again.
+	lineWriter 는 예제가 출력하는 모든 줄을 테스트에 넘겨주므로, 테스트는 시계를 다시 움직이기 전에 tick 이 처리되었음을 안다.
+*/
+type lineWriter chan string
+
+func (w lineWriter) Write(p []byte) (int, error) {
+	w <- string(p)
+	return len(p), nil
+}
+
+/*
+	TestTickers waits until the example is sleeping, then advances one tick at a time and reads the line each tick prints.
+	The ticker drops ticks its reader is not ready for, as a real one does, so advancing past all three at once could lose some.
+	TestTickers 는 예제가 잠들 때까지 기다린 뒤, 한 번에 tick 하나씩 advance 하고 각 tick 이 출력하는 줄을 읽는다.
+	ticker 는 실제 ticker 처럼 읽는 쪽이 준비되지 않은 tick 을 버리므로, 세 개 모두를 한번에 지나가면 일부를 잃을 수 있다.
+*/
+func TestTickers(t *testing.T) {
+	start := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	fake := clock.NewFake(start)
+	lines := make(lineWriter)
+	go tickers(fake, lines)
+
+	fake.BlockUntilSleeping(1)
+	for i := 1; i <= 3; i++ {
+		fake.Advance(500 * time.Millisecond)
+		want := fmt.Sprintln("Tick at ", start.Add(time.Duration(i)*500*time.Millisecond))
+		if got := <-lines; got != want {
+			t.Errorf("tick %d: got %q, want %q", i, got, want)
+		}
+	}
+	fake.Advance(100 * time.Millisecond)
+	if got := <-lines; got != "Ticker stopped\n" {
+		t.Errorf("got %q, want %q", got, "Ticker stopped\n")
+	}
+}
@@ -2,7 +2,11 @@ package main
 
 import (
 	"fmt"
+	"io"
+	"os"
 	"time"
+
+	"gobyexample/internal/clock"
 )
 
 /*
@@ -12,7 +16,11 @@ import (
 	Go에서 Timeout을 구현하는 것은 채널과 select 덕분에 쉽고 우아하다.
 */
 
-func main() {
+/*
+	The example takes its Clock and its output as arguments, so main can run it for real and TestTimeouts can replay it on a fake clock.
+	예제는 자신의 Clock 과 출력을 인자로 받아서, main 은 실제로 실행하고 TestTimeouts 는 가짜 clock 위에서 다시 재생할 수 있다.
+*/
+func timeouts(clock clock.Clock, out io.Writer) {
 	/*
 		For our example, suppose we're executing an external call that returns its result on a channel c1 after 2s.
 		Note that the channel is buffered, so the send in the goroutine is nonblocking.
@@ -23,7 +31,7 @@ func main() {
 	*/
 	c1 := make(chan string, 1)
 	go func() {
-		time.Sleep(2 * time.Second)
+		clock.Sleep(2 * time.Second)
 		c1 <- "result 1"
 	}()
 
@@ -37,9 +45,9 @@ func main() {
 	*/
 	select {
 	case res := <-c1:
-		fmt.Println(res)
-	case <-time.After(1 * time.Second):
-		fmt.Println("timeout 1")
+		fmt.Fprintln(out, res)
+	case <-clock.After(1 * time.Second):
+		fmt.Fprintln(out, "timeout 1")
 	}
 
 	/*
@@ -48,15 +56,15 @@ func main() {
 	*/
 	c2 := make(chan string, 1)
 	go func() {
-		time.Sleep(2 * time.Second)
+		clock.Sleep(2 * time.Second)
 		c2 <- "result 2"
 	}()
 
 	select {
 	case res := <-c2:
-		fmt.Println(res)
-	case <-time.After(3 * time.Second):
-		fmt.Println("timeout 2")
+		fmt.Fprintln(out, res)
+	case <-clock.After(3 * time.Second):
+		fmt.Fprintln(out, "timeout 2")
 	}
 
 	/*
@@ -64,3 +72,7 @@ func main() {
 		이 프로그램을 실행하는 것은 첫번째는 timing out을 연산하는 두번째는 성공한 것을 보여준다.
 	*/
 }
+
+func main() {
+	timeouts(clock.Real{}, os.Stdout)
+}
@@ -0,0 +1,38 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"gobyexample/internal/clock"
+)
+
+/*
+	TestTimeouts first waits for the c1 sleeper and the 1s timeout and advances 1s, so only the timeout fires.
+	Then it waits for the c2 sleeper and the 3s timeout too and advances 2s, which wakes both sleepers but not the timeout.
+	Last it advances past the 3s timeout that lost the select: nobody reads it, and Advance must not wait for anyone to.
+	TestTimeouts 는 먼저 c1 sleeper 와 1초 timeout 을 기다린 뒤 1초를 advance 해서 timeout 만 발사되게 한다.
+	그 다음 c2 sleeper 와 3초 timeout 도 기다린 뒤 2초를 advance 해서, 두 sleeper 는 깨우지만 timeout 은 깨우지 않는다.
+	마지막으로 select 에서 진 3초 timeout 을 지나서 advance 한다: 아무도 그것을 읽지 않고, Advance 는 누군가 읽기를 기다려서는 안 된다.
+*/
+func TestTimeouts(t *testing.T) {
+	fake := clock.NewFake(time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC))
+	var out bytes.Buffer
+	done := make(chan struct{})
+	go func() {
+		timeouts(fake, &out)
+		close(done)
+	}()
+
+	fake.BlockUntil(2)
+	fake.Advance(time.Second)
+	fake.BlockUntil(3)
+	fake.Advance(2 * time.Second)
+	<-done
+	fake.Advance(5 * time.Second)
+
+	if got, want := out.String(), "timeout 1\nresult 2\n"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
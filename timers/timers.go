@@ -2,7 +2,11 @@ package main
 
 import (
 	"fmt"
+	"io"
+	"os"
 	"time"
+
+	"gobyexample/internal/clock"
 )
 
 /*
@@ -14,7 +18,11 @@ import (
 	우리는 처음으로 timers 를 본 뒤에 tickers 를 볼 거임.
 */
 
-func main() {
+/*
+	The example takes its Clock and its output as arguments, so main can run it for real and TestTimers can replay it on a fake clock.
+	예제는 자신의 Clock 과 출력을 인자로 받아서, main 은 실제로 실행하고 TestTimers 는 가짜 clock 위에서 다시 재생할 수 있다.
+*/
+func timers(clock clock.Clock, out io.Writer) {
 	/*
 		Timers represent a single event in the future.
 		You tell the timer how long you want to wait, and it provides a channel that will be notified at that time.
@@ -23,14 +31,14 @@ func main() {
 		timer에게 얼마나 기다릴지를 말하면 그 시점에 알림을 받을 채널을 제공한다.
 		이 timer는 2초를 기다릴 것이다.
 	*/
-	timer1 := time.NewTimer(2 * time.Second)
+	timer1 := clock.NewTimer(2 * time.Second)
 
 	/*
 		The <-timer1.C blocks on the timer's channel C until it sends a value indicating that the timer field.
 		<-timer1.C 는 timer의 채널 C를 timer 필드가 나타내는 값을 보내기 전까지 막는다.
 	*/
-	<-timer1.C
-	fmt.Println("Timer 1 fired")
+	<-timer1.C()
+	fmt.Fprintln(out, "Timer 1 fired")
 
 	/*
 		If you just wanted to wait, you could have used time.Sleep.
@@ -40,23 +48,27 @@ func main() {
 		timer가 유용할 수 있는 한가지 이유는 timer 가 발사되기 전에 취소할 수 있다.
 		여기 그 예제가 있다.
 	*/
-	timer2 := time.NewTimer(time.Second)
+	timer2 := clock.NewTimer(time.Second)
 	go func() {
-		<-timer2.C
-		fmt.Println("Timer 2 fired")
+		<-timer2.C()
+		fmt.Fprintln(out, "Timer 2 fired")
 	}()
 	stop2 := timer2.Stop()
 	if stop2 {
-		fmt.Println("Timer2 stopped.")
+		fmt.Fprintln(out, "Timer2 stopped.")
 	}
 	/*
 		Give the timer2 enough time to fire, if it ever was going to, to show it is in fact stopped.
 		타이머2를 발사할 수 있는 충분한 시간을 주어 실제로 정지되었음을 표시한다.
 	*/
-	time.Sleep(2 * time.Second)
+	clock.Sleep(2 * time.Second)
 
 	/*
 		The first timer will fire ~2s after we start the program, but the second should be stopped before it has a chance to fire.
 		첫번째 timer는 우리가 프로그램을 시작하고 2초 후에 발사되지만 두번째 timer는 발사되기 전에 멈춰진다.
 	*/
 }
+
+func main() {
+	timers(clock.Real{}, os.Stdout)
+}
@@ -0,0 +1,35 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"gobyexample/internal/clock"
+)
+
+/*
+	TestTimers drives the example on a fake clock: wait until timer1 is set and fire it,
+	then wait until the example is sleeping - timer2 is stopped by then - and let the sleep run out.
+	TestTimers 는 가짜 clock 위에서 예제를 움직인다: timer1 이 설정될 때까지 기다려서 발사하고,
+	예제가 잠들 때까지 기다린 뒤(그때는 timer2 가 멈춰 있다) 잠이 끝나게 한다.
+*/
+func TestTimers(t *testing.T) {
+	fake := clock.NewFake(time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC))
+	var out bytes.Buffer
+	done := make(chan struct{})
+	go func() {
+		timers(fake, &out)
+		close(done)
+	}()
+
+	fake.BlockUntil(1)
+	fake.Advance(2 * time.Second)
+	fake.BlockUntilSleeping(1)
+	fake.Advance(2 * time.Second)
+	<-done
+
+	if got, want := out.String(), "Timer 1 fired\nTimer2 stopped.\n"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,270 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"gobyexample/internal/clock"
+)
+
+/*
+	Pool starts a fixed number of workers, which is too many when there is nothing to do and too few during a burst.
+	ScalingPool starts MinWorkers and lets the count move between MinWorkers and MaxWorkers.
+	It grows from a controller goroutine that looks at the queue every CheckInterval: if jobs are waiting and either there are
+	at least as many waiting as there are workers, or jobs have lately waited longer than TargetWait before a worker picked them up,
+	it starts one worker per waiting job, up to MaxWorkers.
+	It shrinks from the workers themselves: a worker that has had no job for IdleTimeout retires, unless that would leave fewer than MinWorkers.
+	A zero IdleTimeout or CheckInterval means 30s and 100ms, and a zero TargetWait means any wait at all is too long.
+	Pool 은 고정된 개수의 worker 들을 시작하는데, 이는 할 일이 없을 때는 너무 많고 폭주 중에는 너무 적다.
+	ScalingPool 은 MinWorkers 개로 시작하고 그 개수가 MinWorkers 와 MaxWorkers 사이에서 움직이게 한다.
+	CheckInterval 마다 큐를 보는 controller goroutine 에서 커진다: job 들이 기다리고 있고, 기다리는 것이 worker 만큼 많거나
+	최근 job 들이 worker 가 집어가기 전에 TargetWait 보다 오래 기다렸다면, 기다리는 job 하나당 worker 하나를 MaxWorkers 까지 시작한다.
+	worker 들 스스로에서 줄어든다: IdleTimeout 동안 job 이 없었던 worker 는 은퇴하는데, 그렇게 하면 MinWorkers 보다 적게 남는 경우는 예외이다.
+	IdleTimeout 이나 CheckInterval 이 0 이면 30초와 100ms 를 뜻하고, TargetWait 가 0 이면 조금이라도 기다리는 것은 너무 긴 것이다.
+*/
+type ScalingOptions struct {
+	MinWorkers    int
+	MaxWorkers    int
+	Queue         int
+	IdleTimeout   time.Duration
+	CheckInterval time.Duration
+	TargetWait    time.Duration
+}
+
+/*
+	ScalingStats is a snapshot of the pool. Queued counts the jobs no worker has picked up yet, including those in a Submit blocked on a full queue.
+	AvgWait is a moving average of how long jobs waited before a worker picked them up.
+	ScalingStats 는 pool 의 스냅샷이다. Queued 는 아직 어떤 worker 도 집어가지 않은 job 들을 세는데, 가득 찬 큐에 block 된 Submit 안의 것들도 포함한다.
+	AvgWait 는 job 들이 worker 가 집어가기 전에 기다린 시간의 이동 평균이다.
+*/
+type ScalingStats struct {
+	Workers   int
+	Peak      int
+	Queued    int
+	Started   int
+	Retired   int
+	Submitted int
+	Completed int
+	Failed    int
+	AvgWait   time.Duration
+}
+
+/*
+	ScalingPool shares Pool's queue, Submit, Shutdown and ShutdownNow and only decides how many workers read it. statsMu guards stats, which the workers update.
+	The controller's ticker, the workers' idle timers and the time a job waited are all on clock, so a Fake clock can run the pool without sleeping.
+	ScalingPool 은 Pool 의 큐, Submit, Shutdown 그리고 ShutdownNow 를 공유하고 몇 개의 worker 가 그것을 읽을지만 정한다. statsMu 는 worker 들이 갱신하는 stats 를 보호한다.
+	controller 의 ticker, worker 들의 idle timer 그리고 job 이 기다린 시간은 모두 clock 위에 있으므로, Fake clock 이 잠들지 않고 pool 을 실행할 수 있다.
+*/
+type ScalingPool[T any] struct {
+	*jobQueue[T]
+	opts    ScalingOptions
+	statsMu sync.Mutex
+	stats   ScalingStats
+}
+
+const (
+	defaultIdleTimeout   = 30 * time.Second
+	defaultCheckInterval = 100 * time.Millisecond
+)
+
+func NewScalingPool[T any](opts ScalingOptions) *ScalingPool[T] {
+	return NewScalingPoolWithClock[T](clock.Real{}, opts)
+}
+
+func NewScalingPoolWithClock[T any](clk clock.Clock, opts ScalingOptions) *ScalingPool[T] {
+	opts.MinWorkers = max(opts.MinWorkers, 0)
+	opts.MaxWorkers = max(opts.MaxWorkers, opts.MinWorkers, 1)
+	opts.Queue = max(opts.Queue, 0)
+	if opts.IdleTimeout <= 0 {
+		opts.IdleTimeout = defaultIdleTimeout
+	}
+	if opts.CheckInterval <= 0 {
+		opts.CheckInterval = defaultCheckInterval
+	}
+	opts.TargetWait = max(opts.TargetWait, 0)
+	p := &ScalingPool[T]{jobQueue: newJobQueue[T](clk, opts.Queue), opts: opts}
+	p.statsMu.Lock()
+	for w := 0; w < opts.MinWorkers; w++ {
+		p.spawn()
+	}
+	p.statsMu.Unlock()
+	p.workers.Add(1)
+	go func() {
+		defer p.workers.Done()
+		p.control()
+	}()
+	return p
+}
+
+func (p *ScalingPool[T]) Submit(ctx context.Context, fn func(context.Context) (T, error)) *Future[T] {
+	f, queued := p.submit(ctx, fn)
+	if queued {
+		p.statsMu.Lock()
+		p.stats.Submitted++
+		p.statsMu.Unlock()
+	}
+	return f
+}
+
+/*
+	spawn starts one worker. The caller holds statsMu.
+	spawn 은 worker 하나를 시작한다. 호출자는 statsMu 를 잡고 있다.
+*/
+func (p *ScalingPool[T]) spawn() {
+	p.stats.Workers++
+	p.stats.Started++
+	p.stats.Peak = max(p.stats.Peak, p.stats.Workers)
+	p.workers.Add(1)
+	go func() {
+		defer p.workers.Done()
+		p.work()
+	}()
+}
+
+func (p *ScalingPool[T]) control() {
+	ticker := p.clock.NewTicker(p.opts.CheckInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-p.closing:
+			return
+		case <-ticker.C():
+			p.grow()
+		}
+	}
+}
+
+/*
+	grow counts the Submits blocked on a full queue as waiting jobs, so with no queue and no workers the first Submit still gets one.
+	It does not take mu: a blocked Submit holds it for reading and Shutdown may be waiting to write, so grow would wait behind the very Submit it has to unblock.
+	The controller counts in workers instead, so Shutdown waits for it and no worker is started after Shutdown is done waiting;
+	one started after the queue is closed finds it closed and retires at once.
+	grow 는 가득 찬 큐에 block 된 Submit 들을 기다리는 job 으로 세므로, 큐도 worker 도 없을 때에도 첫 Submit 은 worker 하나를 얻는다.
+	grow 는 mu 를 잡지 않는다: block 된 Submit 이 그것을 읽기로 잡고 있고 Shutdown 이 쓰기를 기다리고 있을 수 있어서, grow 는 자신이 풀어줘야 하는 바로 그 Submit 뒤에서 기다리게 될 것이다.
+	대신 controller 가 workers 에 세어지므로, Shutdown 은 그것을 기다리고 Shutdown 이 기다리기를 끝낸 뒤에는 어떤 worker 도 시작되지 않는다;
+	큐가 닫힌 뒤에 시작된 worker 는 그것이 닫힌 것을 보고 바로 은퇴한다.
+*/
+func (p *ScalingPool[T]) grow() {
+	waiting := p.waiting()
+	p.statsMu.Lock()
+	defer p.statsMu.Unlock()
+	s := &p.stats
+	if waiting == 0 || s.Workers >= p.opts.MaxWorkers {
+		return
+	}
+	if waiting >= s.Workers || s.AvgWait > p.opts.TargetWait {
+		for n := min(waiting, p.opts.MaxWorkers-s.Workers); n > 0; n-- {
+			p.spawn()
+		}
+	}
+}
+
+/*
+	The idle timer is stopped while a job runs, so only idle workers have one on the clock.
+	idle timer 는 job 이 실행되는 동안 멈춰 있으므로, 한가한 worker 들만 시계 위에 그것을 가진다.
+*/
+func (p *ScalingPool[T]) work() {
+	idle := p.clock.NewTimer(p.opts.IdleTimeout)
+	defer idle.Stop()
+	for {
+		select {
+		case t, ok := <-p.queue:
+			if !ok {
+				p.statsMu.Lock()
+				p.stats.Workers--
+				p.statsMu.Unlock()
+				return
+			}
+			idle.Stop()
+			p.runTimed(t)
+			idle.Reset(p.opts.IdleTimeout)
+		case <-idle.C():
+			if p.retire() {
+				return
+			}
+			idle.Reset(p.opts.IdleTimeout)
+		}
+	}
+}
+
+func (p *ScalingPool[T]) retire() bool {
+	p.statsMu.Lock()
+	defer p.statsMu.Unlock()
+	if p.stats.Workers <= p.opts.MinWorkers {
+		return false
+	}
+	p.stats.Workers--
+	p.stats.Retired++
+	return true
+}
+
+func (p *ScalingPool[T]) runTimed(t task[T]) {
+	wait := p.clock.Since(t.at)
+	p.statsMu.Lock()
+	p.stats.AvgWait += (wait - p.stats.AvgWait) / 4
+	p.statsMu.Unlock()
+
+	err := p.run(t)
+
+	p.statsMu.Lock()
+	if err != nil {
+		p.stats.Failed++
+	} else {
+		p.stats.Completed++
+	}
+	p.statsMu.Unlock()
+}
+
+func (p *ScalingPool[T]) Size() int {
+	p.statsMu.Lock()
+	defer p.statsMu.Unlock()
+	return p.stats.Workers
+}
+
+func (p *ScalingPool[T]) Stats() ScalingStats {
+	p.statsMu.Lock()
+	defer p.statsMu.Unlock()
+	s := p.stats
+	s.Queued = p.waiting()
+	return s
+}
+
+/*
+	scalingExample sends a burst of jobs to a pool that starts with one worker, then stays quiet for longer than IdleTimeout.
+	The pool grows towards MaxWorkers for the burst and the idle workers retire back to MinWorkers afterwards.
+	TestScalingPool in autoscale_test.go checks the same on a Fake clock.
+	scalingExample 은 worker 하나로 시작하는 pool 에 job 들을 한꺼번에 보내고, 그 뒤 IdleTimeout 보다 오래 조용히 있는다.
+	pool 은 폭주를 위해 MaxWorkers 쪽으로 커지고 그 뒤 한가한 worker 들은 MinWorkers 로 은퇴한다.
+	autoscale_test.go 의 TestScalingPool 은 같은 것을 Fake clock 위에서 확인한다.
+*/
+func scalingExample(out io.Writer) {
+	p := NewScalingPool[int](ScalingOptions{
+		MinWorkers:    1,
+		MaxWorkers:    8,
+		Queue:         100,
+		IdleTimeout:   50 * time.Millisecond,
+		CheckInterval: 2 * time.Millisecond,
+		TargetWait:    5 * time.Millisecond,
+	})
+	ctx := context.Background()
+	burst := make([]*Future[int], 40)
+	for i := range burst {
+		burst[i] = p.Submit(ctx, func(context.Context) (int, error) {
+			time.Sleep(10 * time.Millisecond)
+			return i, nil
+		})
+	}
+	for _, f := range burst {
+		f.Wait()
+	}
+	s := p.Stats()
+	fmt.Fprintf(out, "after burst: workers=%d peak=%d completed=%d\n", s.Workers, s.Peak, s.Completed)
+
+	time.Sleep(200 * time.Millisecond)
+	s = p.Stats()
+	fmt.Fprintf(out, "after quiet: workers=%d started=%d retired=%d\n", s.Workers, s.Started, s.Retired)
+	fmt.Fprintln(out, "shutdown:", p.Shutdown(ctx))
+}
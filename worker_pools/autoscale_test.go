@@ -0,0 +1,267 @@
+package main
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"gobyexample/internal/clock"
+)
+
+/*
+	TestScalingOptions checks that NewScalingPool fills in what the options leave out instead of starting a ticker with no interval.
+	TestScalingOptions 는 NewScalingPool 이 간격 없는 ticker 를 시작하는 대신 옵션이 빠뜨린 것을 채우는지 확인한다.
+*/
+func TestScalingOptions(t *testing.T) {
+	defaults := ScalingOptions{MaxWorkers: 1, IdleTimeout: defaultIdleTimeout, CheckInterval: defaultCheckInterval}
+	for _, tc := range []struct {
+		name string
+		opts ScalingOptions
+		want ScalingOptions
+	}{
+		{"zero", ScalingOptions{}, defaults},
+		{"negative", ScalingOptions{MinWorkers: -1, MaxWorkers: -1, Queue: -1, IdleTimeout: -1, CheckInterval: -1, TargetWait: -1}, defaults},
+		{"max below min", ScalingOptions{MinWorkers: 3, MaxWorkers: 2}, ScalingOptions{MinWorkers: 3, MaxWorkers: 3, IdleTimeout: defaultIdleTimeout, CheckInterval: defaultCheckInterval}},
+		{
+			"all set",
+			ScalingOptions{MinWorkers: 1, MaxWorkers: 4, Queue: 10, IdleTimeout: time.Minute, CheckInterval: time.Second, TargetWait: time.Millisecond},
+			ScalingOptions{MinWorkers: 1, MaxWorkers: 4, Queue: 10, IdleTimeout: time.Minute, CheckInterval: time.Second, TargetWait: time.Millisecond},
+		},
+	} {
+		p := NewScalingPoolWithClock[int](clock.NewFake(time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)), tc.opts)
+		if p.opts != tc.want {
+			t.Errorf("%s: options %+v, want %+v", tc.name, p.opts, tc.want)
+		}
+		if cap(p.queue) != tc.want.Queue {
+			t.Errorf("%s: queue of %d, want %d", tc.name, cap(p.queue), tc.want.Queue)
+		}
+		p.ShutdownNow()
+	}
+}
+
+/*
+	TestScalingPool sends a burst of jobs that hold their workers until they are released, ticks the controller once,
+	and expects the pool at MaxWorkers; then it lets the workers sit idle for IdleTimeout and expects them back at MinWorkers.
+	Last comes a sustained load: load jobs are always held, and every CheckInterval one of them finishes and a new one takes its place.
+	After one IdleTimeout of that the pool must have settled between load and MaxWorkers workers and stay there for another:
+	it neither retires workers it keeps busy nor grows, since the controller only ticks once the new job has a worker.
+	With no queue and no workers, the burst is only Submits blocked on the queue, and the pool must grow for those as well.
+	TestScalingPool 은 풀려날 때까지 자신의 worker 를 잡고 있는 job 들을 한꺼번에 보내고, controller 를 한 번 tick 시킨 뒤,
+	pool 이 MaxWorkers 에 있기를 기대한다; 그런 다음 worker 들을 IdleTimeout 동안 한가하게 두고 그들이 MinWorkers 로 돌아오기를 기대한다.
+	마지막은 지속되는 부하이다: load 개의 job 이 항상 잡혀 있고, 매 CheckInterval 마다 그중 하나가 끝나고 새 것이 그 자리를 차지한다.
+	그것이 IdleTimeout 동안 계속된 뒤 pool 은 load 개와 MaxWorkers 개 사이의 worker 에 자리잡고 또 한 번의 IdleTimeout 동안 그대로 있어야 한다:
+	controller 는 새 job 이 worker 를 얻은 뒤에만 tick 하므로, 자신이 바쁘게 유지하는 worker 들을 은퇴시키지도 커지지도 않는다.
+	큐도 worker 도 없으면, 폭주는 큐에 block 된 Submit 들뿐이고, pool 은 그것들을 위해서도 커져야 한다.
+*/
+func TestScalingPool(t *testing.T) {
+	const (
+		maxWorkers = 4
+		jobs       = 8
+		load       = 2
+	)
+	for _, tc := range []struct {
+		name       string
+		minWorkers int
+		queue      int
+	}{
+		{"queued", 1, 100},
+		{"blocked submitters", 0, 0},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			fake := clock.NewFake(time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC))
+			opts := ScalingOptions{
+				MinWorkers:    tc.minWorkers,
+				MaxWorkers:    maxWorkers,
+				Queue:         tc.queue,
+				IdleTimeout:   time.Minute,
+				CheckInterval: time.Second,
+				TargetWait:    time.Hour,
+			}
+			p := NewScalingPoolWithClock[int](fake, opts)
+			ctx := context.Background()
+			fake.BlockUntil(1 + tc.minWorkers)
+
+			started := make(chan int, jobs)
+			release := make(chan struct{})
+			futures := make([]*Future[int], jobs)
+			var submitted sync.WaitGroup
+			for i := range futures {
+				submitted.Add(1)
+				go func() {
+					defer submitted.Done()
+					futures[i] = p.Submit(ctx, func(context.Context) (int, error) {
+						started <- i
+						<-release
+						return i, nil
+					})
+				}()
+			}
+			for range tc.minWorkers {
+				<-started
+			}
+			for p.Stats().Queued != jobs-tc.minWorkers {
+				time.Sleep(time.Millisecond)
+			}
+
+			fake.Advance(opts.CheckInterval)
+			for range maxWorkers - tc.minWorkers {
+				<-started
+			}
+			for p.Stats().Queued != jobs-maxWorkers {
+				time.Sleep(time.Millisecond)
+			}
+			if s := p.Stats(); s.Workers != maxWorkers || s.Peak != maxWorkers {
+				t.Errorf("burst: %+v, want %d workers", s, maxWorkers)
+			}
+
+			close(release)
+			submitted.Wait()
+			for i, f := range futures {
+				if val, err := f.Result(); val != i || err != nil {
+					t.Errorf("job %d: %d, %v", i, val, err)
+				}
+			}
+			fake.BlockUntil(1 + maxWorkers)
+			fake.Advance(opts.IdleTimeout)
+			for p.Size() != tc.minWorkers {
+				time.Sleep(time.Millisecond)
+			}
+			if s := p.Stats(); s.Started != maxWorkers || s.Retired != maxWorkers-tc.minWorkers {
+				t.Errorf("idle: %+v, want %d started and %d retired", s, maxWorkers, maxWorkers-tc.minWorkers)
+			}
+
+			var holds []chan struct{}
+			running := make(chan struct{}, load)
+			sustain := func() {
+				hold := make(chan struct{})
+				holds = append(holds, hold)
+				submitted.Add(1)
+				go func() {
+					defer submitted.Done()
+					p.Submit(ctx, func(ctx context.Context) (int, error) {
+						running <- struct{}{}
+						select {
+						case <-hold:
+							return 0, nil
+						case <-ctx.Done():
+							return 0, ctx.Err()
+						}
+					})
+				}()
+			}
+			for range load {
+				sustain()
+			}
+			for range tc.minWorkers {
+				<-running
+			}
+			for p.Stats().Queued != load-tc.minWorkers {
+				time.Sleep(time.Millisecond)
+			}
+			fake.Advance(opts.CheckInterval)
+			for range load - tc.minWorkers {
+				<-running
+			}
+			half := int(opts.IdleTimeout / opts.CheckInterval)
+			rounds := 2 * half
+			var settled ScalingStats
+			for round := range rounds {
+				if round == half {
+					settled = p.Stats()
+				}
+				close(holds[0])
+				holds = holds[1:]
+				sustain()
+				<-running
+				for p.Stats().Queued != 0 {
+					time.Sleep(time.Millisecond)
+				}
+				fake.Advance(opts.CheckInterval)
+			}
+			if s := p.Stats(); s.Workers < load || s.Workers > maxWorkers || s.Workers != settled.Workers || s.Started != settled.Started || s.Retired != settled.Retired {
+				t.Errorf("sustained: %+v, was %+v an IdleTimeout earlier, want %d to %d workers and no change", s, settled, load, maxWorkers)
+			}
+
+			for _, hold := range holds {
+				close(hold)
+			}
+			submitted.Wait()
+			if err := p.Shutdown(ctx); err != nil {
+				t.Errorf("shutdown: %v", err)
+			}
+			total := jobs + load + rounds
+			if s := p.Stats(); s.Workers != 0 || s.Submitted != total || s.Completed != total {
+				t.Errorf("shutdown: %+v, want no workers and %d jobs completed", s, total)
+			}
+		})
+	}
+}
+
+/*
+	TestScalingPoolWaitTime keeps fewer jobs waiting than there are workers, so the pool only grows once jobs have waited longer than TargetWait.
+	The controller ticks once, after a job has waited that long, so it cannot see the waiting job twice.
+	TestScalingPoolWaitTime 은 worker 보다 적은 job 들을 기다리게 하므로, pool 은 job 들이 TargetWait 보다 오래 기다린 뒤에만 커진다.
+	controller 는 job 하나가 그만큼 기다린 뒤에 한 번만 tick 하므로, 기다리는 job 을 두 번 볼 수 없다.
+*/
+func TestScalingPoolWaitTime(t *testing.T) {
+	fake := clock.NewFake(time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC))
+	opts := ScalingOptions{
+		MinWorkers:    2,
+		MaxWorkers:    4,
+		Queue:         10,
+		IdleTimeout:   time.Hour,
+		CheckInterval: 100 * time.Millisecond,
+		TargetWait:    5 * time.Millisecond,
+	}
+	p := NewScalingPoolWithClock[string](fake, opts)
+	defer p.ShutdownNow()
+	ctx := context.Background()
+	fake.BlockUntil(1 + opts.MinWorkers)
+
+	started := make(chan string, 4)
+	release := make(map[string]chan struct{})
+	submit := func(name string) {
+		release[name] = make(chan struct{})
+		wait := release[name]
+		p.Submit(ctx, func(ctx context.Context) (string, error) {
+			started <- name
+			select {
+			case <-wait:
+				return name, nil
+			case <-ctx.Done():
+				return "", ctx.Err()
+			}
+		})
+	}
+	queued := func(n int) {
+		for p.Stats().Queued != n {
+			time.Sleep(time.Millisecond)
+		}
+	}
+
+	submit("a")
+	submit("b")
+	<-started
+	<-started
+	submit("c")
+	queued(1)
+	fake.Advance(30 * time.Millisecond)
+
+	close(release["a"])
+	if name := <-started; name != "c" {
+		t.Fatalf("started %s, want c", name)
+	}
+	if s := p.Stats(); s.AvgWait <= opts.TargetWait {
+		t.Fatalf("average wait %v, want more than %v", s.AvgWait, opts.TargetWait)
+	}
+	submit("d")
+	queued(1)
+	fake.Advance(opts.CheckInterval - 30*time.Millisecond)
+	if name := <-started; name != "d" {
+		t.Fatalf("started %s, want d", name)
+	}
+	if s := p.Stats(); s.Workers != 3 || s.Peak != 3 {
+		t.Errorf("after a long wait: %+v, want 3 workers", s)
+	}
+}
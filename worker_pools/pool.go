@@ -0,0 +1,350 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"runtime/debug"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"gobyexample/internal/clock"
+)
+
+/*
+	The workers in main read jobs and send results, but a result is only a number: a job cannot fail, and main throws the results away.
+	Pool keeps the same shape, a fixed set of workers ranging over a jobs channel, and hands back a Future for every job it accepts.
+	The Future holds what the job returned, its value and its error, so the caller knows which inputs failed and why.
+	A panic in a job is recovered in the worker and becomes a PanicError, so one bad input cannot take the pool, or the program, down.
+	main 의 worker 들은 job 을 읽고 결과를 보내지만, 결과는 숫자일 뿐이다: job 은 실패할 수 없고, main 은 결과들을 버린다.
+	Pool 은 같은 모양, 즉 jobs 채널 위를 range 하는 고정된 worker 들을 유지하고, 받아들이는 모든 job 에 대해 Future 를 돌려준다.
+	Future 는 job 이 반환한 것, 그것의 값과 에러를 가지므로, 호출자는 어떤 입력이 왜 실패했는지 안다.
+	job 안의 panic 은 worker 에서 recover 되어 PanicError 가 되므로, 나쁜 입력 하나가 pool 이나 프로그램을 쓰러뜨릴 수 없다.
+*/
+
+var (
+	ErrPoolClosed  = errors.New("pool: closed")
+	ErrJobCanceled = errors.New("pool: job canceled before it ran")
+)
+
+/*
+	PanicError is what a job's panic becomes, with the stack where it happened.
+	PanicError 는 job 의 panic 이 변한 것으로, panic 이 일어난 곳의 스택을 가진다.
+*/
+type PanicError struct {
+	Value any
+	Stack []byte
+}
+
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("panic: %v", e.Value)
+}
+
+/*
+	Future is the result of one job. Done is closed when the job has finished; Wait blocks until then,
+	and Result and Err wait too before they return what the job returned.
+	Future 는 job 하나의 결과이다. Done 은 job 이 끝나면 닫힌다; Wait 는 그때까지 block 하며,
+	Result 와 Err 도 job 이 반환한 것을 반환하기 전에 기다린다.
+*/
+type Future[T any] struct {
+	done chan struct{}
+	val  T
+	err  error
+}
+
+func newFuture[T any]() *Future[T] {
+	return &Future[T]{done: make(chan struct{})}
+}
+
+func (f *Future[T]) complete(val T, err error) {
+	f.val, f.err = val, err
+	close(f.done)
+}
+
+func (f *Future[T]) fail(err error) *Future[T] {
+	var zero T
+	f.complete(zero, err)
+	return f
+}
+
+func (f *Future[T]) Done() <-chan struct{} {
+	return f.done
+}
+
+func (f *Future[T]) Wait() {
+	<-f.done
+}
+
+func (f *Future[T]) Result() (T, error) {
+	<-f.done
+	return f.val, f.err
+}
+
+func (f *Future[T]) Err() error {
+	<-f.done
+	return f.err
+}
+
+type task[T any] struct {
+	ctx    context.Context
+	fn     func(context.Context) (T, error)
+	future *Future[T]
+	at     time.Time
+}
+
+/*
+	jobQueue is the part Pool and ScalingPool share: the queue, Submit, Shutdown and ShutdownNow, and running one job; they differ in how many workers read the queue.
+	stop is canceled when the pool gives up on its jobs: jobs still queued then fail with ErrJobCanceled and the context of running jobs is canceled.
+	mu guards closed, so no Submit sends on the queue after it is closed, and closing is closed along with the queue for anything else that has to stop then.
+	blocked counts the Submits waiting for room in a full queue, which are jobs waiting for a worker as much as the queued ones are.
+	jobQueue 는 Pool 과 ScalingPool 이 공유하는 부분이다: 큐, Submit, Shutdown 과 ShutdownNow, 그리고 job 하나의 실행; 그들은 몇 개의 worker 가 큐를 읽는지만 다르다.
+	stop 은 pool 이 자신의 job 들을 포기할 때 취소된다: 그때 아직 큐에 있는 job 들은 ErrJobCanceled 로 실패하고 실행 중인 job 들의 context 는 취소된다.
+	mu 는 closed 를 보호하므로, 큐가 닫힌 뒤에는 어떤 Submit 도 그것에 보내지 않으며, closing 은 그때 멈춰야 하는 다른 것들을 위해 큐와 함께 닫힌다.
+	blocked 는 가득 찬 큐에 자리가 나기를 기다리는 Submit 들을 세는데, 그것들도 큐에 있는 것들만큼 worker 를 기다리는 job 들이다.
+*/
+type jobQueue[T any] struct {
+	queue   chan task[T]
+	clock   clock.Clock
+	stop    context.Context
+	cancel  context.CancelFunc
+	closing chan struct{}
+	mu      sync.RWMutex
+	closed  bool
+	blocked atomic.Int64
+	workers sync.WaitGroup
+}
+
+func newJobQueue[T any](clk clock.Clock, size int) *jobQueue[T] {
+	stop, cancel := context.WithCancel(context.Background())
+	return &jobQueue[T]{
+		queue:   make(chan task[T], max(size, 0)),
+		clock:   clk,
+		stop:    stop,
+		cancel:  cancel,
+		closing: make(chan struct{}),
+	}
+}
+
+/*
+	Pool runs jobs on a fixed number of workers. Jobs wait in a queue of a fixed size, so Submit blocks when the workers are behind.
+	Pool 은 고정된 개수의 worker 들 위에서 job 들을 실행한다. job 들은 고정된 크기의 큐에서 기다리므로, worker 들이 뒤쳐지면 Submit 은 block 한다.
+*/
+type Pool[T any] struct {
+	*jobQueue[T]
+}
+
+func NewPool[T any](workers, queue int) *Pool[T] {
+	return NewPoolWithClock[T](clock.Real{}, workers, queue)
+}
+
+func NewPoolWithClock[T any](clk clock.Clock, workers, queue int) *Pool[T] {
+	p := &Pool[T]{newJobQueue[T](clk, queue)}
+	for w := 0; w < max(workers, 1); w++ {
+		p.workers.Add(1)
+		go func() {
+			defer p.workers.Done()
+			p.work()
+		}()
+	}
+	return p
+}
+
+/*
+	Submit queues fn and returns its Future. fn gets a context that is canceled when ctx is, or when the pool gives up on its jobs.
+	If the pool is closed, or ctx ends while Submit waits for room in the queue, the Future has already failed.
+	Submit 은 fn 을 큐에 넣고 그것의 Future 를 반환한다. fn 은 ctx 가 취소되거나, pool 이 자신의 job 들을 포기할 때 취소되는 context 를 받는다.
+	pool 이 닫혔거나, Submit 이 큐에 자리가 나기를 기다리는 동안 ctx 가 끝나면, Future 는 이미 실패해 있다.
+*/
+func (p *Pool[T]) Submit(ctx context.Context, fn func(context.Context) (T, error)) *Future[T] {
+	f, _ := p.submit(ctx, fn)
+	return f
+}
+
+/*
+	submit is Submit that also reports whether the job was queued.
+	submit 은 job 이 큐에 들어갔는지도 알려주는 Submit 이다.
+*/
+func (q *jobQueue[T]) submit(ctx context.Context, fn func(context.Context) (T, error)) (*Future[T], bool) {
+	f := newFuture[T]()
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+	if q.closed {
+		return f.fail(ErrPoolClosed), false
+	}
+	t := task[T]{ctx: ctx, fn: fn, future: f, at: q.clock.Now()}
+	select {
+	case q.queue <- t:
+		return f, true
+	default:
+	}
+	q.blocked.Add(1)
+	defer q.blocked.Add(-1)
+	select {
+	case q.queue <- t:
+		return f, true
+	case <-ctx.Done():
+		return f.fail(ctx.Err()), false
+	case <-q.stop.Done():
+		return f.fail(ErrPoolClosed), false
+	}
+}
+
+/*
+	waiting is the number of jobs no worker has picked up yet, in the queue or in a blocked Submit.
+	waiting 은 아직 어떤 worker 도 집어가지 않은 job 의 수로, 큐에 있거나 block 된 Submit 안에 있는 것들이다.
+*/
+func (q *jobQueue[T]) waiting() int {
+	return len(q.queue) + int(q.blocked.Load())
+}
+
+func (p *Pool[T]) work() {
+	for t := range p.queue {
+		p.run(t)
+	}
+}
+
+/*
+	run finishes t's Future and returns its error. A job whose pool has given up, or whose ctx ended while it was queued, is not started.
+	run 은 t 의 Future 를 끝내고 그것의 에러를 반환한다. pool 이 포기했거나, 큐에 있는 동안 ctx 가 끝난 job 은 시작되지 않는다.
+*/
+func (q *jobQueue[T]) run(t task[T]) error {
+	if q.stop.Err() != nil {
+		t.future.fail(ErrJobCanceled)
+		return ErrJobCanceled
+	}
+	if err := t.ctx.Err(); err != nil {
+		t.future.fail(err)
+		return err
+	}
+	val, err := runTask(q.stop, t)
+	t.future.complete(val, err)
+	return err
+}
+
+/*
+	runTask runs one job with a context that also ends when stop does, and turns a panic into a PanicError.
+	runTask 는 stop 이 끝날 때도 끝나는 context 로 job 하나를 실행하고, panic 을 PanicError 로 바꾼다.
+*/
+func runTask[T any](stop context.Context, t task[T]) (val T, err error) {
+	ctx, cancel := context.WithCancel(t.ctx)
+	defer cancel()
+	defer context.AfterFunc(stop, cancel)()
+	defer func() {
+		if r := recover(); r != nil {
+			err = &PanicError{Value: r, Stack: debug.Stack()}
+		}
+	}()
+	return t.fn(ctx)
+}
+
+func (q *jobQueue[T]) close() {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if !q.closed {
+		q.closed = true
+		close(q.queue)
+		close(q.closing)
+	}
+}
+
+/*
+	Shutdown stops accepting jobs and drains the queue: every job already accepted runs, and Shutdown returns when they have all finished.
+	If ctx ends first, the pool gives up on the rest as ShutdownNow does, waits for the running jobs to return, and returns ctx's error.
+	Shutdown 은 job 을 받아들이는 것을 멈추고 큐를 비운다: 이미 받아들여진 모든 job 은 실행되고, Shutdown 은 그것들이 모두 끝나면 반환한다.
+	ctx 가 먼저 끝나면, pool 은 ShutdownNow 처럼 나머지를 포기하고, 실행 중인 job 들이 반환하기를 기다린 뒤, ctx 의 에러를 반환한다.
+*/
+func (q *jobQueue[T]) Shutdown(ctx context.Context) error {
+	drained := make(chan struct{})
+	go func() {
+		q.close()
+		q.workers.Wait()
+		close(drained)
+	}()
+	select {
+	case <-drained:
+		return nil
+	case <-ctx.Done():
+		q.cancel()
+		<-drained
+		return ctx.Err()
+	}
+}
+
+/*
+	ShutdownNow stops accepting jobs, fails every queued job with ErrJobCanceled, cancels the context of the running ones and waits for them to return.
+	ShutdownNow 는 job 을 받아들이는 것을 멈추고, 큐에 있는 모든 job 을 ErrJobCanceled 로 실패시키고, 실행 중인 것들의 context 를 취소하고 그것들이 반환하기를 기다린다.
+*/
+func (q *jobQueue[T]) ShutdownNow() {
+	q.cancel()
+	q.close()
+	q.workers.Wait()
+}
+
+/*
+	poolExample parses a batch of inputs, some of which are bad in different ways, and reports each failure with its input.
+	Then it queues more slow jobs than a deadline allows and shows what Shutdown does with the ones that do not make it.
+	poolExample 은 일부가 서로 다른 방식으로 나쁜 입력들의 묶음을 파싱하고, 각 실패를 그것의 입력과 함께 알린다.
+	그런 다음 기한이 허락하는 것보다 많은 느린 job 들을 큐에 넣고 Shutdown 이 시간 안에 못 끝내는 것들을 어떻게 하는지 보여준다.
+*/
+func poolExample(out io.Writer) {
+	ctx := context.Background()
+	pool := NewPool[int](3, 5)
+	inputs := []string{"1", "2", "three", "4", "", "6"}
+	futures := make([]*Future[int], len(inputs))
+	for i, in := range inputs {
+		futures[i] = pool.Submit(ctx, func(context.Context) (int, error) {
+			time.Sleep(10 * time.Millisecond)
+			if in == "" {
+				var digits []byte
+				return int(digits[0]), nil
+			}
+			n, err := strconv.Atoi(in)
+			return n * 2, err
+		})
+	}
+	for i, f := range futures {
+		n, err := f.Result()
+		var panicErr *PanicError
+		switch {
+		case errors.As(err, &panicErr):
+			fmt.Fprintf(out, "input %q: job panicked: %v\n", inputs[i], panicErr.Value)
+		case err != nil:
+			fmt.Fprintf(out, "input %q: %v\n", inputs[i], err)
+		default:
+			fmt.Fprintf(out, "input %q: %d\n", inputs[i], n)
+		}
+	}
+	fmt.Fprintln(out, "shutdown:", pool.Shutdown(ctx))
+	fmt.Fprintln(out, "submit after shutdown:", pool.Submit(ctx, func(context.Context) (int, error) { return 0, nil }).Err())
+
+	slow := NewPool[int](2, 10)
+	futures = futures[:0]
+	for j := 1; j <= 10; j++ {
+		futures = append(futures, slow.Submit(ctx, func(ctx context.Context) (int, error) {
+			select {
+			case <-time.After(20 * time.Millisecond):
+				return j, nil
+			case <-ctx.Done():
+				return 0, ctx.Err()
+			}
+		}))
+	}
+	deadline, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
+	defer cancel()
+	fmt.Fprintln(out, "shutdown with deadline:", slow.Shutdown(deadline))
+	counts := make(map[string]int)
+	for _, f := range futures {
+		switch err := f.Err(); {
+		case err == nil:
+			counts["done"]++
+		case errors.Is(err, ErrJobCanceled):
+			counts["canceled before running"]++
+		case errors.Is(err, context.Canceled):
+			counts["canceled while running"]++
+		}
+	}
+	fmt.Fprintln(out, counts)
+}
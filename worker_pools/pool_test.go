@@ -0,0 +1,195 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+/*
+	TestPoolJobs runs one job per case and checks what its Future holds: the job's value, its error, or the PanicError its panic became.
+	TestPoolJobs 는 경우마다 job 하나를 실행하고 그것의 Future 가 가진 것을 확인한다: job 의 값, 그것의 에러, 또는 그것의 panic 이 변한 PanicError.
+*/
+func TestPoolJobs(t *testing.T) {
+	errBad := errors.New("bad input")
+	pool := NewPool[int](2, 4)
+	defer pool.ShutdownNow()
+	for _, tc := range []struct {
+		name  string
+		fn    func(context.Context) (int, error)
+		want  int
+		isErr func(error) bool
+	}{
+		{"value", func(context.Context) (int, error) { return 42, nil }, 42, nil},
+		{"error", func(context.Context) (int, error) { return 0, errBad }, 0, func(err error) bool { return errors.Is(err, errBad) }},
+		{"panic", func(context.Context) (int, error) {
+			var digits []byte
+			return int(digits[0]), nil
+		}, 0, func(err error) bool {
+			var pe *PanicError
+			return errors.As(err, &pe) && len(pe.Stack) > 0
+		}},
+		{"panic with a value", func(context.Context) (int, error) { panic("boom") }, 0, func(err error) bool {
+			var pe *PanicError
+			return errors.As(err, &pe) && pe.Value == "boom" && err.Error() == "panic: boom"
+		}},
+		{"job context is live", func(ctx context.Context) (int, error) { return 1, ctx.Err() }, 1, nil},
+	} {
+		f := pool.Submit(context.Background(), tc.fn)
+		val, err := f.Result()
+		if val != tc.want || tc.isErr == nil && err != nil || tc.isErr != nil && !tc.isErr(err) {
+			t.Errorf("%s: got %d, %v, want %d", tc.name, val, err, tc.want)
+		}
+		select {
+		case <-f.Done():
+		default:
+			t.Errorf("%s: Done is open after Result returned", tc.name)
+		}
+	}
+}
+
+/*
+	TestPoolSubmit checks the Submits that fail without running the job: after the pool is closed, and while waiting for room in a full queue,
+	which ends with the caller's ctx or with ShutdownNow. Either way the Future has already failed when Submit returns.
+	TestPoolSubmit 은 job 을 실행하지 않고 실패하는 Submit 들을 확인한다: pool 이 닫힌 뒤, 그리고 가득 찬 큐에 자리가 나기를 기다리는 동안,
+	이것은 호출자의 ctx 나 ShutdownNow 와 함께 끝난다. 어느 쪽이든 Submit 이 반환할 때 Future 는 이미 실패해 있다.
+*/
+func TestPoolSubmit(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		full bool
+		ctx  func() (context.Context, context.CancelFunc)
+		stop func(p *Pool[int])
+		want error
+	}{
+		{"after Shutdown", false, nil, func(p *Pool[int]) { p.Shutdown(context.Background()) }, ErrPoolClosed},
+		{"after ShutdownNow", false, nil, func(p *Pool[int]) { p.ShutdownNow() }, ErrPoolClosed},
+		{"full queue until the deadline", true, func() (context.Context, context.CancelFunc) {
+			return context.WithTimeout(context.Background(), 10*time.Millisecond)
+		}, nil, context.DeadlineExceeded},
+		{"full queue until ShutdownNow", true, nil, func(p *Pool[int]) {
+			for p.waiting() == 0 {
+				time.Sleep(time.Millisecond)
+			}
+			p.ShutdownNow()
+		}, ErrPoolClosed},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			p := NewPool[int](1, 0)
+			defer p.ShutdownNow()
+			if tc.full {
+				started := make(chan struct{})
+				p.Submit(context.Background(), func(ctx context.Context) (int, error) {
+					close(started)
+					<-ctx.Done()
+					return 0, ctx.Err()
+				})
+				<-started
+			}
+			ctx, cancel := context.Background(), context.CancelFunc(func() {})
+			if tc.ctx != nil {
+				ctx, cancel = tc.ctx()
+			}
+			defer cancel()
+			switch {
+			case tc.stop == nil:
+			case tc.full:
+				go tc.stop(p)
+			default:
+				tc.stop(p)
+			}
+
+			ran := false
+			f := p.Submit(ctx, func(context.Context) (int, error) {
+				ran = true
+				return 1, nil
+			})
+			select {
+			case <-f.Done():
+			default:
+				t.Fatalf("Submit returned a Future that has not failed")
+			}
+			if err := f.Err(); !errors.Is(err, tc.want) || ran {
+				t.Errorf("got %v, ran %v, want %v without running", err, ran, tc.want)
+			}
+		})
+	}
+}
+
+/*
+	TestPoolShutdown holds one worker in job a while b and c wait in the queue, then shuts the pool down three ways.
+	Shutdown drains: once the jobs are let go all three finish. Shutdown with a deadline that passes first and ShutdownNow give up instead:
+	a's context is canceled, and b and c fail with ErrJobCanceled without running.
+	TestPoolShutdown 은 b 와 c 가 큐에서 기다리는 동안 worker 하나를 job a 에 잡아두고, 그 다음 세 가지 방법으로 pool 을 멈춘다.
+	Shutdown 은 비운다: job 들이 풀려나면 셋 모두 끝난다. 먼저 지나가는 기한을 가진 Shutdown 과 ShutdownNow 는 대신 포기한다:
+	a 의 context 는 취소되고, b 와 c 는 실행되지 않고 ErrJobCanceled 로 실패한다.
+*/
+func TestPoolShutdown(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		shutdown func(p *Pool[int]) error
+		release  bool
+		err      error
+		want     []error
+	}{
+		{"Shutdown drains", func(p *Pool[int]) error {
+			return p.Shutdown(context.Background())
+		}, true, nil, []error{nil, nil, nil}},
+		{"Shutdown past its deadline", func(p *Pool[int]) error {
+			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+			defer cancel()
+			return p.Shutdown(ctx)
+		}, false, context.DeadlineExceeded, []error{context.Canceled, ErrJobCanceled, ErrJobCanceled}},
+		{"ShutdownNow", func(p *Pool[int]) error {
+			p.ShutdownNow()
+			return nil
+		}, false, nil, []error{context.Canceled, ErrJobCanceled, ErrJobCanceled}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			p := NewPool[int](1, 2)
+			started := make(chan int, 3)
+			release := make(chan struct{})
+			var futures []*Future[int]
+			for i := range 3 {
+				futures = append(futures, p.Submit(context.Background(), func(ctx context.Context) (int, error) {
+					started <- i
+					select {
+					case <-release:
+						return i, nil
+					case <-ctx.Done():
+						return 0, ctx.Err()
+					}
+				}))
+				if i == 0 {
+					<-started
+				}
+			}
+
+			result := make(chan error)
+			go func() { result <- tc.shutdown(p) }()
+			if tc.release {
+				<-p.closing
+				if err := p.Submit(context.Background(), func(context.Context) (int, error) { return 0, nil }).Err(); !errors.Is(err, ErrPoolClosed) {
+					t.Errorf("submit while draining: %v, want ErrPoolClosed", err)
+				}
+				close(release)
+			}
+			if err := <-result; !errors.Is(err, tc.err) {
+				t.Errorf("shutdown returned %v, want %v", err, tc.err)
+			}
+
+			for i, f := range futures {
+				select {
+				case <-f.Done():
+				default:
+					t.Fatalf("job %d has not finished after the shutdown returned", i)
+				}
+				val, err := f.Result()
+				if !errors.Is(err, tc.want[i]) || tc.want[i] == nil && (err != nil || val != i) {
+					t.Errorf("job %d: %d, %v, want %v", i, val, err, tc.want[i])
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,375 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"math/rand/v2"
+	"sync"
+	"time"
+
+	"gobyexample/internal/clock"
+)
+
+/*
+	A job that fails in Pool fails for good, even when the failure was a timeout or a busy server that would have worked a moment later.
+	Retrier submits a job to a Pool and, when it fails with an error the policy calls retryable, submits it again after a backoff,
+	up to MaxAttempts times. The backoff is waited out on a Clock outside the pool, so a job waiting to be retried does not hold a worker,
+	and a FakeClock can run the whole schedule without sleeping.
+	A job that runs out of attempts, or fails with an error that is not retryable, goes to the dead-letter queue,
+	where it keeps its name, every error it got and the job itself, so it can be looked at and replayed once the cause is fixed.
+	Pool 에서 실패하는 job 은, 그 실패가 조금 뒤에는 성공했을 timeout 이나 바쁜 서버였더라도, 영영 실패한다.
+	Retrier 는 job 을 Pool 에 제출하고, 그것이 policy 가 재시도할 수 있다고 하는 에러로 실패하면, backoff 뒤에 그것을 다시 제출하는데,
+	MaxAttempts 번까지이다. backoff 는 pool 밖에서 Clock 위에서 기다려지므로, 재시도를 기다리는 job 은 worker 를 잡고 있지 않으며,
+	FakeClock 이 잠들지 않고 전체 일정을 실행할 수 있다.
+	시도를 다 써버리거나, 재시도할 수 없는 에러로 실패한 job 은 dead-letter 큐로 가는데,
+	거기서 자신의 이름, 받은 모든 에러 그리고 job 자체를 유지하므로, 원인이 고쳐지면 살펴보고 다시 실행할 수 있다.
+*/
+
+/*
+	RetryPolicy says how often and how patiently to retry. The delay before attempt n+1 is BaseDelay * Multiplier^(n-1), at most MaxDelay,
+	and Jitter takes up to that fraction off it at random so that jobs which failed together do not all come back together.
+	Retryable classifies errors; nil means IsRetryable. Rand returns a number in [0, 1) for the jitter; nil means math/rand.
+	RetryPolicy 는 얼마나 자주 그리고 얼마나 참을성 있게 재시도할지 말한다. 시도 n+1 전의 지연은 BaseDelay * Multiplier^(n-1) 이고, 최대 MaxDelay 이며,
+	Jitter 는 그 비율까지를 무작위로 빼서 함께 실패한 job 들이 모두 함께 돌아오지 않게 한다.
+	Retryable 은 에러들을 분류한다; nil 은 IsRetryable 을 뜻한다. Rand 는 jitter 를 위해 [0, 1) 의 숫자를 반환한다; nil 은 math/rand 를 뜻한다.
+*/
+type RetryPolicy struct {
+	MaxAttempts int
+	BaseDelay   time.Duration
+	MaxDelay    time.Duration
+	Multiplier  float64
+	Jitter      float64
+	Retryable   func(error) bool
+	Rand        func() float64
+}
+
+/*
+	maxBackoff is where a policy without MaxDelay stops growing, instead of overflowing time.Duration after enough attempts.
+	maxBackoff 는 MaxDelay 가 없는 정책이 충분한 시도 뒤에 time.Duration 을 넘치게 하는 대신 커지기를 멈추는 곳이다.
+*/
+const maxBackoff = time.Duration(math.MaxInt64)
+
+/*
+	Backoff returns the delay after the given failed attempt, counting from 1.
+	The exponent stops growing once the delay reaches MaxDelay, or maxBackoff if there is none.
+	Backoff 는 1 부터 센 주어진 실패한 시도 뒤의 지연을 반환한다.
+	지연이 MaxDelay 에, 없다면 maxBackoff 에 닿으면 지수는 커지기를 멈춘다.
+*/
+func (p RetryPolicy) Backoff(attempt int) time.Duration {
+	mult := p.Multiplier
+	if mult < 1 {
+		mult = 2
+	}
+	limit := float64(maxBackoff)
+	if p.MaxDelay > 0 {
+		limit = float64(p.MaxDelay)
+	}
+	d := float64(p.BaseDelay)
+	for i := 1; i < attempt && d > 0 && d < limit; i++ {
+		d *= mult
+	}
+	d = min(d, limit)
+	if p.Jitter > 0 {
+		random := p.Rand
+		if random == nil {
+			random = rand.Float64
+		}
+		d -= d * min(p.Jitter, 1) * random()
+	}
+	if d >= float64(maxBackoff) {
+		return maxBackoff
+	}
+	return time.Duration(d)
+}
+
+func (p RetryPolicy) retryable(err error) bool {
+	if p.Retryable != nil {
+		return p.Retryable(err)
+	}
+	return IsRetryable(err)
+}
+
+type permanentError struct {
+	err error
+}
+
+func (e *permanentError) Error() string { return e.err.Error() }
+func (e *permanentError) Unwrap() error { return e.err }
+
+/*
+	Permanent marks err as not worth retrying, such as bad input. A job returns Permanent(err) to go straight to the dead-letter queue.
+	Permanent 는 잘못된 입력처럼 err 을 재시도할 가치가 없다고 표시한다. job 은 곧장 dead-letter 큐로 가기 위해 Permanent(err) 를 반환한다.
+*/
+func Permanent(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &permanentError{err}
+}
+
+/*
+	IsRetryable is the default classifier. Errors marked Permanent and panics are not retryable, since running the same job again
+	will most likely do the same thing, and neither is a canceled context, since nobody is waiting for the result any more.
+	Everything else is, including a job's own deadline running out.
+	IsRetryable 은 기본 분류기이다. Permanent 로 표시된 에러와 panic 은 재시도할 수 없는데, 같은 job 을 다시 실행하면
+	아마도 같은 일을 할 것이기 때문이고, 취소된 context 도 마찬가지인데, 더 이상 아무도 결과를 기다리지 않기 때문이다.
+	job 자신의 기한이 다 된 것을 포함하여 나머지는 모두 재시도할 수 있다.
+*/
+func IsRetryable(err error) bool {
+	var permanent *permanentError
+	var panicErr *PanicError
+	switch {
+	case errors.As(err, &permanent), errors.As(err, &panicErr):
+		return false
+	case errors.Is(err, context.Canceled), errors.Is(err, ErrPoolClosed), errors.Is(err, ErrJobCanceled):
+		return false
+	}
+	return true
+}
+
+/*
+	RetryError is what a job that ended up in the dead-letter queue fails with: the last error, after Attempts attempts.
+	RetryError 는 dead-letter 큐에 들어간 job 이 실패하며 내는 것이다: Attempts 번의 시도 뒤의 마지막 에러.
+*/
+type RetryError struct {
+	Name     string
+	Attempts int
+	Err      error
+}
+
+func (e *RetryError) Error() string {
+	return fmt.Sprintf("%s: gave up after %d attempts: %v", e.Name, e.Attempts, e.Err)
+}
+
+func (e *RetryError) Unwrap() error {
+	return e.Err
+}
+
+/*
+	DeadLetter is a job that failed for good, with every error it got in order and when it was given up on.
+	DeadLetter 는 영영 실패한 job 으로, 받은 모든 에러를 순서대로, 그리고 언제 포기되었는지를 가진다.
+*/
+type DeadLetter[T any] struct {
+	ID       int
+	Name     string
+	Errors   []error
+	FailedAt time.Time
+	fn       func(context.Context) (T, error)
+	policy   RetryPolicy
+}
+
+var ErrNoDeadLetter = errors.New("retry: no such dead letter")
+
+/*
+	DeadLetterQueue holds the dead letters in the order they arrived. It is safe to inspect from any goroutine while jobs keep failing.
+	DeadLetterQueue 는 dead letter 들을 도착한 순서대로 가진다. job 들이 계속 실패하는 동안 어떤 goroutine 에서든 살펴보는 것이 안전하다.
+*/
+type DeadLetterQueue[T any] struct {
+	mu      sync.Mutex
+	letters []DeadLetter[T]
+	nextID  int
+}
+
+func (q *DeadLetterQueue[T]) push(letter DeadLetter[T]) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.nextID++
+	letter.ID = q.nextID
+	q.letters = append(q.letters, letter)
+}
+
+func (q *DeadLetterQueue[T]) take(id int) (DeadLetter[T], bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	for i, letter := range q.letters {
+		if letter.ID == id {
+			q.letters = append(q.letters[:i], q.letters[i+1:]...)
+			return letter, true
+		}
+	}
+	return DeadLetter[T]{}, false
+}
+
+func (q *DeadLetterQueue[T]) Letters() []DeadLetter[T] {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return append([]DeadLetter[T](nil), q.letters...)
+}
+
+func (q *DeadLetterQueue[T]) Len() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return len(q.letters)
+}
+
+type Retrier[T any] struct {
+	pool   *Pool[T]
+	clock  clock.Clock
+	policy RetryPolicy
+	dead   DeadLetterQueue[T]
+}
+
+func NewRetrier[T any](pool *Pool[T], policy RetryPolicy) *Retrier[T] {
+	return NewRetrierWithClock(clock.Real{}, pool, policy)
+}
+
+func NewRetrierWithClock[T any](clk clock.Clock, pool *Pool[T], policy RetryPolicy) *Retrier[T] {
+	policy.MaxAttempts = max(policy.MaxAttempts, 1)
+	return &Retrier[T]{pool: pool, clock: clk, policy: policy}
+}
+
+func (r *Retrier[T]) DeadLetters() *DeadLetterQueue[T] {
+	return &r.dead
+}
+
+/*
+	JobOption changes the policy for one job, starting from the Retrier's: WithPolicy replaces all of it, WithRetryable only the classifier.
+	JobOption 은 Retrier 의 것에서 시작해서 job 하나의 정책을 바꾼다: WithPolicy 는 그것 전부를, WithRetryable 은 분류기만 바꾼다.
+*/
+type JobOption func(*RetryPolicy)
+
+func WithPolicy(policy RetryPolicy) JobOption {
+	return func(p *RetryPolicy) { *p = policy }
+}
+
+func WithRetryable(retryable func(error) bool) JobOption {
+	return func(p *RetryPolicy) { p.Retryable = retryable }
+}
+
+/*
+	Submit runs fn on the pool under the retry policy, changed by opts, and returns a Future for its final outcome.
+	If ctx ends while the job waits for a retry, the Future fails with ctx's error and the job is dropped rather than sent to the dead-letter queue.
+	Submit 은 opts 로 바뀐 재시도 정책 아래에서 pool 위에서 fn 을 실행하고 그것의 최종 결과에 대한 Future 를 반환한다.
+	job 이 재시도를 기다리는 동안 ctx 가 끝나면, Future 는 ctx 의 에러로 실패하고 job 은 dead-letter 큐로 보내지는 대신 버려진다.
+*/
+func (r *Retrier[T]) Submit(ctx context.Context, name string, fn func(context.Context) (T, error), opts ...JobOption) *Future[T] {
+	policy := r.policy
+	for _, opt := range opts {
+		opt(&policy)
+	}
+	policy.MaxAttempts = max(policy.MaxAttempts, 1)
+	f := newFuture[T]()
+	go r.attempt(ctx, name, fn, policy, f)
+	return f
+}
+
+func (r *Retrier[T]) attempt(ctx context.Context, name string, fn func(context.Context) (T, error), policy RetryPolicy, f *Future[T]) {
+	var errs []error
+	for attempt := 1; ; attempt++ {
+		val, err := r.pool.Submit(ctx, fn).Result()
+		if err == nil {
+			f.complete(val, nil)
+			return
+		}
+		errs = append(errs, err)
+		if ctx.Err() != nil {
+			f.fail(ctx.Err())
+			return
+		}
+		if attempt == policy.MaxAttempts || !policy.retryable(err) {
+			r.dead.push(DeadLetter[T]{Name: name, Errors: errs, FailedAt: r.clock.Now(), fn: fn, policy: policy})
+			f.fail(&RetryError{Name: name, Attempts: attempt, Err: err})
+			return
+		}
+
+		timer := r.clock.NewTimer(policy.Backoff(attempt))
+		select {
+		case <-timer.C():
+			timer.Stop()
+		case <-ctx.Done():
+			timer.Stop()
+			f.fail(ctx.Err())
+			return
+		}
+	}
+}
+
+/*
+	Replay takes a dead letter off the queue and submits its job again with a fresh set of attempts, under the policy it was submitted with.
+	ReplayAll does that for every letter in the queue, in order.
+	Replay 는 dead letter 하나를 큐에서 꺼내고 그것의 job 을 제출될 때의 정책 아래에서 새로운 시도 횟수로 다시 제출한다.
+	ReplayAll 은 큐에 있는 모든 letter 에 대해 순서대로 그렇게 한다.
+*/
+func (r *Retrier[T]) Replay(ctx context.Context, id int) (*Future[T], error) {
+	letter, ok := r.dead.take(id)
+	if !ok {
+		return nil, fmt.Errorf("%w: %d", ErrNoDeadLetter, id)
+	}
+	return r.Submit(ctx, letter.Name, letter.fn, WithPolicy(letter.policy)), nil
+}
+
+func (r *Retrier[T]) ReplayAll(ctx context.Context) []*Future[T] {
+	var futures []*Future[T]
+	for _, letter := range r.dead.Letters() {
+		if f, err := r.Replay(ctx, letter.ID); err == nil {
+			futures = append(futures, f)
+		}
+	}
+	return futures
+}
+
+/*
+	retryExample runs three jobs: "flaky" fails twice and then works, "broken" rejects its input,
+	and "down" calls a service that stays down for every one of its attempts. Then the service comes back and the dead letters are replayed.
+	TestRetrier in retry_test.go runs the same jobs on a Fake clock and checks the backoff schedule exactly.
+	retryExample 은 세 개의 job 을 실행한다: "flaky" 는 두 번 실패한 뒤 동작하고, "broken" 은 자신의 입력을 거부하며,
+	"down" 은 모든 시도 동안 다운되어 있는 서비스를 호출한다. 그런 다음 서비스가 돌아오고 dead letter 들이 다시 실행된다.
+	retry_test.go 의 TestRetrier 는 같은 job 들을 Fake clock 위에서 실행하고 backoff 일정을 정확히 확인한다.
+*/
+func retryExample(out io.Writer) {
+	pool := NewPool[string](2, 4)
+	defer pool.ShutdownNow()
+	r := NewRetrier(pool, RetryPolicy{
+		MaxAttempts: 4,
+		BaseDelay:   5 * time.Millisecond,
+		MaxDelay:    50 * time.Millisecond,
+		Jitter:      0.2,
+	})
+	ctx := context.Background()
+
+	errBusy := errors.New("service busy")
+	var mu sync.Mutex
+	serviceUp := false
+	flakyCalls := 0
+	jobs := map[string]func(context.Context) (string, error){
+		"flaky": func(context.Context) (string, error) {
+			mu.Lock()
+			defer mu.Unlock()
+			flakyCalls++
+			if flakyCalls < 3 {
+				return "", errBusy
+			}
+			return "flaky ok", nil
+		},
+		"broken": func(context.Context) (string, error) {
+			return "", Permanent(errors.New(`invalid input "x"`))
+		},
+		"down": func(context.Context) (string, error) {
+			mu.Lock()
+			defer mu.Unlock()
+			if !serviceUp {
+				return "", errBusy
+			}
+			return "down ok", nil
+		},
+	}
+	for _, name := range []string{"flaky", "broken", "down"} {
+		val, err := r.Submit(ctx, name, jobs[name]).Result()
+		fmt.Fprintf(out, "%s: %q %v\n", name, val, err)
+	}
+	for _, l := range r.DeadLetters().Letters() {
+		fmt.Fprintf(out, "dead letter %d %s: %d errors, last: %v\n", l.ID, l.Name, len(l.Errors), l.Errors[len(l.Errors)-1])
+	}
+
+	mu.Lock()
+	serviceUp = true
+	mu.Unlock()
+	for _, f := range r.ReplayAll(ctx) {
+		val, err := f.Result()
+		fmt.Fprintf(out, "replayed: %q %v\n", val, err)
+	}
+	fmt.Fprintln(out, "dead letters left:", r.DeadLetters().Len())
+}
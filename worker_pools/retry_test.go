@@ -0,0 +1,212 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"gobyexample/internal/clock"
+)
+
+/*
+	TestBackoff checks the delays a policy gives, including a policy with no MaxDelay many attempts in, which must stop growing instead of overflowing.
+	TestBackoff 는 정책이 주는 지연들을 확인하는데, MaxDelay 가 없는 정책의 많은 시도 뒤도 포함하며, 그것은 넘치는 대신 커지기를 멈춰야 한다.
+*/
+func TestBackoff(t *testing.T) {
+	half := func() float64 { return 0.5 }
+	for _, tc := range []struct {
+		name    string
+		policy  RetryPolicy
+		attempt int
+		want    time.Duration
+	}{
+		{"first", RetryPolicy{BaseDelay: 100 * time.Millisecond}, 1, 100 * time.Millisecond},
+		{"doubles", RetryPolicy{BaseDelay: 100 * time.Millisecond}, 4, 800 * time.Millisecond},
+		{"multiplier", RetryPolicy{BaseDelay: 100 * time.Millisecond, Multiplier: 3}, 3, 900 * time.Millisecond},
+		{"capped", RetryPolicy{BaseDelay: 100 * time.Millisecond, MaxDelay: time.Second}, 10, time.Second},
+		{"jitter", RetryPolicy{BaseDelay: 100 * time.Millisecond, MaxDelay: time.Second, Jitter: 0.2, Rand: half}, 2, 180 * time.Millisecond},
+		{"jitter after cap", RetryPolicy{BaseDelay: 100 * time.Millisecond, MaxDelay: time.Second, Jitter: 0.2, Rand: half}, 5, 900 * time.Millisecond},
+		{"no max delay", RetryPolicy{BaseDelay: 100 * time.Millisecond}, 100, maxBackoff},
+		{"no max delay, many attempts", RetryPolicy{BaseDelay: time.Second, Multiplier: 10}, 1 << 30, maxBackoff},
+		{"no max delay with jitter", RetryPolicy{BaseDelay: time.Second, Jitter: 0.5, Rand: func() float64 { return 0 }}, 200, maxBackoff},
+		{"no base delay", RetryPolicy{}, 1 << 30, 0},
+	} {
+		if got := tc.policy.Backoff(tc.attempt); got != tc.want {
+			t.Errorf("%s: Backoff(%d) = %v, want %v", tc.name, tc.attempt, got, tc.want)
+		}
+	}
+}
+
+/*
+	TestRetrier runs three jobs on a Fake clock: "flaky" fails twice and then works, "broken" rejects its input,
+	and "down" calls a service that is down for every one of its attempts. Each job records the fake time of every attempt,
+	so the test sees the backoff schedule exactly. Then the service comes back and the dead letters are replayed.
+	Every retry waits on one fake timer, so before each step the test waits for the jobs still retrying to be parked on the clock,
+	then advances exactly to their deadline, so the retries see the clock where their timer fired.
+	flaky's third attempt works and parks nothing, so the test waits for it to finish before moving the clock past it.
+	TestRetrier 는 Fake clock 위에서 세 개의 job 을 실행한다: "flaky" 는 두 번 실패한 뒤 동작하고, "broken" 은 자신의 입력을 거부하며,
+	"down" 은 모든 시도 동안 다운되어 있는 서비스를 호출한다. 각 job 은 모든 시도의 가짜 시간을 기록하므로,
+	테스트는 backoff 일정을 정확히 볼 수 있다. 그런 다음 서비스가 돌아오고 dead letter 들이 다시 실행된다.
+	모든 재시도는 가짜 timer 하나를 기다리므로, 각 단계 전에 테스트는 아직 재시도 중인 job 들이 시계 위에 세워지기를 기다리고,
+	그런 다음 정확히 그들의 기한까지 advance 하므로, 재시도들은 자신의 timer 가 발사된 곳에 있는 시계를 본다.
+	flaky 의 세 번째 시도는 성공하고 아무것도 세우지 않으므로, 테스트는 시계를 그 너머로 움직이기 전에 그것이 끝나기를 기다린다.
+*/
+func TestRetrier(t *testing.T) {
+	start := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	fake := clock.NewFake(start)
+	pool := NewPoolWithClock[string](fake, 2, 4)
+	defer pool.ShutdownNow()
+	policy := RetryPolicy{
+		MaxAttempts: 4,
+		BaseDelay:   100 * time.Millisecond,
+		MaxDelay:    time.Second,
+		Jitter:      0.2,
+		Rand:        func() float64 { return 0.5 },
+	}
+	r := NewRetrierWithClock(fake, pool, policy)
+	ctx := context.Background()
+
+	errBusy := errors.New("service busy")
+	var mu sync.Mutex
+	attempts := make(map[string][]time.Duration)
+	record := func(name string) int {
+		mu.Lock()
+		defer mu.Unlock()
+		attempts[name] = append(attempts[name], fake.Since(start))
+		return len(attempts[name])
+	}
+	var serviceUp atomic.Bool
+
+	flaky := r.Submit(ctx, "flaky", func(context.Context) (string, error) {
+		if record("flaky") < 3 {
+			return "", errBusy
+		}
+		return "flaky ok", nil
+	})
+	broken := r.Submit(ctx, "broken", func(context.Context) (string, error) {
+		record("broken")
+		return "", Permanent(errors.New(`invalid input "x"`))
+	})
+	down := r.Submit(ctx, "down", func(context.Context) (string, error) {
+		record("down")
+		if !serviceUp.Load() {
+			return "", errBusy
+		}
+		return "down ok", nil
+	})
+
+	broken.Wait()
+	fake.BlockUntil(2)
+	fake.Advance(policy.Backoff(1))
+	fake.BlockUntil(2)
+	fake.Advance(policy.Backoff(2))
+	flaky.Wait()
+	fake.BlockUntil(1)
+	fake.Advance(policy.Backoff(3))
+	down.Wait()
+
+	for _, tc := range []struct {
+		name string
+		want []time.Duration
+	}{
+		{"flaky", []time.Duration{0, 90 * time.Millisecond, 270 * time.Millisecond}},
+		{"down", []time.Duration{0, 90 * time.Millisecond, 270 * time.Millisecond, 630 * time.Millisecond}},
+		{"broken", []time.Duration{0}},
+	} {
+		if !slices.Equal(attempts[tc.name], tc.want) {
+			t.Errorf("%s attempts at %v, want %v", tc.name, attempts[tc.name], tc.want)
+		}
+	}
+	if val, err := flaky.Result(); val != "flaky ok" || err != nil {
+		t.Errorf("flaky: %q, %v", val, err)
+	}
+	var retryErr *RetryError
+	if err := down.Err(); !errors.As(err, &retryErr) || retryErr.Attempts != 4 || !errors.Is(err, errBusy) {
+		t.Errorf("down: %v, want to give up after 4 attempts", err)
+	}
+	if err := broken.Err(); !errors.As(err, &retryErr) || retryErr.Attempts != 1 {
+		t.Errorf("broken: %v, want to give up after 1 attempt", err)
+	}
+
+	letters := r.DeadLetters().Letters()
+	var names []string
+	for _, l := range letters {
+		names = append(names, l.Name)
+	}
+	if !slices.Equal(names, []string{"broken", "down"}) {
+		t.Fatalf("dead letters %v, want [broken down]", names)
+	}
+	if n := len(letters[1].Errors); n != 4 {
+		t.Errorf("down has %d errors, want 4", n)
+	}
+
+	serviceUp.Store(true)
+	replayed, err := r.Replay(ctx, letters[1].ID)
+	if err != nil {
+		t.Fatalf("replay: %v", err)
+	}
+	if val, err := replayed.Result(); val != "down ok" || err != nil {
+		t.Errorf("replayed down: %q, %v", val, err)
+	}
+	if _, err := r.Replay(ctx, 99); !errors.Is(err, ErrNoDeadLetter) {
+		t.Errorf("replay missing: %v, want ErrNoDeadLetter", err)
+	}
+	if n := r.DeadLetters().Len(); n != 1 {
+		t.Errorf("%d dead letters after replay, want 1", n)
+	}
+}
+
+/*
+	TestRetrierJobOptions submits the same always-failing job under different per-job options and counts its attempts,
+	first as submitted and again after a replay, which keeps the policy the job was submitted with.
+	TestRetrierJobOptions 는 항상 실패하는 같은 job 을 서로 다른 job 별 옵션으로 제출하고 그것의 시도들을 세는데,
+	제출된 대로 한 번, 그리고 job 이 제출될 때의 정책을 유지하는 replay 뒤에 다시 센다.
+*/
+func TestRetrierJobOptions(t *testing.T) {
+	errBusy := errors.New("service busy")
+	errGone := errors.New("service gone")
+	for _, tc := range []struct {
+		name string
+		err  error
+		opts []JobOption
+		want int
+	}{
+		{"retrier policy", errBusy, nil, 3},
+		{"own policy", errBusy, []JobOption{WithPolicy(RetryPolicy{MaxAttempts: 5})}, 5},
+		{"own policy, no attempts", errBusy, []JobOption{WithPolicy(RetryPolicy{})}, 1},
+		{"own classifier", errGone, []JobOption{WithRetryable(func(err error) bool { return !errors.Is(err, errGone) })}, 1},
+		{"classifier after policy", errBusy, []JobOption{WithPolicy(RetryPolicy{MaxAttempts: 5}), WithRetryable(func(error) bool { return false })}, 1},
+		{"policy after classifier", errBusy, []JobOption{WithRetryable(func(error) bool { return false }), WithPolicy(RetryPolicy{MaxAttempts: 2})}, 2},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			pool := NewPool[int](1, 1)
+			defer pool.ShutdownNow()
+			r := NewRetrier(pool, RetryPolicy{MaxAttempts: 3})
+			ctx := context.Background()
+			var calls atomic.Int64
+			job := func(context.Context) (int, error) {
+				calls.Add(1)
+				return 0, tc.err
+			}
+
+			var retryErr *RetryError
+			if err := r.Submit(ctx, tc.name, job, tc.opts...).Err(); !errors.As(err, &retryErr) || retryErr.Attempts != tc.want {
+				t.Errorf("submit: %v, want to give up after %d attempts", err, tc.want)
+			}
+			replayed := r.ReplayAll(ctx)
+			if len(replayed) != 1 {
+				t.Fatalf("replayed %d jobs, want 1", len(replayed))
+			}
+			if err := replayed[0].Err(); !errors.As(err, &retryErr) || retryErr.Attempts != tc.want {
+				t.Errorf("replay: %v, want to give up after %d attempts", err, tc.want)
+			}
+			if n := calls.Load(); n != int64(2*tc.want) {
+				t.Errorf("%d calls, want %d", n, 2*tc.want)
+			}
+		})
+	}
+}
@@ -2,6 +2,7 @@ package main
 
 import (
 	"fmt"
+	"os"
 	"time"
 )
 
@@ -67,4 +68,22 @@ func main() {
 	for a := 1; a <= numJobs; a++ {
 		<-results
 	}
+
+	/*
+		Pool in pool.go is the same idea made reusable: every job gets back a Future with its value or its error.
+		pool.go 의 Pool 은 같은 아이디어를 재사용할 수 있게 만든 것이다: 모든 job 은 자신의 값이나 에러를 가진 Future 를 돌려받는다.
+	*/
+	poolExample(os.Stdout)
+
+	/*
+		ScalingPool in autoscale.go moves the number of workers with the load instead of always starting three.
+		autoscale.go 의 ScalingPool 은 항상 세 개를 시작하는 대신 부하에 따라 worker 의 수를 움직인다.
+	*/
+	scalingExample(os.Stdout)
+
+	/*
+		Retrier in retry.go gives failed jobs more attempts and keeps the ones that still fail in a dead-letter queue.
+		retry.go 의 Retrier 는 실패한 job 들에게 더 많은 시도를 주고 여전히 실패하는 것들을 dead-letter 큐에 보관한다.
+	*/
+	retryExample(os.Stdout)
 }